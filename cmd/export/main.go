@@ -0,0 +1,65 @@
+// Command export writes the workspace of a user to a ZIP archive, the same
+// archive GET /api/v1/export streams to the user.
+//
+//	go run ./cmd/export -email user@mail.ru -out backup.zip
+package main
+
+import (
+	"cotion/internal/application/export"
+	"cotion/internal/infrastructure/psql"
+	"cotion/internal/infrastructure/s3"
+	"cotion/internal/pkg/security"
+	"flag"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"os"
+)
+
+func init() {
+	godotenv.Load(".env_test")
+	log.SetOutput(os.Stderr)
+	log.SetLevel(log.InfoLevel)
+	log.SetFormatter(&log.TextFormatter{})
+}
+
+func main() {
+	email := flag.String("email", "", "email of the user whose workspace is exported")
+	out := flag.String("out", "cotion-export.zip", "path of the archive")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := psql.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	imageStorage, err := s3.NewMinioProvider(s3.AvatarsBucket)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	securityManager := security.NewSimpleSecurityManager()
+	exportService := export.NewExportApp(psql.NewNotesStorage(db), psql.NewUsersNotesStorage(db), psql.NewBlocksStorage(db),
+		psql.NewShareLinksStorage(db), psql.NewUserStorage(db), imageStorage)
+
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := exportService.ExportWorkspace(securityManager.Hash(*email), file); err != nil {
+		file.Close()
+		os.Remove(*out)
+		log.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Info("The workspace is exported to ", *out)
+}
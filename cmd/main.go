@@ -2,13 +2,24 @@ package main
 
 import (
 	"cotion/internal/application/auth"
+	"cotion/internal/application/collab"
+	"cotion/internal/application/comments"
+	"cotion/internal/application/export"
+	"cotion/internal/application/members"
 	"cotion/internal/application/notes"
+	"cotion/internal/application/notifications"
+	"cotion/internal/application/presence"
+	"cotion/internal/application/share"
+	"cotion/internal/application/tags"
+	"cotion/internal/application/templates"
 	"cotion/internal/application/user"
+	"cotion/internal/domain/entity"
 	"cotion/internal/handler"
 	"cotion/internal/handler/middleware"
 	"cotion/internal/infrastructure/psql"
 	"cotion/internal/infrastructure/s3"
 	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/events"
 	"cotion/internal/pkg/security"
 	"cotion/internal/pkg/xss"
 	"github.com/gorilla/mux"
@@ -17,6 +28,18 @@ import (
 	log "github.com/sirupsen/logrus"
 	"net/http"
 	"os"
+	"time"
+)
+
+const (
+	ENV_TRASH_RETENTION = "trash_retention"
+
+	trashPurgeInterval     = time.Hour
+	presenceExpiryInterval = 10 * time.Second
+	// Public links allow a few requests per minute to each client, so
+	// that link passwords cannot be guessed.
+	publicRequestsLimit  = 30
+	publicRequestsWindow = time.Minute
 )
 
 func init() {
@@ -34,7 +57,15 @@ func main() {
 	defer db.Close()
 	log.Info("Successful connect to database.")
 
-	imageStorage, err := s3.NewMinioProvider()
+	imageStorage, err := s3.NewMinioProvider(s3.AvatarsBucket)
+	if err != nil {
+		log.Fatal(err)
+	}
+	noteImagesStorage, err := s3.NewMinioProvider(s3.NotesBucket)
+	if err != nil {
+		log.Fatal(err)
+	}
+	attachmentFilesStorage, err := s3.NewMinioProvider(s3.AttachmentsBucket)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -46,17 +77,71 @@ func main() {
 	userStorage := psql.NewUserStorage(db)
 	notesStorage := psql.NewNotesStorage(db)
 	usersNotesStorage := psql.NewUsersNotesStorage(db)
+	blocksStorage := psql.NewBlocksStorage(db)
+	revisionsStorage := psql.NewRevisionsStorage(db)
+	shareLinksStorage := psql.NewShareLinksStorage(db)
+	tagsStorage := psql.NewTagsStorage(db)
+	templatesStorage := psql.NewTemplatesStorage(db)
+	attachmentsStorage := psql.NewAttachmentsStorage(db)
+	uploadsStorage := psql.NewUploadsStorage(db)
+	commentsStorage := psql.NewCommentsStorage(db)
+	noteLinksStorage := psql.NewNoteLinksStorage(db)
+	mentionsStorage := psql.NewMentionsStorage(db)
+	notificationsStorage := psql.NewNotificationsStorage(db)
 	sessionStorage := storage.NewSessionStorage()
 
-	notesService := notes.NewNotesApp(notesStorage, usersNotesStorage)
+	eventBus := events.NewBus()
+
+	notificationsService := notifications.NewNotificationsApp(notificationsStorage, mentionsStorage, userStorage, usersNotesStorage)
+	notesService := notes.NewNotesApp(notes.Dependencies{
+		Notes:         notesStorage,
+		UsersNotes:    usersNotesStorage,
+		Blocks:        blocksStorage,
+		Revisions:     revisionsStorage,
+		Tags:          tagsStorage,
+		NoteLinks:     noteLinksStorage,
+		Images:        noteImagesStorage,
+		Attachments:   attachmentsStorage,
+		Files:         attachmentFilesStorage,
+		Uploads:       uploadsStorage,
+		Notifications: notificationsService,
+		EventBus:      eventBus,
+	})
 	userService := user.NewUserService(userStorage, imageStorage, securityManager)
 	authService := auth.NewAuthApp(sessionStorage, userService, securityManager)
+	membersService := members.NewMembersApp(usersNotesStorage, userStorage, securityManager, eventBus)
+	tagsService := tags.NewTagsApp(tagsStorage, usersNotesStorage)
+	templatesService := templates.NewTemplatesApp(templatesStorage, tagsStorage, notesService)
+	commentsService := comments.NewCommentsApp(commentsStorage, usersNotesStorage, notificationsService)
+	presenceService := presence.NewPresenceApp(usersNotesStorage)
+	collabService := collab.NewCollabApp(notesStorage, usersNotesStorage, presenceService, notesService)
+	exportService := export.NewExportApp(notesStorage, usersNotesStorage, blocksStorage, shareLinksStorage, userStorage, imageStorage)
+	shareService := share.NewShareApp(shareLinksStorage, usersNotesStorage, notesStorage, blocksStorage)
+
+	trashRetention := entity.DefaultTrashRetention
+	if value := os.Getenv(ENV_TRASH_RETENTION); value != "" {
+		if trashRetention, err = time.ParseDuration(value); err != nil {
+			log.Fatal(err)
+		}
+	}
+	go notesService.RunTrashPurge(trashPurgeInterval, trashRetention, nil)
+	go presenceService.RunExpiry(presenceExpiryInterval, entity.PresenceTimeout, nil)
 
 	notesHandler := handler.NewNotesHandler(notesService, authService, securityManager)
 	userHandler := handler.NewUserHandler(userService)
 	loginHandler := handler.NewLoginHandler(authService)
+	membersHandler := handler.NewMembersHandler(membersService, securityManager)
+	shareHandler := handler.NewShareHandler(shareService, securityManager)
+	tagsHandler := handler.NewTagsHandler(tagsService, securityManager)
+	templatesHandler := handler.NewTemplatesHandler(templatesService, securityManager)
+	commentsHandler := handler.NewCommentsHandler(commentsService, securityManager)
+	collabHandler := handler.NewCollabHandler(collabService, authService, securityManager)
+	presenceHandler := handler.NewPresenceHandler(presenceService, securityManager)
+	notificationsHandler := handler.NewNotificationsHandler(notificationsService, securityManager)
+	exportHandler := handler.NewExportHandler(exportService, securityManager)
 
 	amw := middleware.NewAuthMiddleware(authService)
+	publicLimiter := middleware.NewRateLimiter(publicRequestsLimit, publicRequestsWindow)
 	xss.NewXssSanitizer()
 
 	routerAPI := router.PathPrefix("/api/v1").Subrouter()
@@ -64,8 +149,86 @@ func main() {
 	routerAPI.HandleFunc("/note/{note-token:[0-9]+}", amw.Auth(notesHandler.ReceiveSingleNote)).Methods("GET")
 	routerAPI.HandleFunc("/note/{note-token:[0-9]+}", amw.Auth(notesHandler.UpdateNote)).Methods("PUT") //update note data
 	routerAPI.HandleFunc("/notes", amw.Auth(notesHandler.MainPage)).Methods("GET")
+	routerAPI.HandleFunc("/notes/tree", amw.Auth(notesHandler.NotesTree)).Methods("GET")
+	routerAPI.HandleFunc("/notes/search", amw.Auth(notesHandler.SearchNotes)).Methods("GET")
+	routerAPI.HandleFunc("/notes/events", amw.Auth(notesHandler.NotesEvents)).Methods("GET")
+	routerAPI.HandleFunc("/notes/graph", amw.Auth(notesHandler.NotesGraph)).Methods("GET")
+	routerAPI.HandleFunc("/notes/import", amw.Auth(notesHandler.ImportNotes)).Methods("POST")
+	routerAPI.HandleFunc("/export", amw.Auth(exportHandler.ExportWorkspace)).Methods("GET")
+	routerAPI.HandleFunc("/note", amw.Auth(templatesHandler.CreateNote)).Methods("POST").Queries("template", "{template-id:[0-9]+}")
 	routerAPI.HandleFunc("/note", amw.Auth(notesHandler.CreateNote)).Methods("POST")
 	routerAPI.HandleFunc("/note/{note-token:[0-9]+}", amw.Auth(notesHandler.DeleteNote)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/collab", collabHandler.EditNote).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/presence", amw.Auth(presenceHandler.Presence)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/export", amw.Auth(notesHandler.ExportNote)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/backlinks", amw.Auth(notesHandler.Backlinks)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/move", amw.Auth(notesHandler.MoveNote)).Methods("PUT")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/duplicate", amw.Auth(notesHandler.DuplicateNote)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/icon", amw.Auth(notesHandler.UploadIcon)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/icon", amw.Auth(notesHandler.SetEmojiIcon)).Methods("PUT")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/icon", amw.Auth(notesHandler.DownloadIcon)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/icon", amw.Auth(notesHandler.DeleteIcon)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/cover", amw.Auth(notesHandler.UploadCover)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/cover", amw.Auth(notesHandler.DownloadCover)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/cover", amw.Auth(notesHandler.DeleteCover)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/attachments", amw.Auth(notesHandler.Attachments)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/attachments", amw.Auth(notesHandler.UploadAttachment)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/attachments/{attachment-id:[0-9]+}", amw.Auth(notesHandler.DownloadAttachment)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/attachments/{attachment-id:[0-9]+}", amw.Auth(notesHandler.DeleteAttachment)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/uploads", amw.Auth(notesHandler.CreateUpload)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/uploads/{upload-id:[0-9a-f]+}", amw.Auth(notesHandler.UploadStatus)).Methods("HEAD")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/uploads/{upload-id:[0-9a-f]+}", amw.Auth(notesHandler.UploadChunk)).Methods("PATCH")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/uploads/{upload-id:[0-9a-f]+}", amw.Auth(notesHandler.AbortUpload)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/pin", amw.Auth(notesHandler.PinNote)).Methods("PUT", "DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/favorite", amw.Auth(notesHandler.FavoriteNote)).Methods("PUT", "DELETE")
+	routerAPI.HandleFunc("/notes/order", amw.Auth(notesHandler.ReorderNotes)).Methods("PUT")
+
+	routerAPI.HandleFunc("/trash", amw.Auth(notesHandler.Trash)).Methods("GET")
+	routerAPI.HandleFunc("/trash/{note-token:[0-9]+}/restore", amw.Auth(notesHandler.RestoreNote)).Methods("POST")
+	routerAPI.HandleFunc("/trash/{note-token:[0-9]+}", amw.Auth(notesHandler.DeleteNoteForever)).Methods("DELETE")
+
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/blocks", amw.Auth(notesHandler.InsertBlock)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/blocks/{block-id:[a-zA-Z]+}", amw.Auth(notesHandler.UpdateBlock)).Methods("PUT")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/blocks/{block-id:[a-zA-Z]+}/move", amw.Auth(notesHandler.MoveBlock)).Methods("PUT")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/blocks/{block-id:[a-zA-Z]+}", amw.Auth(notesHandler.DeleteBlock)).Methods("DELETE")
+
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/revisions", amw.Auth(notesHandler.Revisions)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/revisions/diff", amw.Auth(notesHandler.DiffRevisions)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/revisions/{revision-id:[0-9]+}", amw.Auth(notesHandler.GetRevision)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/revisions/{revision-id:[0-9]+}/restore", amw.Auth(notesHandler.RestoreRevision)).Methods("POST")
+
+	routerAPI.HandleFunc("/tags", amw.Auth(tagsHandler.Tags)).Methods("GET")
+	routerAPI.HandleFunc("/tags", amw.Auth(tagsHandler.CreateTag)).Methods("POST")
+	routerAPI.HandleFunc("/tags/{tag-id:[0-9]+}", amw.Auth(tagsHandler.UpdateTag)).Methods("PUT")
+	routerAPI.HandleFunc("/tags/{tag-id:[0-9]+}", amw.Auth(tagsHandler.DeleteTag)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/tags/{tag-id:[0-9]+}", amw.Auth(tagsHandler.AttachTag)).Methods("PUT")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/tags/{tag-id:[0-9]+}", amw.Auth(tagsHandler.DetachTag)).Methods("DELETE")
+
+	routerAPI.HandleFunc("/templates", amw.Auth(templatesHandler.Templates)).Methods("GET")
+	routerAPI.HandleFunc("/templates", amw.Auth(templatesHandler.CreateTemplate)).Methods("POST")
+	routerAPI.HandleFunc("/templates/{template-id:[0-9]+}", amw.Auth(templatesHandler.UpdateTemplate)).Methods("PUT")
+	routerAPI.HandleFunc("/templates/{template-id:[0-9]+}", amw.Auth(templatesHandler.DeleteTemplate)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/template", amw.Auth(templatesHandler.SaveNoteAsTemplate)).Methods("POST")
+
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/comments", amw.Auth(commentsHandler.Comments)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/comments", amw.Auth(commentsHandler.CreateComment)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/comments/{comment-id:[0-9]+}", amw.Auth(commentsHandler.UpdateComment)).Methods("PUT")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/comments/{comment-id:[0-9]+}", amw.Auth(commentsHandler.DeleteComment)).Methods("DELETE")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/comments/{comment-id:[0-9]+}/resolve", amw.Auth(commentsHandler.ResolveComment)).Methods("PUT", "DELETE")
+
+	routerAPI.HandleFunc("/notifications", amw.Auth(notificationsHandler.Notifications)).Methods("GET")
+	routerAPI.HandleFunc("/notifications/read", amw.Auth(notificationsHandler.MarkAllRead)).Methods("PUT")
+	routerAPI.HandleFunc("/notifications/{notification-id:[0-9]+}/read", amw.Auth(notificationsHandler.MarkRead)).Methods("PUT")
+
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/members", amw.Auth(membersHandler.Members)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/members", amw.Auth(membersHandler.AddMember)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/members/{member-id:[0-9a-f]+}", amw.Auth(membersHandler.ChangeRole)).Methods("PUT")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/members/{member-id:[0-9a-f]+}", amw.Auth(membersHandler.RevokeMember)).Methods("DELETE")
+
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/share", amw.Auth(shareHandler.Links)).Methods("GET")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/share", amw.Auth(shareHandler.CreateLink)).Methods("POST")
+	routerAPI.HandleFunc("/note/{note-token:[0-9]+}/share/{share-token:[0-9a-f]+}", amw.Auth(shareHandler.RevokeLink)).Methods("DELETE")
+	routerAPI.HandleFunc("/public/{share-token:[0-9a-f]+}", publicLimiter.Limit(shareHandler.PublicNote)).Methods("GET")
 
 	routerAPI.HandleFunc("/users/signup", amw.NotAuth(userHandler.SignUp)).Methods("POST")
 	routerAPI.HandleFunc("/user", amw.Auth(userHandler.GetUser)).Methods("GET")
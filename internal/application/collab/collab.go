@@ -0,0 +1,541 @@
+package collab
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/application/presence"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/ot"
+	"cotion/internal/pkg/xss"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+const (
+	packageName = "app collab"
+	// saveDelay is how long edits are collected before the note is saved.
+	saveDelay = 2 * time.Second
+	// sessionBuffer is how many messages a client may fall behind before
+	// it is disconnected.
+	sessionBuffer = 64
+	// maxHistory is how many operations are kept for editors that have not
+	// reported their revision. An operation based on an older revision is
+	// refused and the client has to join again.
+	maxHistory = 1000
+	// maxSaveAttempts is how many times the document is rebased onto the
+	// saved note before the save is given up until the next edit.
+	maxSaveAttempts = 3
+)
+
+var ErrUnknownRevision = errors.New("The operation is based on an unknown revision.")
+var ErrUnknownMessage = errors.New("Unknown message type.")
+var ErrNoCursor = errors.New("The cursor message has no cursor.")
+var ErrNotSaved = errors.New("The changes could not be saved.")
+
+// CollabApp lets several clients edit the body of a note at the same time.
+// Concurrent operations are merged with operational transformation: every
+// operation is transformed against the ones the client has not seen yet
+// before it is applied.
+type CollabApp struct {
+	notesRepository      repository.NotesRepository
+	usersNotesRepository repository.UsersNotesRepository
+	presenceApp          *presence.PresenceApp
+	notesApp             *notes.NotesApp
+
+	mu        sync.Mutex
+	documents map[string]*document
+}
+
+// document is the state of a note that is being edited. savedName,
+// savedBody and version are the note as it was last loaded or saved, the
+// edits made since then are saved on top of that version.
+type document struct {
+	mu        sync.Mutex
+	token     string
+	name      string
+	savedName string
+	body      string
+	savedBody string
+	version   int
+	// history holds the operations after revision base, the older ones
+	// have been seen by every editor.
+	history  []ot.Operation
+	base     int
+	sessions map[*Session]struct{}
+	// closed is set once the last session has left and the document is
+	// no longer in CollabApp.documents.
+	closed bool
+
+	dirty      bool
+	lastEditor string
+	saveTimer  *time.Timer
+
+	stopPresence func()
+}
+
+// Session is a client connected to a note. ID is the id of its presence
+// session, revision is the latest revision the client is known to have.
+type Session struct {
+	ID       string
+	UserID   string
+	doc      *document
+	canEdit  bool
+	revision int
+	messages chan entity.CollabMessage
+}
+
+func NewCollabApp(notesRepo repository.NotesRepository, usersNotesRepo repository.UsersNotesRepository,
+	presenceApp *presence.PresenceApp, notesApp *notes.NotesApp) *CollabApp {
+	return &CollabApp{
+		notesRepository:      notesRepo,
+		usersNotesRepository: usersNotesRepo,
+		presenceApp:          presenceApp,
+		notesApp:             notesApp,
+		documents:            map[string]*document{},
+	}
+}
+
+// Messages returns the messages for the client. The channel is closed when
+// the session is over.
+func (s *Session) Messages() <-chan entity.CollabMessage {
+	return s.messages
+}
+
+// Join connects the user to the note and makes them present on it. Viewers
+// only receive changes, editors may also send them. The first message of
+// the session is CollabInit.
+func (c *CollabApp) Join(userID string, username string, noteToken string) (*Session, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Join",
+		"noteToken": noteToken,
+	})
+
+	role, err := c.usersNotesRepository.Role(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return nil, notes.ErrNoteAccess
+	}
+
+	canEdit := entity.RoleAllows(role, entity.RoleEditor)
+	for {
+		doc, err := c.document(noteToken)
+		if err != nil {
+			logger.Warning(err)
+			return nil, err
+		}
+
+		sessionID, err := c.presenceApp.Join(noteToken, userID, username)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		session := &Session{
+			ID:       sessionID,
+			UserID:   userID,
+			doc:      doc,
+			canEdit:  canEdit,
+			messages: make(chan entity.CollabMessage, sessionBuffer),
+		}
+		if doc.add(session) {
+			return session, nil
+		}
+		// The last session has closed the document in the meantime.
+		c.presenceApp.Leave(noteToken, sessionID)
+	}
+}
+
+// document returns the open document of the note, loading it if nobody
+// edits the note yet.
+func (c *CollabApp) document(noteToken string) (*document, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if doc, ok := c.documents[noteToken]; ok {
+		return doc, nil
+	}
+
+	note, err := c.notesRepository.Find(noteToken)
+	if err != nil {
+		return nil, err
+	}
+	if note.DeletedAt != nil {
+		return nil, notes.ErrNoteInTrash
+	}
+	doc := &document{
+		token:     noteToken,
+		name:      note.Name,
+		savedName: note.Name,
+		body:      note.Body,
+		savedBody: note.Body,
+		version:   note.Version,
+		sessions:  map[*Session]struct{}{},
+	}
+	var events <-chan entity.PresenceEvent
+	events, doc.stopPresence = c.presenceApp.Subscribe(noteToken)
+	go doc.forwardPresence(events)
+	c.documents[noteToken] = doc
+	return doc, nil
+}
+
+// Receive handles a message sent by the client.
+func (c *CollabApp) Receive(session *Session, message entity.CollabMessage) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Receive",
+		"noteToken": session.doc.token,
+	})
+
+	doc := session.doc
+	switch message.Type {
+	case entity.CollabHeartbeat:
+		doc.mu.Lock()
+		if message.Revision > session.revision && message.Revision <= doc.revision() {
+			session.revision = message.Revision
+			doc.trim()
+		}
+		doc.mu.Unlock()
+		return c.presenceApp.Heartbeat(doc.token, session.ID)
+	case entity.CollabCursor:
+		if message.Cursor == nil {
+			logger.Warning(ErrNoCursor)
+			return ErrNoCursor
+		}
+		return c.presenceApp.MoveCursor(doc.token, session.ID, *message.Cursor)
+	}
+
+	if !session.canEdit {
+		logger.Warning(notes.ErrNoteRole)
+		return notes.ErrNoteRole
+	}
+	c.presenceApp.Heartbeat(doc.token, session.ID)
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	if _, ok := doc.sessions[session]; !ok {
+		return nil
+	}
+
+	switch message.Type {
+	case entity.CollabOperation:
+		revision, err := doc.apply(message.Revision, message.Operation)
+		if err != nil {
+			logger.Warning(err)
+			return err
+		}
+		doc.send(session, entity.CollabMessage{Type: entity.CollabAck, Revision: revision})
+		doc.broadcast(session, entity.CollabMessage{
+			Type:      entity.CollabOperation,
+			Revision:  revision,
+			Operation: doc.history[len(doc.history)-1],
+			UserID:    session.UserID,
+		})
+		// The acknowledged client has every operation up to its own one.
+		session.revision = revision
+		doc.trim()
+	case entity.CollabName:
+		if len(message.Name) > entity.MaxNameLength {
+			logger.Warning(entity.ErrNoteNameLengthExceedsLimit)
+			return entity.ErrNoteNameLengthExceedsLimit
+		}
+		doc.name = message.Name
+		doc.broadcast(session, entity.CollabMessage{
+			Type:     entity.CollabName,
+			Revision: doc.revision(),
+			Name:     message.Name,
+			UserID:   session.UserID,
+		})
+	default:
+		logger.Warning(ErrUnknownMessage)
+		return ErrUnknownMessage
+	}
+
+	doc.dirty = true
+	doc.lastEditor = session.UserID
+	if doc.saveTimer == nil {
+		doc.saveTimer = time.AfterFunc(saveDelay, func() {
+			doc.mu.Lock()
+			defer doc.mu.Unlock()
+			doc.saveTimer = nil
+			c.save(doc)
+		})
+	}
+
+	return nil
+}
+
+// Leave disconnects the client. The note is saved when the last client
+// leaves.
+func (c *CollabApp) Leave(session *Session) {
+	doc := session.doc
+	c.presenceApp.Leave(doc.token, session.ID)
+
+	doc.mu.Lock()
+	doc.remove(session)
+	if len(doc.sessions) != 0 {
+		doc.mu.Unlock()
+		return
+	}
+	if doc.saveTimer != nil {
+		doc.saveTimer.Stop()
+		doc.saveTimer = nil
+	}
+	// Only this note waits for the save, c.mu is not held.
+	c.save(doc)
+	doc.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	doc.mu.Lock()
+	// A client may have joined during the save.
+	if len(doc.sessions) != 0 || doc.closed {
+		doc.mu.Unlock()
+		return
+	}
+	doc.closed = true
+	doc.mu.Unlock()
+
+	if c.documents[doc.token] == doc {
+		delete(c.documents, doc.token)
+	}
+	doc.stopPresence()
+}
+
+// save writes the merged state of the document. It is saved on top of the
+// version it was loaded at, if the note has been saved by someone else
+// since then their changes are merged in first. A failed save is reported
+// to the sessions, the edits stay in the document until the next save. The
+// caller holds doc.mu.
+func (c *CollabApp) save(doc *document) {
+	if !doc.dirty {
+		return
+	}
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "save",
+		"noteToken": doc.token,
+	})
+
+	if err := c.saveDocument(doc); err != nil {
+		logger.Error(err)
+		doc.broadcast(nil, entity.CollabMessage{Type: entity.CollabError, Error: ErrNotSaved.Error()})
+	}
+}
+
+func (c *CollabApp) saveDocument(doc *document) error {
+	for attempt := 0; attempt < maxSaveAttempts; attempt++ {
+		editorID, err := c.editor(doc)
+		if err != nil {
+			return err
+		}
+
+		request := entity.NoteRequest{Name: doc.name, Body: doc.body}
+		version, err := c.notesApp.UpdateNote(editorID, doc.token, request, doc.version)
+		if errors.Is(err, entity.ErrNoteVersionConflict) {
+			if err := c.rebase(doc); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		doc.version = version
+		doc.savedName = doc.name
+		doc.savedBody = doc.body
+		doc.dirty = false
+		return nil
+	}
+	return entity.ErrNoteVersionConflict
+}
+
+// editor returns who the merged edits are saved as: the last editor if they
+// may still edit the note, any other editor of the session otherwise.
+func (c *CollabApp) editor(doc *document) (string, error) {
+	candidates := []string{doc.lastEditor}
+	for session := range doc.sessions {
+		if session.canEdit {
+			candidates = append(candidates, session.UserID)
+		}
+	}
+
+	for _, userID := range candidates {
+		role, err := c.usersNotesRepository.Role(userID, doc.token)
+		if err == nil && entity.RoleAllows(role, entity.RoleEditor) {
+			return userID, nil
+		}
+	}
+	return "", notes.ErrNoteRole
+}
+
+// rebase merges the saved note into the document. Its changes since the
+// last save are sent to the clients as an operation of its last editor, a
+// rename is taken only if the name has not been changed here as well.
+func (c *CollabApp) rebase(doc *document) error {
+	latest, err := c.notesRepository.Find(doc.token)
+	if err != nil {
+		return err
+	}
+	if latest.DeletedAt != nil {
+		return notes.ErrNoteInTrash
+	}
+	name, body := latest.Name, latest.Body
+
+	if body != doc.savedBody {
+		local := ot.Diff(doc.savedBody, doc.body)
+		_, saved, err := ot.Transform(local, ot.Diff(doc.savedBody, body))
+		if err != nil {
+			return err
+		}
+		if doc.body, err = saved.Apply(doc.body); err != nil {
+			return err
+		}
+		doc.history = append(doc.history, saved)
+		doc.broadcast(nil, entity.CollabMessage{
+			Type:      entity.CollabOperation,
+			Revision:  doc.revision(),
+			Operation: saved,
+			UserID:    latest.LastEditedBy,
+		})
+		doc.trim()
+	}
+	if name != doc.savedName && doc.name == doc.savedName {
+		doc.name = name
+		doc.broadcast(nil, entity.CollabMessage{
+			Type:     entity.CollabName,
+			Revision: doc.revision(),
+			Name:     name,
+			UserID:   latest.LastEditedBy,
+		})
+	}
+
+	doc.savedName = name
+	doc.savedBody = body
+	doc.version = latest.Version
+	return nil
+}
+
+// apply transforms the operation against the ones made after its revision,
+// applies it and returns the new revision.
+func (doc *document) apply(revision int, op ot.Operation) (int, error) {
+	if revision < doc.base || revision > doc.revision() {
+		return 0, ErrUnknownRevision
+	}
+
+	var err error
+	for _, concurrent := range doc.history[revision-doc.base:] {
+		if op, _, err = ot.Transform(op, concurrent); err != nil {
+			return 0, err
+		}
+	}
+
+	body, err := op.Apply(doc.body)
+	if err != nil {
+		return 0, err
+	}
+	if len(body) > entity.MaxBodyLength {
+		return 0, entity.ErrNoteBodyLengthExceedsLimit
+	}
+
+	doc.body = body
+	doc.history = append(doc.history, op)
+	return doc.revision(), nil
+}
+
+// revision is the number of operations applied to the document.
+func (doc *document) revision() int {
+	return doc.base + len(doc.history)
+}
+
+// trim drops the operations every editor has already seen. Viewers never
+// send operations, so their revision does not matter.
+func (doc *document) trim() {
+	oldest := doc.revision()
+	for session := range doc.sessions {
+		if session.canEdit && session.revision < oldest {
+			oldest = session.revision
+		}
+	}
+	if oldest < doc.revision()-maxHistory {
+		oldest = doc.revision() - maxHistory
+	}
+	if oldest <= doc.base {
+		return
+	}
+
+	doc.history = doc.history[oldest-doc.base:]
+	doc.base = oldest
+}
+
+// forwardPresence sends the presence events of the note to its sessions
+// until the subscription is stopped.
+func (doc *document) forwardPresence(events <-chan entity.PresenceEvent) {
+	for event := range events {
+		event := event
+		doc.mu.Lock()
+		doc.broadcast(nil, entity.CollabMessage{Type: entity.CollabPresence, Presence: &event})
+		doc.mu.Unlock()
+	}
+}
+
+// add connects the session and sends it the document. It fails once the
+// document is closed.
+func (doc *document) add(session *Session) bool {
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	if doc.closed {
+		return false
+	}
+	session.revision = doc.revision()
+	doc.sessions[session] = struct{}{}
+	doc.send(session, entity.CollabMessage{
+		Type:      entity.CollabInit,
+		Revision:  session.revision,
+		Name:      doc.name,
+		Body:      doc.body,
+		SessionID: session.ID,
+	})
+	return true
+}
+
+// broadcast sends the message to every session except the sender.
+func (doc *document) broadcast(sender *Session, message entity.CollabMessage) {
+	xss.SanitizeCollabMessage(&message)
+	for session := range doc.sessions {
+		if session != sender {
+			doc.deliver(session, message)
+		}
+	}
+}
+
+// send sanitizes the text of the message the way the REST handlers do, the
+// document itself keeps the stored text.
+func (doc *document) send(session *Session, message entity.CollabMessage) {
+	xss.SanitizeCollabMessage(&message)
+	doc.deliver(session, message)
+}
+
+// deliver drops a session that does not keep up instead of blocking the
+// others.
+func (doc *document) deliver(session *Session, message entity.CollabMessage) {
+	select {
+	case session.messages <- message:
+	default:
+		doc.remove(session)
+	}
+}
+
+func (doc *document) remove(session *Session) {
+	if _, ok := doc.sessions[session]; !ok {
+		return
+	}
+	delete(doc.sessions, session)
+	close(session.messages)
+}
@@ -0,0 +1,326 @@
+package collab
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/application/presence"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/events"
+	"cotion/internal/pkg/ot"
+	"cotion/internal/pkg/security"
+	"cotion/internal/pkg/xss"
+	"github.com/stretchr/testify/require"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestCollab(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	editorID := security.Hash("test2@mail.ru")
+	viewerID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	presenceService := presence.NewPresenceApp(usersNotesStorage)
+	eventBus := events.NewBus()
+	notesService := notes.NewNotesApp(notes.Dependencies{
+		Notes:       notesStorage,
+		UsersNotes:  usersNotesStorage,
+		Blocks:      storage.NewBlocksStorage(),
+		Revisions:   storage.NewRevisionsStorage(),
+		Tags:        storage.NewTagsStorage(),
+		NoteLinks:   storage.NewNoteLinksStorage(notesStorage),
+		Attachments: storage.NewAttachmentsStorage(),
+		Uploads:     storage.NewUploadsStorage(),
+		EventBus:    eventBus,
+	})
+	collabService := NewCollabApp(notesStorage, usersNotesStorage, presenceService, notesService)
+
+	_, err := notesStorage.Update("1", entity.Note{Name: "1st note", Body: "abc"})
+	require.Equal(t, nil, err)
+	usersNotesStorage.AddLink(editorID, "1", entity.RoleEditor)
+	usersNotesStorage.AddLink(viewerID, "1", entity.RoleViewer)
+
+	_, err = collabService.Join(editorID, "test2", "2")
+	require.Equal(t, notes.ErrNoteAccess, err)
+
+	owner, err := collabService.Join(ownerID, "test", "1")
+	require.Equal(t, nil, err)
+	editor, err := collabService.Join(editorID, "test2", "1")
+	require.Equal(t, nil, err)
+	viewer, err := collabService.Join(viewerID, "nikita", "1")
+	require.Equal(t, nil, err)
+
+	for _, session := range []*Session{owner, editor, viewer} {
+		init := next(session)
+		require.Equal(t, entity.CollabMessage{Type: entity.CollabInit, Name: "1st note", Body: "abc", SessionID: session.ID}, init)
+	}
+
+	presence, err := presenceService.Presence(ownerID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, len(presence.Users))
+	require.Equal(t, nil, collabService.Receive(viewer, entity.CollabMessage{
+		Type:   entity.CollabCursor,
+		Cursor: &entity.Cursor{Anchor: 1, Head: 2},
+	}))
+
+	// Both edit revision 0 at the same time.
+	require.Equal(t, nil, collabService.Receive(owner, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Operation: ot.Operation{}.Insert("1").Retain(3),
+	}))
+	require.Equal(t, nil, collabService.Receive(editor, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Operation: ot.Operation{}.Retain(3).Insert("2"),
+	}))
+
+	require.Equal(t, entity.CollabMessage{Type: entity.CollabAck, Revision: 1}, next(owner))
+	require.Equal(t, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Revision:  2,
+		Operation: ot.Operation{}.Retain(4).Insert("2"),
+		UserID:    editorID,
+	}, next(owner))
+	require.Equal(t, entity.CollabOperation, (next(editor)).Type)
+	require.Equal(t, entity.CollabMessage{Type: entity.CollabAck, Revision: 2}, next(editor))
+
+	require.Equal(t, notes.ErrNoteRole, collabService.Receive(viewer, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Operation: ot.Operation{}.Delete(5),
+	}))
+	require.Equal(t, ErrUnknownRevision, collabService.Receive(editor, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Revision:  3,
+		Operation: ot.Operation{}.Retain(5),
+	}))
+
+	collabService.Leave(owner)
+	collabService.Leave(editor)
+	collabService.Leave(viewer)
+
+	note, err := notesStorage.Find("1")
+	require.Equal(t, nil, err)
+	require.Equal(t, "1abc2", note.Body)
+	require.Equal(t, editorID, note.LastEditedBy)
+
+	var received []entity.CollabMessage
+	for message := range viewer.Messages() {
+		if message.Type != entity.CollabPresence {
+			received = append(received, message)
+		}
+	}
+	require.Equal(t, 2, len(received))
+
+	presence, err = presenceService.Presence(ownerID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, len(presence.Users))
+	log.Println("SUCCESS")
+}
+
+func TestCollabSave(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	editorID := security.Hash("test2@mail.ru")
+
+	notesStorage, usersNotesStorage, notesService, collabService := newTestCollab()
+	_, err := notesStorage.Update("1", entity.Note{Name: "1st note", Body: "abc"})
+	require.Equal(t, nil, err)
+	usersNotesStorage.AddLink(editorID, "1", entity.RoleEditor)
+	note, err := notesStorage.Find("1")
+	require.Equal(t, nil, err)
+
+	owner, err := collabService.Join(ownerID, "test", "1")
+	require.Equal(t, nil, err)
+	editor, err := collabService.Join(editorID, "test2", "1")
+	require.Equal(t, nil, err)
+	next(owner)
+	next(editor)
+
+	require.Equal(t, nil, collabService.Receive(editor, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Operation: ot.Operation{}.Insert("1").Retain(3),
+	}))
+	next(owner)
+
+	// The note is saved over the REST API during the session.
+	_, err = notesService.UpdateNote(ownerID, "1", entity.NoteRequest{Name: "Renamed", Body: "abc2"}, note.Version)
+	require.Equal(t, nil, err)
+
+	doc := editor.doc
+	doc.mu.Lock()
+	collabService.save(doc)
+	doc.mu.Unlock()
+
+	require.Equal(t, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Revision:  2,
+		Operation: ot.Operation{}.Retain(4).Insert("2"),
+		UserID:    ownerID,
+	}, next(owner))
+	require.Equal(t, entity.CollabMessage{Type: entity.CollabName, Revision: 2, Name: "Renamed", UserID: ownerID}, next(owner))
+
+	note, err = notesStorage.Find("1")
+	require.Equal(t, nil, err)
+	require.Equal(t, "Renamed", note.Name)
+	require.Equal(t, "1abc2", note.Body)
+	require.Equal(t, editorID, note.LastEditedBy)
+
+	revisions, err := notesService.Revisions(ownerID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(revisions.Revisions))
+
+	collabService.Leave(owner)
+	collabService.Leave(editor)
+	log.Println("SUCCESS")
+}
+
+func TestCollabSaveEditor(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	editorID := security.Hash("test2@mail.ru")
+
+	notesStorage, usersNotesStorage, _, collabService := newTestCollab()
+	usersNotesStorage.AddLink(editorID, "1", entity.RoleEditor)
+
+	owner, err := collabService.Join(ownerID, "test", "1")
+	require.Equal(t, nil, err)
+	editor, err := collabService.Join(editorID, "test2", "1")
+	require.Equal(t, nil, err)
+	next(owner)
+	next(editor)
+
+	require.Equal(t, nil, collabService.Receive(editor, entity.CollabMessage{
+		Type: entity.CollabName,
+		Name: "Plans",
+	}))
+	next(owner)
+
+	// The edits of an editor who has lost the role are saved by another one.
+	require.Equal(t, nil, usersNotesStorage.DeleteLink(editorID, "1"))
+	doc := owner.doc
+	doc.mu.Lock()
+	collabService.save(doc)
+	doc.mu.Unlock()
+
+	note, err := notesStorage.Find("1")
+	require.Equal(t, nil, err)
+	require.Equal(t, "Plans", note.Name)
+	require.Equal(t, ownerID, note.LastEditedBy)
+
+	// Without any editor the sessions are told that the edits are not saved.
+	require.Equal(t, nil, collabService.Receive(owner, entity.CollabMessage{
+		Type: entity.CollabName,
+		Name: "Plans v2",
+	}))
+	next(editor)
+	require.Equal(t, nil, usersNotesStorage.DeleteLink(ownerID, "1"))
+	doc.mu.Lock()
+	collabService.save(doc)
+	doc.mu.Unlock()
+	require.Equal(t, entity.CollabMessage{Type: entity.CollabError, Error: ErrNotSaved.Error()}, next(owner))
+	require.Equal(t, entity.CollabMessage{Type: entity.CollabError, Error: ErrNotSaved.Error()}, next(editor))
+
+	collabService.Leave(owner)
+	collabService.Leave(editor)
+	log.Println("SUCCESS")
+}
+
+func TestCollabHistory(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	editorID := security.Hash("test2@mail.ru")
+
+	notesStorage, usersNotesStorage, _, collabService := newTestCollab()
+	_, err := notesStorage.Update("1", entity.Note{Name: "1st note", Body: ""})
+	require.Equal(t, nil, err)
+	usersNotesStorage.AddLink(editorID, "1", entity.RoleEditor)
+
+	owner, err := collabService.Join(ownerID, "test", "1")
+	require.Equal(t, nil, err)
+	editor, err := collabService.Join(editorID, "test2", "1")
+	require.Equal(t, nil, err)
+	next(owner)
+	next(editor)
+
+	for revision := 0; revision < 3; revision++ {
+		require.Equal(t, nil, collabService.Receive(owner, entity.CollabMessage{
+			Type:      entity.CollabOperation,
+			Revision:  revision,
+			Operation: ot.Operation{}.Retain(revision).Insert("a"),
+		}))
+	}
+	require.Equal(t, 3, len(owner.doc.history))
+
+	// The history is kept until the idle editor has seen it.
+	require.Equal(t, nil, collabService.Receive(editor, entity.CollabMessage{Type: entity.CollabHeartbeat, Revision: 2}))
+	require.Equal(t, 1, len(owner.doc.history))
+	require.Equal(t, ErrUnknownRevision, collabService.Receive(editor, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Revision:  1,
+		Operation: ot.Operation{}.Retain(1),
+	}))
+
+	require.Equal(t, entity.ErrNoteBodyLengthExceedsLimit, collabService.Receive(editor, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Revision:  3,
+		Operation: ot.Operation{}.Retain(3).Insert(strings.Repeat("a", entity.MaxBodyLength)),
+	}))
+
+	// The document keeps the text as it is, only what clients get is
+	// sanitized.
+	for revision := 0; revision < 3; revision++ {
+		require.Equal(t, entity.CollabAck, next(owner).Type)
+	}
+	xss.NewXssSanitizer()
+	require.Equal(t, nil, collabService.Receive(editor, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Revision:  3,
+		Operation: ot.Operation{}.Retain(3).Insert(" & 'b'<script>alert(1)</script>"),
+	}))
+	require.Equal(t, nil, collabService.Receive(editor, entity.CollabMessage{
+		Type: entity.CollabName,
+		Name: "Tom's <img src=x onerror=a()>",
+	}))
+	require.Equal(t, entity.CollabMessage{
+		Type:      entity.CollabOperation,
+		Revision:  4,
+		Operation: ot.Operation{}.Retain(3).Insert(" &amp; &#39;b&#39;"),
+		UserID:    editorID,
+	}, next(owner))
+	require.Equal(t, entity.CollabMessage{Type: entity.CollabName, Revision: 4, Name: "Tom&#39;s <img src=\"x\">", UserID: editorID}, next(owner))
+
+	collabService.Leave(owner)
+	collabService.Leave(editor)
+
+	note, err := notesStorage.Find("1")
+	require.Equal(t, nil, err)
+	require.Equal(t, "aaa & 'b'<script>alert(1)</script>", note.Body)
+	require.Equal(t, "Tom's <img src=x onerror=a()>", note.Name)
+	log.Println("SUCCESS")
+}
+
+func newTestCollab() (*storage.NotesStorage, *storage.UsersNotesStorage, *notes.NotesApp, *CollabApp) {
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	presenceService := presence.NewPresenceApp(usersNotesStorage)
+	notesService := notes.NewNotesApp(notes.Dependencies{
+		Notes:       notesStorage,
+		UsersNotes:  usersNotesStorage,
+		Blocks:      storage.NewBlocksStorage(),
+		Revisions:   storage.NewRevisionsStorage(),
+		Tags:        storage.NewTagsStorage(),
+		NoteLinks:   storage.NewNoteLinksStorage(notesStorage),
+		Attachments: storage.NewAttachmentsStorage(),
+		Uploads:     storage.NewUploadsStorage(),
+		EventBus:    events.NewBus(),
+	})
+	return notesStorage, usersNotesStorage, notesService, NewCollabApp(notesStorage, usersNotesStorage, presenceService, notesService)
+}
+
+// next returns the next message of the session that is not a presence event.
+func next(session *Session) entity.CollabMessage {
+	for message := range session.Messages() {
+		if message.Type != entity.CollabPresence {
+			return message
+		}
+	}
+	return entity.CollabMessage{}
+}
@@ -0,0 +1,218 @@
+package comments
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/application/notifications"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const packageName = "app comments"
+
+var ErrCommentNotFound = errors.New("The comment does not exist.")
+var ErrNotCommentAuthor = errors.New("Only the author can edit the comment.")
+var ErrCommentDelete = errors.New("Only the author or an owner of the note can delete the comment.")
+var ErrReplyAnchor = errors.New("A reply cannot be anchored.")
+
+type CommentsApp struct {
+	commentsRepository   repository.CommentsRepository
+	usersNotesRepository repository.UsersNotesRepository
+	notificationsApp     *notifications.NotificationsApp
+}
+
+func NewCommentsApp(commentsRepo repository.CommentsRepository, usersNotesRepo repository.UsersNotesRepository,
+	notificationsApp *notifications.NotificationsApp) *CommentsApp {
+	return &CommentsApp{
+		commentsRepository:   commentsRepo,
+		usersNotesRepository: usersNotesRepo,
+		notificationsApp:     notificationsApp,
+	}
+}
+
+// Comments returns the threads of the note in the order they were started,
+// every thread has its replies in the order they were written.
+func (c *CommentsApp) Comments(userID string, noteToken string) (entity.Comments, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Comments",
+	})
+
+	if !c.usersNotesRepository.CheckLink(userID, noteToken) {
+		logger.Warning(notes.ErrNoteAccess)
+		return entity.Comments{}, notes.ErrNoteAccess
+	}
+
+	comments, err := c.commentsRepository.AllByNote(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Comments{}, err
+	}
+
+	threads := []entity.Comment{}
+	index := map[int]int{}
+	for _, comment := range comments {
+		if comment.ParentID == 0 {
+			index[comment.ID] = len(threads)
+			threads = append(threads, comment)
+		}
+	}
+	for _, comment := range comments {
+		if i, ok := index[comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+
+	return entity.Comments{Comments: threads}, nil
+}
+
+// CreateComment starts a thread or, if ParentID is set, replies in one. A
+// reply to a reply goes to the same thread. Viewers can read the comments
+// but not write them.
+func (c *CommentsApp) CreateComment(userID string, noteToken string, commentRequest entity.CommentRequest) (entity.Comment, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateComment",
+	})
+
+	if err := c.checkRole(userID, noteToken, entity.RoleCommenter); err != nil {
+		logger.Warning(err)
+		return entity.Comment{}, err
+	}
+
+	comment := entity.Comment{
+		NoteToken: noteToken,
+		UserID:    userID,
+		Body:      commentRequest.Body,
+		Anchor:    commentRequest.Anchor,
+		CreatedAt: time.Now(),
+	}
+	if commentRequest.ParentID != 0 {
+		if commentRequest.Anchor != nil {
+			logger.Warning(ErrReplyAnchor)
+			return entity.Comment{}, ErrReplyAnchor
+		}
+		parent, err := c.findComment(noteToken, commentRequest.ParentID)
+		if err != nil {
+			logger.Warning(err)
+			return entity.Comment{}, err
+		}
+		comment.ParentID = parent.ID
+		if parent.ParentID != 0 {
+			comment.ParentID = parent.ParentID
+		}
+	}
+	comment.UpdatedAt = comment.CreatedAt
+
+	commentID, err := c.commentsRepository.Save(comment)
+	if err != nil {
+		logger.Error(err)
+		return entity.Comment{}, err
+	}
+	comment.ID = commentID
+
+	if err := c.notificationsApp.Mention(userID, noteToken, comment.ID, comment.Body); err != nil {
+		logger.Warning(err)
+	}
+	return comment, nil
+}
+
+func (c *CommentsApp) UpdateComment(userID string, noteToken string, commentID int, commentRequest entity.CommentRequest) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateComment",
+	})
+
+	comment, err := c.checkComment(userID, noteToken, commentID, entity.RoleCommenter)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+	if comment.UserID != userID {
+		logger.Warning(ErrNotCommentAuthor)
+		return ErrNotCommentAuthor
+	}
+
+	comment.Body = commentRequest.Body
+	comment.UpdatedAt = time.Now()
+	if err := c.commentsRepository.Update(comment); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if err := c.notificationsApp.Mention(userID, noteToken, comment.ID, comment.Body); err != nil {
+		logger.Warning(err)
+	}
+	return nil
+}
+
+// DeleteComment removes the comment, deleting the start of a thread removes
+// the whole thread.
+func (c *CommentsApp) DeleteComment(userID string, noteToken string, commentID int) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteComment",
+	})
+
+	comment, err := c.checkComment(userID, noteToken, commentID, entity.RoleViewer)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+	if comment.UserID != userID {
+		role, err := c.usersNotesRepository.Role(userID, noteToken)
+		if err != nil || role != entity.RoleOwner {
+			logger.Warning(ErrCommentDelete)
+			return ErrCommentDelete
+		}
+	}
+
+	return c.commentsRepository.Delete(commentID)
+}
+
+// ResolveComment resolves or reopens the thread the comment belongs to.
+func (c *CommentsApp) ResolveComment(userID string, noteToken string, commentID int, resolved bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ResolveComment",
+	})
+
+	comment, err := c.checkComment(userID, noteToken, commentID, entity.RoleCommenter)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+	if comment.ParentID != 0 {
+		commentID = comment.ParentID
+	}
+
+	return c.commentsRepository.SetResolved(commentID, resolved)
+}
+
+func (c *CommentsApp) checkComment(userID string, noteToken string, commentID int, required string) (entity.Comment, error) {
+	if err := c.checkRole(userID, noteToken, required); err != nil {
+		return entity.Comment{}, err
+	}
+	return c.findComment(noteToken, commentID)
+}
+
+func (c *CommentsApp) checkRole(userID string, noteToken string, required string) error {
+	role, err := c.usersNotesRepository.Role(userID, noteToken)
+	if err != nil {
+		return notes.ErrNoteAccess
+	}
+	if !entity.RoleAllows(role, required) {
+		return notes.ErrNoteRole
+	}
+	return nil
+}
+
+func (c *CommentsApp) findComment(noteToken string, commentID int) (entity.Comment, error) {
+	comment, err := c.commentsRepository.Find(commentID)
+	if err != nil || comment.NoteToken != noteToken {
+		return entity.Comment{}, ErrCommentNotFound
+	}
+	return comment, nil
+}
@@ -0,0 +1,68 @@
+package comments
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	commenterID := security.Hash("nikita@mail.ru")
+	viewerID := security.Hash("test3@mail.ru")
+	strangerID := security.Hash("test2@mail.ru")
+
+	usersNotesStorage := storage.NewUsersNotesStorage(storage.NewNotesStorage())
+	usersNotesStorage.AddLink(commenterID, "1", entity.RoleCommenter)
+	usersNotesStorage.AddLink(viewerID, "1", entity.RoleViewer)
+	commentsService := NewCommentsApp(storage.NewCommentsStorage(), usersNotesStorage, nil)
+
+	_, err := commentsService.CreateComment(strangerID, "1", entity.CommentRequest{Body: "hi"})
+	require.Equal(t, notes.ErrNoteAccess, err)
+	_, err = commentsService.CreateComment(viewerID, "1", entity.CommentRequest{Body: "hi"})
+	require.Equal(t, notes.ErrNoteRole, err)
+
+	anchor := &entity.CommentAnchor{From: 2, To: 8}
+	thread, err := commentsService.CreateComment(ownerID, "1", entity.CommentRequest{Body: "Typo here", Anchor: anchor})
+	require.Equal(t, nil, err)
+	reply, err := commentsService.CreateComment(commenterID, "1", entity.CommentRequest{Body: "Fixed", ParentID: thread.ID})
+	require.Equal(t, nil, err)
+	require.Equal(t, thread.ID, reply.ParentID)
+	nested, err := commentsService.CreateComment(ownerID, "1", entity.CommentRequest{Body: "Thanks", ParentID: reply.ID})
+	require.Equal(t, nil, err)
+	require.Equal(t, thread.ID, nested.ParentID)
+	_, err = commentsService.CreateComment(ownerID, "1", entity.CommentRequest{Body: "x", ParentID: thread.ID, Anchor: anchor})
+	require.Equal(t, ErrReplyAnchor, err)
+	_, err = commentsService.CreateComment(ownerID, "3", entity.CommentRequest{Body: "x", ParentID: thread.ID})
+	require.Equal(t, ErrCommentNotFound, err)
+	second, err := commentsService.CreateComment(commenterID, "1", entity.CommentRequest{Body: "Second thread"})
+	require.Equal(t, nil, err)
+
+	require.Equal(t, ErrNotCommentAuthor, commentsService.UpdateComment(ownerID, "1", reply.ID, entity.CommentRequest{Body: "edited"}))
+	require.Equal(t, nil, commentsService.UpdateComment(commenterID, "1", reply.ID, entity.CommentRequest{Body: "Fixed it"}))
+	require.Equal(t, notes.ErrNoteRole, commentsService.ResolveComment(viewerID, "1", nested.ID, true))
+	require.Equal(t, nil, commentsService.ResolveComment(commenterID, "1", nested.ID, true))
+
+	comments, err := commentsService.Comments(viewerID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(comments.Comments))
+	require.Equal(t, thread.ID, comments.Comments[0].ID)
+	require.Equal(t, true, comments.Comments[0].Resolved)
+	require.Equal(t, anchor, comments.Comments[0].Anchor)
+	require.Equal(t, 2, len(comments.Comments[0].Replies))
+	require.Equal(t, "Fixed it", comments.Comments[0].Replies[0].Body)
+	require.Equal(t, second.ID, comments.Comments[1].ID)
+
+	require.Equal(t, ErrCommentDelete, commentsService.DeleteComment(commenterID, "1", thread.ID))
+	require.Equal(t, nil, commentsService.DeleteComment(ownerID, "1", thread.ID))
+
+	comments, err = commentsService.Comments(ownerID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, len(comments.Comments))
+	require.Equal(t, second.ID, comments.Comments[0].ID)
+	log.Println("SUCCESS")
+}
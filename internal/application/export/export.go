@@ -0,0 +1,205 @@
+package export
+
+import (
+	"archive/zip"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/markdown"
+	"encoding/json"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"mime"
+	"time"
+)
+
+const (
+	packageName = "app export"
+
+	manifestPath = "manifest.json"
+	avatarName   = "avatar"
+	notesDir     = "notes/"
+)
+
+// avatarExtensions are the extensions of the usual avatar types, other
+// types get the one the mime package knows.
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type ExportApp struct {
+	notesRepository      repository.NotesRepository
+	usersNotesRepository repository.UsersNotesRepository
+	blocksRepository     repository.BlocksRepository
+	shareLinksRepository repository.ShareLinksRepository
+	userRepository       repository.UserRepository
+	imageRepository      repository.ImageRepository
+}
+
+func NewExportApp(notesRepo repository.NotesRepository, usersNotesRepo repository.UsersNotesRepository,
+	blocksRepo repository.BlocksRepository, shareLinksRepo repository.ShareLinksRepository,
+	userRepo repository.UserRepository, imageRepo repository.ImageRepository) *ExportApp {
+	return &ExportApp{
+		notesRepository:      notesRepo,
+		usersNotesRepository: usersNotesRepo,
+		blocksRepository:     blocksRepo,
+		shareLinksRepository: shareLinksRepo,
+		userRepository:       userRepo,
+		imageRepository:      imageRepo,
+	}
+}
+
+// ExportWorkspace writes a ZIP archive of everything the user has to w:
+// every note they can open as Markdown and as JSON, their avatar and a
+// manifest. The archive is written note by note, so it is never held in
+// memory as a whole.
+func (e *ExportApp) ExportWorkspace(userID string, w io.Writer) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ExportWorkspace",
+	})
+
+	user, err := e.userRepository.Get(userID)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+	notes, err := e.usersNotesRepository.AllNotesByUserID(userID, entity.NotesFilter{Body: entity.BodyNone})
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	manifest := entity.ExportManifest{
+		ExportedAt: time.Now(),
+		User:       entity.ExportUser{UserID: userID, Username: user.Username, Email: user.Email},
+		Notes:      []entity.ExportManifestNote{},
+	}
+
+	for _, shortNote := range notes.ShortNote {
+		manifestNote, err := e.exportNote(archive, userID, shortNote.Token)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+		manifest.Notes = append(manifest.Notes, manifestNote)
+	}
+
+	if user.Avatar != "" {
+		if path, err := e.exportAvatar(archive, user.Avatar); err != nil {
+			// A backup without the avatar is better than no backup.
+			logger.Warning(err)
+		} else {
+			manifest.Avatar = path
+		}
+	}
+
+	if err := writeJSON(archive, manifestPath, manifest); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return archive.Close()
+}
+
+func (e *ExportApp) exportNote(archive *zip.Writer, userID string, noteToken string) (entity.ExportManifestNote, error) {
+	note, err := e.notesRepository.Find(noteToken)
+	if err != nil {
+		return entity.ExportManifestNote{}, err
+	}
+	if note.Blocks, err = e.blocksRepository.AllByNote(noteToken); err != nil {
+		return entity.ExportManifestNote{}, err
+	}
+	role, err := e.usersNotesRepository.Role(userID, noteToken)
+	if err != nil {
+		return entity.ExportManifestNote{}, err
+	}
+	members, err := e.usersNotesRepository.Members(noteToken)
+	if err != nil {
+		return entity.ExportManifestNote{}, err
+	}
+	// Only editors manage the share links of a note.
+	var links []entity.ShareLink
+	if entity.RoleAllows(role, entity.RoleEditor) {
+		if links, err = e.shareLinksRepository.AllByNote(noteToken); err != nil {
+			return entity.ExportManifestNote{}, err
+		}
+	}
+
+	manifestNote := entity.ExportManifestNote{
+		Token:      noteToken,
+		Name:       note.Name,
+		Parent:     note.Parent,
+		Role:       role,
+		Markdown:   notesDir + noteToken + ".md",
+		JSON:       notesDir + noteToken + ".json",
+		Members:    members,
+		ShareLinks: links,
+	}
+	if manifestNote.Members == nil {
+		manifestNote.Members = []entity.Member{}
+	}
+	if manifestNote.ShareLinks == nil {
+		manifestNote.ShareLinks = []entity.ShareLink{}
+	}
+
+	file, err := archive.Create(manifestNote.Markdown)
+	if err != nil {
+		return entity.ExportManifestNote{}, err
+	}
+	if _, err := io.WriteString(file, markdown.Render(note)); err != nil {
+		return entity.ExportManifestNote{}, err
+	}
+	if err := writeJSON(archive, manifestNote.JSON, note); err != nil {
+		return entity.ExportManifestNote{}, err
+	}
+
+	return manifestNote, nil
+}
+
+// exportAvatar writes the avatar and returns its path in the archive, the
+// extension is taken from the type of the stored image.
+func (e *ExportApp) exportAvatar(archive *zip.Writer, avatar string) (string, error) {
+	img, err := e.imageRepository.DownloadFile(avatar)
+	if err != nil {
+		return "", err
+	}
+	defer img.Close()
+
+	// The object is only fetched when it is used, a missing avatar must not
+	// leave an empty file in the archive.
+	info, err := img.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	path := avatarPath(info.ContentType)
+	file, err := archive.Create(path)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(file, img)
+	return path, err
+}
+
+func avatarPath(contentType string) string {
+	if extension, ok := avatarExtensions[contentType]; ok {
+		return avatarName + extension
+	}
+	if extensions, err := mime.ExtensionsByType(contentType); err == nil && len(extensions) != 0 {
+		return avatarName + extensions[0]
+	}
+	return avatarName
+}
+
+func writeJSON(archive *zip.Writer, name string, value interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
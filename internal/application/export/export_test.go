@@ -0,0 +1,90 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"log"
+	"testing"
+)
+
+func TestExportWorkspace(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	shareLinksStorage := storage.NewShareLinksStorage()
+	require.Equal(t, nil, shareLinksStorage.Save(entity.ShareLink{Token: "abc", NoteToken: "1", CreatedBy: userID}))
+	require.Equal(t, nil, shareLinksStorage.Save(entity.ShareLink{Token: "def", NoteToken: "2", CreatedBy: "owner"}))
+	require.Equal(t, nil, usersNotesStorage.AddLink(userID, "2", entity.RoleViewer))
+	exportService := NewExportApp(notesStorage, usersNotesStorage, storage.NewBlocksStorage(), shareLinksStorage,
+		storage.NewUserCacheStorage(security.NewSimpleSecurityManager()), nil)
+
+	var buf bytes.Buffer
+	require.Equal(t, nil, exportService.ExportWorkspace(userID, &buf))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.Equal(t, nil, err)
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		src, err := file.Open()
+		require.Equal(t, nil, err)
+		files[file.Name], err = ioutil.ReadAll(src)
+		require.Equal(t, nil, err)
+		src.Close()
+	}
+	require.Equal(t, 7, len(files))
+	require.Equal(t, "# 1st note\n\nHello everybody. This is Body of the 1st note)\n", string(files["notes/1.md"]))
+
+	var note entity.Note
+	require.Equal(t, nil, json.Unmarshal(files["notes/3.json"], &note))
+	require.Equal(t, "3st note", note.Name)
+
+	var manifest entity.ExportManifest
+	require.Equal(t, nil, json.Unmarshal(files["manifest.json"], &manifest))
+	require.Equal(t, "test@mail.ru", manifest.User.Email)
+	require.Equal(t, "", manifest.Avatar)
+	require.Equal(t, 3, len(manifest.Notes))
+	for _, manifestNote := range manifest.Notes {
+		require.Contains(t, files, manifestNote.Markdown)
+		require.Contains(t, files, manifestNote.JSON)
+		switch manifestNote.Token {
+		case "1":
+			require.Equal(t, entity.RoleOwner, manifestNote.Role)
+			require.Equal(t, 1, len(manifestNote.Members))
+			require.Equal(t, "abc", manifestNote.ShareLinks[0].Token)
+		case "2":
+			// Viewers do not get the share links.
+			require.Equal(t, entity.RoleViewer, manifestNote.Role)
+			require.Equal(t, 2, len(manifestNote.Members))
+			require.Equal(t, []entity.ShareLink{}, manifestNote.ShareLinks)
+		}
+	}
+
+	require.NotEqual(t, nil, exportService.ExportWorkspace(security.Hash("nobody@mail.ru"), &buf))
+	log.Println("SUCCESS")
+}
+
+func TestAvatarPath(t *testing.T) {
+	cases := map[string]struct {
+		contentType string
+		expected    string
+	}{
+		"jpeg":    {contentType: "image/jpeg", expected: "avatar.jpg"},
+		"png":     {contentType: "image/png", expected: "avatar.png"},
+		"unknown": {contentType: "application/x-unknown", expected: "avatar"},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, avatarPath(tc.contentType))
+		})
+		log.Println("SUCCESS")
+	}
+}
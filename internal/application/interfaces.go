@@ -1,19 +1,119 @@
 package application
 
 import (
+	"cotion/internal/application/collab"
 	"cotion/internal/domain/entity"
 	"github.com/minio/minio-go/v7"
+	"io"
 	"mime/multipart"
 	"net/http"
 )
 
 type NotesAppManager interface {
 	//FindByToken(token string) (entity.Note, error)
-	AllNotesByUserID(userID string) (entity.ShortNotes, error)
+	AllNotesByUserID(userID string, filter entity.NotesFilter) (entity.ShortNotes, error)
+	NotesTree(userID string) (entity.NotesTree, error)
+	NotesEvents(userID string) (<-chan entity.NoteEvent, func())
+	SearchNotes(userID string, query string) (entity.FoundNotes, error)
 	SaveNote(userID string, noteRequest entity.NoteRequest) error
 	GetNote(userID string, noteToken string) (entity.Note, error)
-	UpdateNote(userID string, noteToken string, noteRequest entity.NoteRequest) error
+	UpdateNote(userID string, noteToken string, noteRequest entity.NoteRequest, version int) (int, error)
 	DeleteNote(userID string, noteToken string) error
+	Trash(userID string) (entity.TrashedNotes, error)
+	RestoreNote(userID string, noteToken string) error
+	DeleteNoteForever(userID string, noteToken string) error
+	MoveNote(userID string, noteToken string, parentToken string) error
+	DuplicateNote(userID string, noteToken string, ownerID string, duplicateRequest entity.DuplicateRequest) (entity.DuplicatedNote, error)
+	PinNote(userID string, noteToken string, pinned bool) error
+	FavoriteNote(userID string, noteToken string, favorite bool) error
+	ReorderNotes(userID string, noteTokens []string) error
+	InsertBlock(userID string, noteToken string, blockRequest entity.BlockRequest) (entity.Block, error)
+	UpdateBlock(userID string, noteToken string, blockID string, blockRequest entity.BlockRequest) error
+	MoveBlock(userID string, noteToken string, blockID string, position int) error
+	DeleteBlock(userID string, noteToken string, blockID string) error
+	Revisions(userID string, noteToken string) (entity.Revisions, error)
+	GetRevision(userID string, noteToken string, revisionID int) (entity.Revision, error)
+	DiffRevisions(userID string, noteToken string, fromID int, toID int) (entity.RevisionDiff, error)
+	RestoreRevision(userID string, noteToken string, revisionID int) error
+	Backlinks(userID string, noteToken string) (entity.Backlinks, error)
+	NotesGraph(userID string) (entity.NotesGraph, error)
+	ExportNote(userID string, noteToken string, format string) (entity.ExportedNote, error)
+	ImportNotes(userID string, parentToken string, files []*multipart.FileHeader) (entity.ImportedNotes, error)
+	SetEmojiIcon(userID string, noteToken string, iconRequest entity.IconRequest) error
+	UploadIcon(userID string, noteToken string, src multipart.File, hdr *multipart.FileHeader) error
+	DownloadIcon(userID string, noteToken string) (*minio.Object, error)
+	DeleteIcon(userID string, noteToken string) error
+	UploadCover(userID string, noteToken string, src multipart.File, hdr *multipart.FileHeader) error
+	DownloadCover(userID string, noteToken string) (*minio.Object, error)
+	DeleteCover(userID string, noteToken string) error
+	Attachments(userID string, noteToken string) (entity.Attachments, error)
+	UploadAttachment(userID string, noteToken string, src multipart.File, hdr *multipart.FileHeader) (entity.Attachment, error)
+	DownloadAttachment(userID string, noteToken string, attachmentID int) (entity.Attachment, *minio.Object, error)
+	DeleteAttachment(userID string, noteToken string, attachmentID int) error
+	CreateUpload(userID string, noteToken string, uploadRequest entity.UploadRequest) (entity.Upload, error)
+	UploadStatus(userID string, noteToken string, uploadID string) (entity.Upload, error)
+	UploadChunk(userID string, noteToken string, uploadID string, offset int64, chunk io.Reader, length int64) (entity.Upload, error)
+	AbortUpload(userID string, noteToken string, uploadID string) error
+}
+
+type CollabAppManager interface {
+	Join(userID string, username string, noteToken string) (*collab.Session, error)
+	Receive(session *collab.Session, message entity.CollabMessage) error
+	Leave(session *collab.Session)
+}
+
+type PresenceAppManager interface {
+	Presence(userID string, noteToken string) (entity.Presence, error)
+}
+
+type TagsAppManager interface {
+	Tags(userID string) (entity.Tags, error)
+	CreateTag(userID string, tagRequest entity.TagRequest) (entity.Tag, error)
+	UpdateTag(userID string, tagID int, tagRequest entity.TagRequest) error
+	DeleteTag(userID string, tagID int) error
+	AttachTag(userID string, noteToken string, tagID int) error
+	DetachTag(userID string, noteToken string, tagID int) error
+}
+
+type TemplatesAppManager interface {
+	Templates(userID string) (entity.Templates, error)
+	CreateTemplate(userID string, templateRequest entity.TemplateRequest) (entity.Template, error)
+	UpdateTemplate(userID string, templateID int, templateRequest entity.TemplateRequest) error
+	DeleteTemplate(userID string, templateID int) error
+	SaveNoteAsTemplate(userID string, noteToken string) (entity.Template, error)
+	CreateNote(userID string, username string, templateID int, noteRequest entity.NoteRequest) error
+}
+
+type CommentsAppManager interface {
+	Comments(userID string, noteToken string) (entity.Comments, error)
+	CreateComment(userID string, noteToken string, commentRequest entity.CommentRequest) (entity.Comment, error)
+	UpdateComment(userID string, noteToken string, commentID int, commentRequest entity.CommentRequest) error
+	DeleteComment(userID string, noteToken string, commentID int) error
+	ResolveComment(userID string, noteToken string, commentID int, resolved bool) error
+}
+
+type NotificationsAppManager interface {
+	Notifications(userID string) (entity.Notifications, error)
+	MarkRead(userID string, notificationID int) error
+	MarkAllRead(userID string) error
+}
+
+type ExportAppManager interface {
+	ExportWorkspace(userID string, w io.Writer) error
+}
+
+type MembersAppManager interface {
+	Members(userID string, noteToken string) (entity.Members, error)
+	AddMember(userID string, noteToken string, memberRequest entity.MemberRequest) error
+	ChangeRole(userID string, noteToken string, memberID string, role string) error
+	RevokeMember(userID string, noteToken string, memberID string) error
+}
+
+type ShareAppManager interface {
+	CreateLink(userID string, noteToken string, linkRequest entity.ShareLinkRequest) (entity.ShareLink, error)
+	Links(userID string, noteToken string) (entity.ShareLinks, error)
+	RevokeLink(userID string, noteToken string, shareToken string) error
+	PublicNote(shareToken string, password string) (entity.Note, error)
 }
 
 type UserAppManager interface {
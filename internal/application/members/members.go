@@ -0,0 +1,181 @@
+package members
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/events"
+	"cotion/internal/pkg/security"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const packageName = "app members"
+
+var ErrNotOwner = errors.New("Only owners of the note can manage its members.")
+var ErrNoSuchUser = errors.New("There is no registered user with this email.")
+var ErrAlreadyMember = errors.New("The user is already a member of this note.")
+var ErrNoSuchMember = errors.New("The user is not a member of this note.")
+var ErrLastOwner = errors.New("The note must keep at least one owner.")
+
+type MembersApp struct {
+	usersNotesRepository repository.UsersNotesRepository
+	userRepository       repository.UserRepository
+	securityManager      security.Manager
+	eventBus             *events.Bus
+}
+
+func NewMembersApp(usersNotesRepo repository.UsersNotesRepository, userRepo repository.UserRepository, securityManager security.Manager,
+	eventBus *events.Bus) *MembersApp {
+	return &MembersApp{
+		usersNotesRepository: usersNotesRepo,
+		userRepository:       userRepo,
+		securityManager:      securityManager,
+		eventBus:             eventBus,
+	}
+}
+
+func (m *MembersApp) Members(userID string, noteToken string) (entity.Members, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Members",
+	})
+
+	if !m.usersNotesRepository.CheckLink(userID, noteToken) {
+		logger.Warning(notes.ErrNoteAccess)
+		return entity.Members{}, notes.ErrNoteAccess
+	}
+
+	members, err := m.usersNotesRepository.Members(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Members{}, err
+	}
+	return entity.Members{Members: members}, nil
+}
+
+func (m *MembersApp) AddMember(userID string, noteToken string, memberRequest entity.MemberRequest) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AddMember",
+	})
+
+	if err := m.checkOwner(userID, noteToken); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	memberID := m.securityManager.Hash(memberRequest.Email)
+	if _, err := m.userRepository.Get(memberID); err != nil {
+		logger.Warning(err)
+		return ErrNoSuchUser
+	}
+	if m.usersNotesRepository.CheckLink(memberID, noteToken) {
+		logger.Warning(ErrAlreadyMember)
+		return ErrAlreadyMember
+	}
+
+	if err := m.usersNotesRepository.AddLink(memberID, noteToken, memberRequest.Role); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	members, err := m.usersNotesRepository.Members(noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return nil
+	}
+	event := entity.NoteEvent{Type: entity.NoteShared, NoteToken: noteToken, UserID: userID}
+	for _, member := range members {
+		event.UserIDs = append(event.UserIDs, member.UserID)
+	}
+	m.eventBus.Publish(event)
+	return nil
+}
+
+func (m *MembersApp) ChangeRole(userID string, noteToken string, memberID string, role string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ChangeRole",
+	})
+
+	if err := m.checkOwner(userID, noteToken); err != nil {
+		logger.Warning(err)
+		return err
+	}
+	if role != entity.RoleOwner {
+		if err := m.checkOtherOwner(noteToken, memberID); err != nil {
+			logger.Warning(err)
+			return err
+		}
+	}
+
+	return m.usersNotesRepository.UpdateRole(memberID, noteToken, role)
+}
+
+// RevokeMember removes a member from the note. Owners can revoke anyone,
+// other members can only leave the note themselves.
+func (m *MembersApp) RevokeMember(userID string, noteToken string, memberID string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "RevokeMember",
+	})
+
+	if userID != memberID {
+		if err := m.checkOwner(userID, noteToken); err != nil {
+			logger.Warning(err)
+			return err
+		}
+	}
+	if err := m.checkOtherOwner(noteToken, memberID); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := m.usersNotesRepository.DeleteLink(memberID, noteToken); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	m.eventBus.Publish(entity.NoteEvent{
+		Type:      entity.NoteDeleted,
+		NoteToken: noteToken,
+		UserID:    userID,
+		UserIDs:   []string{memberID},
+	})
+	return nil
+}
+
+func (m *MembersApp) checkOwner(userID string, noteToken string) error {
+	role, err := m.usersNotesRepository.Role(userID, noteToken)
+	if err != nil {
+		return notes.ErrNoteAccess
+	}
+	if role != entity.RoleOwner {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// checkOtherOwner makes sure the note still has an owner
+// once the member stops being one.
+func (m *MembersApp) checkOtherOwner(noteToken string, memberID string) error {
+	role, err := m.usersNotesRepository.Role(memberID, noteToken)
+	if err != nil {
+		return ErrNoSuchMember
+	}
+	if role != entity.RoleOwner {
+		return nil
+	}
+
+	members, err := m.usersNotesRepository.Members(noteToken)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.UserID != memberID && member.Role == entity.RoleOwner {
+			return nil
+		}
+	}
+	return ErrLastOwner
+}
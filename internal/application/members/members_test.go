@@ -0,0 +1,74 @@
+package members
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/events"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMembers(t *testing.T) {
+	securityManager := security.NewSimpleSecurityManager()
+	ownerID := security.Hash("test@mail.ru")
+	memberID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	userStorage := storage.NewUserCacheStorage(securityManager)
+	membersService := NewMembersApp(usersNotesStorage, userStorage, securityManager, events.NewBus())
+
+	cases := map[string]struct {
+		process  func() error
+		expected error
+	}{
+		"Invite unknown user": {
+			process: func() error {
+				return membersService.AddMember(ownerID, "1", entity.MemberRequest{Email: "nobody@mail.ru", Role: entity.RoleViewer})
+			},
+			expected: ErrNoSuchUser,
+		},
+		"Invite by not owner": {
+			process: func() error {
+				return membersService.AddMember(memberID, "1", entity.MemberRequest{Email: "test2@mail.ru", Role: entity.RoleViewer})
+			},
+			expected: notes.ErrNoteAccess,
+		},
+		"Revoke last owner": {
+			process: func() error {
+				return membersService.RevokeMember(ownerID, "3", ownerID)
+			},
+			expected: ErrLastOwner,
+		},
+		"Revoke not member": {
+			process: func() error {
+				return membersService.RevokeMember(ownerID, "3", memberID)
+			},
+			expected: ErrNoSuchMember,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.process())
+		})
+	}
+
+	require.Equal(t, nil, membersService.AddMember(ownerID, "1", entity.MemberRequest{Email: "nikita@mail.ru", Role: entity.RoleViewer}))
+	require.Equal(t, ErrAlreadyMember, membersService.AddMember(ownerID, "1", entity.MemberRequest{Email: "nikita@mail.ru", Role: entity.RoleEditor}))
+
+	members, err := membersService.Members(memberID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(members.Members))
+
+	require.Equal(t, ErrNotOwner, membersService.ChangeRole(memberID, "1", memberID, entity.RoleOwner))
+	require.Equal(t, nil, membersService.ChangeRole(ownerID, "1", memberID, entity.RoleOwner))
+	require.Equal(t, nil, membersService.ChangeRole(ownerID, "1", ownerID, entity.RoleEditor))
+	require.Equal(t, ErrLastOwner, membersService.ChangeRole(memberID, "1", memberID, entity.RoleViewer))
+
+	require.Equal(t, nil, membersService.RevokeMember(ownerID, "1", ownerID))
+	require.Equal(t, false, usersNotesStorage.CheckLink(ownerID, "1"))
+}
@@ -0,0 +1,206 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"github.com/minio/minio-go/v7"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// sniffLength is how much of a file http.DetectContentType looks at.
+	sniffLength        = 512
+	defaultFileName    = "file"
+	genericContentType = "application/octet-stream"
+	plainTextMediaType = "text/plain"
+)
+
+var ErrAttachmentNotFound = errors.New("The attachment does not exist.")
+
+func (n *NotesApp) Attachments(userID string, noteToken string) (entity.Attachments, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Attachments",
+		"noteToken": noteToken,
+	})
+
+	if err := n.checkRole(userID, noteToken, entity.RoleViewer); err != nil {
+		logger.Warning(err)
+		return entity.Attachments{}, err
+	}
+
+	attachments, err := n.attachmentsRepository.AllByNote(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Attachments{}, err
+	}
+	if attachments == nil {
+		attachments = []entity.Attachment{}
+	}
+	return entity.Attachments{Attachments: attachments}, nil
+}
+
+// UploadAttachment stores the file and attaches it to the note. The type of
+// the file is detected from its content, the extension is only used when the
+// content is not recognised.
+func (n *NotesApp) UploadAttachment(userID string, noteToken string, src multipart.File, hdr *multipart.FileHeader) (entity.Attachment, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "UploadAttachment",
+		"noteToken": noteToken,
+	})
+	defer src.Close()
+
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		logger.Warning(err)
+		return entity.Attachment{}, err
+	}
+
+	fileName := attachmentFileName(hdr.Filename)
+	contentType, err := detectContentType(src, fileName)
+	if err != nil {
+		logger.Error(err)
+		return entity.Attachment{}, err
+	}
+
+	objectName, err := n.fileRepository.UploadObject(entity.FileUnit{
+		Payload:     src,
+		PayloadSize: hdr.Size,
+		ContentType: contentType,
+	})
+	if err != nil {
+		logger.Error(err)
+		return entity.Attachment{}, err
+	}
+
+	attachment := entity.Attachment{
+		NoteToken:   noteToken,
+		ObjectName:  objectName,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        hdr.Size,
+		UploadedBy:  userID,
+		CreatedAt:   time.Now(),
+	}
+	attachment.ID, err = n.attachmentsRepository.Save(attachment)
+	if err != nil {
+		logger.Error(err)
+		n.deleteAttachmentFile(attachment)
+		return entity.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// DownloadAttachment returns the attachment with its file, which the caller
+// has to close.
+func (n *NotesApp) DownloadAttachment(userID string, noteToken string, attachmentID int) (entity.Attachment, *minio.Object, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "DownloadAttachment",
+		"noteToken": noteToken,
+	})
+
+	attachment, err := n.findAttachment(userID, noteToken, attachmentID, entity.RoleViewer)
+	if err != nil {
+		logger.Warning(err)
+		return entity.Attachment{}, nil, err
+	}
+
+	file, err := n.fileRepository.DownloadFile(attachment.ObjectName)
+	if err != nil {
+		logger.Error(err)
+		return entity.Attachment{}, nil, err
+	}
+	return attachment, file, nil
+}
+
+func (n *NotesApp) DeleteAttachment(userID string, noteToken string, attachmentID int) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "DeleteAttachment",
+		"noteToken": noteToken,
+	})
+
+	attachment, err := n.findAttachment(userID, noteToken, attachmentID, entity.RoleEditor)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.attachmentsRepository.Delete(attachmentID); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	n.deleteAttachmentFile(attachment)
+	return nil
+}
+
+// findAttachment returns the attachment if it belongs to the note and the
+// user's role on the note is at least the required one.
+func (n *NotesApp) findAttachment(userID string, noteToken string, attachmentID int, required string) (entity.Attachment, error) {
+	if err := n.checkRole(userID, noteToken, required); err != nil {
+		return entity.Attachment{}, err
+	}
+
+	attachment, err := n.attachmentsRepository.Find(attachmentID)
+	if err != nil || attachment.NoteToken != noteToken {
+		return entity.Attachment{}, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// deleteAttachmentFile removes the file of a deleted attachment, a failure
+// is only logged like for images.
+func (n *NotesApp) deleteAttachmentFile(attachment entity.Attachment) {
+	if err := n.fileRepository.DeleteFile(attachment.ObjectName); err != nil {
+		log.WithFields(log.Fields{
+			"package":      packageName,
+			"function":     "deleteAttachmentFile",
+			"attachmentID": attachment.ID,
+		}).Warning(err)
+	}
+}
+
+// detectContentType sniffs the beginning of the file and rewinds it.
+func detectContentType(src multipart.File, fileName string) (string, error) {
+	head := make([]byte, sniffLength)
+	length, err := io.ReadFull(src, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return sniffContentType(head[:length], fileName), nil
+}
+
+// sniffContentType detects the type of the file from its beginning.
+// Content that is only recognised as binary or plain text is given the type
+// of the file's extension, if it has a known one.
+func sniffContentType(head []byte, fileName string) string {
+	contentType := http.DetectContentType(head)
+	if contentType == genericContentType || strings.HasPrefix(contentType, plainTextMediaType) {
+		if byExtension := mime.TypeByExtension(filepath.Ext(fileName)); byExtension != "" {
+			return byExtension
+		}
+	}
+	return contentType
+}
+
+// attachmentFileName keeps only the base name of the uploaded file.
+func attachmentFileName(fileName string) string {
+	fileName = strings.TrimSpace(filepath.Base(strings.ReplaceAll(fileName, `\`, "/")))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return defaultFileName
+	}
+	return truncateName(fileName, entity.MaxFileNameLength)
+}
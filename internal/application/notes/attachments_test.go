@@ -0,0 +1,110 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestAttachments(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	files := newFilesStorage()
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Files = files
+	notesService := NewNotesApp(deps)
+
+	src, hdr := formFile(t, "report.pdf", "application/octet-stream", []byte("%PDF-1.4 report"))
+	_, err := notesService.UploadAttachment(userID, "2", src, hdr)
+	require.Equal(t, ErrNoteAccess, err)
+
+	src, hdr = formFile(t, "report.pdf", "application/octet-stream", []byte("%PDF-1.4 report"))
+	report, err := notesService.UploadAttachment(userID, "1", src, hdr)
+	require.Equal(t, nil, err)
+	require.Equal(t, "report.pdf", report.FileName)
+	require.Equal(t, "application/pdf", report.ContentType)
+	require.Equal(t, int64(15), report.Size)
+	require.Equal(t, "application/pdf", files.files[report.ObjectName])
+
+	src, hdr = formFile(t, "data.json", "text/html", []byte(`{"a": 1}`))
+	data, err := notesService.UploadAttachment(userID, "1", src, hdr)
+	require.Equal(t, nil, err)
+	require.Equal(t, "application/json", data.ContentType)
+
+	attachments, err := notesService.Attachments(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, []entity.Attachment{report, data}, attachments.Attachments)
+	_, err = notesService.Attachments(otherID, "1")
+	require.Equal(t, ErrNoteAccess, err)
+
+	attachment, _, err := notesService.DownloadAttachment(userID, "1", report.ID)
+	require.Equal(t, nil, err)
+	require.Equal(t, report, attachment)
+	_, _, err = notesService.DownloadAttachment(userID, "3", report.ID)
+	require.Equal(t, ErrAttachmentNotFound, err)
+
+	require.Equal(t, nil, usersNotesStorage.AddLink(otherID, "1", entity.RoleViewer))
+	_, _, err = notesService.DownloadAttachment(otherID, "1", report.ID)
+	require.Equal(t, nil, err)
+	require.Equal(t, ErrNoteRole, notesService.DeleteAttachment(otherID, "1", report.ID))
+
+	require.Equal(t, nil, notesService.DeleteAttachment(userID, "1", report.ID))
+	require.Equal(t, ErrAttachmentNotFound, notesService.DeleteAttachment(userID, "1", report.ID))
+	require.Equal(t, 1, len(files.files))
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, nil, notesService.DeleteNoteForever(userID, "1"))
+	require.Equal(t, 0, len(files.files))
+	log.Println("SUCCESS")
+}
+
+func TestDetectContentType(t *testing.T) {
+	cases := map[string]struct {
+		inFileName string
+		inContent  []byte
+		expected   string
+	}{
+		"Sniffed": {
+			inFileName: "image.txt",
+			inContent:  []byte("\x89PNG\x0D\x0A\x1A\x0A"),
+			expected:   "image/png",
+		},
+		"By extension": {
+			inFileName: "style.css",
+			inContent:  []byte("body { color: red; }"),
+			expected:   "text/css; charset=utf-8",
+		},
+		"Plain text": {
+			inFileName: "notes",
+			inContent:  []byte("hello"),
+			expected:   "text/plain; charset=utf-8",
+		},
+		"Unknown": {
+			inFileName: "data",
+			inContent:  []byte{0, 1, 2, 3},
+			expected:   "application/octet-stream",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			src, _ := formFile(t, tc.inFileName, "application/octet-stream", tc.inContent)
+			contentType, err := detectContentType(src, tc.inFileName)
+			require.Equal(t, nil, err)
+			require.Equal(t, tc.expected, contentType)
+
+			content := make([]byte, len(tc.inContent))
+			_, err = src.Read(content)
+			require.Equal(t, nil, err)
+			require.Equal(t, tc.inContent, content)
+		})
+		log.Println("SUCCESS")
+	}
+}
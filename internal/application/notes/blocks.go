@@ -0,0 +1,118 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/generator"
+	log "github.com/sirupsen/logrus"
+)
+
+func (n *NotesApp) InsertBlock(userID string, noteToken string, blockRequest entity.BlockRequest) (entity.Block, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "InsertBlock",
+	})
+
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		logger.Warning(err)
+		return entity.Block{}, err
+	}
+
+	blocks, err := n.blocksRepository.AllByNote(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Block{}, err
+	}
+
+	// Without an explicit position the block is appended to the end.
+	position := len(blocks)
+	if blockRequest.Position != nil {
+		position = clampPosition(*blockRequest.Position, len(blocks))
+	}
+
+	block := entity.Block{
+		ID:       generator.RandSID(blockIDLength),
+		Type:     blockRequest.Type,
+		Position: position,
+		Content:  blockRequest.Content,
+	}
+	if err := n.blocksRepository.Insert(noteToken, block); err != nil {
+		logger.Error(err)
+		return entity.Block{}, err
+	}
+
+	n.saveBlocksRevision(userID, noteToken)
+	return block, nil
+}
+
+func (n *NotesApp) UpdateBlock(userID string, noteToken string, blockID string, blockRequest entity.BlockRequest) error {
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "UpdateBlock",
+		}).Warning(err)
+		return err
+	}
+
+	if err := n.blocksRepository.Update(noteToken, entity.Block{
+		ID:      blockID,
+		Type:    blockRequest.Type,
+		Content: blockRequest.Content,
+	}); err != nil {
+		return err
+	}
+
+	n.saveBlocksRevision(userID, noteToken)
+	return nil
+}
+
+func (n *NotesApp) MoveBlock(userID string, noteToken string, blockID string, position int) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "MoveBlock",
+	})
+
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	blocks, err := n.blocksRepository.AllByNote(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if err := n.blocksRepository.Move(noteToken, blockID, clampPosition(position, len(blocks)-1)); err != nil {
+		return err
+	}
+
+	n.saveBlocksRevision(userID, noteToken)
+	return nil
+}
+
+func (n *NotesApp) DeleteBlock(userID string, noteToken string, blockID string) error {
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "DeleteBlock",
+		}).Warning(err)
+		return err
+	}
+
+	if err := n.blocksRepository.Delete(noteToken, blockID); err != nil {
+		return err
+	}
+
+	n.saveBlocksRevision(userID, noteToken)
+	return nil
+}
+
+func clampPosition(position int, max int) int {
+	if position > max {
+		position = max
+	}
+	if position < 0 {
+		position = 0
+	}
+	return position
+}
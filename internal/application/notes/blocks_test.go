@@ -0,0 +1,54 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func blockTypes(blocks []entity.Block) []string {
+	types := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		types = append(types, block.Type)
+	}
+	return types
+}
+
+func TestBlocks(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	content := json.RawMessage(`{"text":"hello"}`)
+	first := 0
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	text, err := notesService.InsertBlock(userID, "1", entity.BlockRequest{Type: entity.BlockText, Content: content})
+	require.Equal(t, nil, err)
+	code, err := notesService.InsertBlock(userID, "1", entity.BlockRequest{Type: entity.BlockCode, Content: content})
+	require.Equal(t, nil, err)
+	heading, err := notesService.InsertBlock(userID, "1", entity.BlockRequest{Type: entity.BlockHeading, Position: &first, Content: content})
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, heading.Position)
+
+	note, err := notesService.GetNote(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, []string{entity.BlockHeading, entity.BlockText, entity.BlockCode}, blockTypes(note.Blocks))
+
+	require.Equal(t, nil, notesService.MoveBlock(userID, "1", heading.ID, 10))
+	require.Equal(t, nil, notesService.UpdateBlock(userID, "1", text.ID, entity.BlockRequest{Type: entity.BlockTodo, Content: content}))
+	require.Equal(t, nil, notesService.DeleteBlock(userID, "1", code.ID))
+
+	note, err = notesService.GetNote(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, []string{entity.BlockTodo, entity.BlockHeading}, blockTypes(note.Blocks))
+	require.Equal(t, 1, note.Blocks[1].Position)
+
+	require.Equal(t, storage.ErrNoBlockInDB, notesService.DeleteBlock(userID, "1", code.ID))
+
+	_, err = notesService.InsertBlock(userID, "2", entity.BlockRequest{Type: entity.BlockText, Content: content})
+	require.Equal(t, ErrNoteAccess, err)
+}
@@ -0,0 +1,256 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/generator"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// copySuffix is added to the name of the duplicated note, the names of its
+// subpages are kept.
+const copySuffix = " (copy)"
+
+var ErrDuplicateOwner = errors.New("The note can only be copied to a member of it.")
+
+// DuplicateNote copies the note into a new one owned by ownerID, which is
+// either the user or another member of the note. Only the notes both the user
+// and the owner can read are copied, subpages that are not are skipped with
+// their own subpages. The copy is put next to the note if the owner can edit
+// its parent, at the top level otherwise. If a note cannot be copied, the
+// copies made so far are deleted again.
+func (n *NotesApp) DuplicateNote(userID string, noteToken string, ownerID string, duplicateRequest entity.DuplicateRequest) (entity.DuplicatedNote, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "DuplicateNote",
+		"noteToken": noteToken,
+	})
+
+	if !n.usersNotesRepository.CheckLink(userID, noteToken) {
+		logger.Warning(ErrNoteAccess)
+		return entity.DuplicatedNote{}, ErrNoteAccess
+	}
+	if !n.usersNotesRepository.CheckLink(ownerID, noteToken) {
+		logger.Warning(ErrDuplicateOwner)
+		return entity.DuplicatedNote{}, ErrDuplicateOwner
+	}
+
+	note, err := n.notesRepository.Find(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.DuplicatedNote{}, err
+	}
+	if note.DeletedAt != nil {
+		logger.Warning(ErrNoteInTrash)
+		return entity.DuplicatedNote{}, ErrNoteInTrash
+	}
+
+	tokens := []string{noteToken}
+	if duplicateRequest.Subtree {
+		if tokens, err = n.notesRepository.Subtree(noteToken); err != nil {
+			logger.Error(err)
+			return entity.DuplicatedNote{}, err
+		}
+	}
+
+	parent := note.Parent
+	if parent != "" && n.checkRole(ownerID, parent, entity.RoleEditor) != nil {
+		parent = ""
+	}
+	note.Name = truncateName(note.Name, entity.MaxNameLength-len(copySuffix)) + copySuffix
+
+	// The subtree lists every note after its parent, so the parents of the
+	// copies are known by the time they are saved.
+	copies := map[string]string{note.Parent: parent}
+	var copied []string
+	for _, token := range tokens {
+		if token != noteToken {
+			if note, err = n.notesRepository.Find(token); err != nil {
+				logger.Error(err)
+				n.removeCopies(ownerID, copied)
+				return entity.DuplicatedNote{}, err
+			}
+		}
+		copyParent, ok := copies[note.Parent]
+		if !ok || note.DeletedAt != nil ||
+			!n.usersNotesRepository.CheckLink(userID, token) || !n.usersNotesRepository.CheckLink(ownerID, token) {
+			continue
+		}
+
+		copies[token], err = n.copyNote(userID, ownerID, token, copyParent, note, duplicateRequest.Images)
+		if err != nil {
+			logger.Error(err)
+			n.removeCopies(ownerID, copied)
+			return entity.DuplicatedNote{}, err
+		}
+		copied = append(copied, copies[token])
+	}
+
+	return entity.DuplicatedNote{Token: copies[noteToken]}, nil
+}
+
+// removeCopies deletes the copies of a duplication that has failed. The
+// first one is the copy of the note, the others are in its subtree.
+func (n *NotesApp) removeCopies(ownerID string, tokens []string) {
+	if len(tokens) == 0 {
+		return
+	}
+	for _, token := range tokens {
+		n.publish(entity.NoteDeleted, ownerID, token)
+	}
+	if err := n.deleteForever(tokens[0]); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "removeCopies",
+			"noteToken": tokens[0],
+		}).Error(err)
+	}
+}
+
+// copyNote saves a copy of the note with its blocks and returns its token.
+// The copy gets its own copies of the uploaded icon and cover and, with the
+// images, of the attachments, so that they outlive the note. A copy that
+// cannot be finished is deleted.
+func (n *NotesApp) copyNote(userID string, ownerID string, noteToken string, parent string, note entity.Note, images bool) (string, error) {
+	blocks, err := n.blocksRepository.AllByNote(noteToken)
+	if err != nil {
+		return "", err
+	}
+
+	newToken := generator.RandToken()
+	now := time.Now()
+	newNote := entity.Note{
+		Name:         note.Name,
+		Body:         note.Body,
+		Parent:       parent,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		CreatedBy:    userID,
+		LastEditedBy: userID,
+	}
+
+	if err := n.notesRepository.Save(newToken, newNote); err != nil {
+		return "", err
+	}
+	if err := n.fillCopy(userID, ownerID, noteToken, newToken, note, blocks, images); err != nil {
+		if deleteErr := n.deleteForever(newToken); deleteErr != nil {
+			log.WithFields(log.Fields{
+				"package":   packageName,
+				"function":  "copyNote",
+				"noteToken": newToken,
+			}).Error(deleteErr)
+		}
+		return "", err
+	}
+
+	// Mentions are not notified again, the copy only gets the links.
+	if err := n.updateNoteLinks(ownerID, newToken, newNote.Body); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "copyNote",
+			"noteToken": newToken,
+		}).Warning(err)
+	}
+	n.publish(entity.NoteCreated, ownerID, newToken)
+	n.saveRevision(userID, newToken, newNote)
+	return newToken, nil
+}
+
+// fillCopy gives the saved copy its owner, blocks, images and attachments.
+func (n *NotesApp) fillCopy(userID string, ownerID string, noteToken string, newToken string, note entity.Note, blocks []entity.Block, images bool) error {
+	if err := n.usersNotesRepository.AddLink(ownerID, newToken, entity.RoleOwner); err != nil {
+		return err
+	}
+
+	position := 0
+	for _, block := range blocks {
+		if block.Type == entity.BlockImage && !images {
+			continue
+		}
+		block.ID = generator.RandSID(blockIDLength)
+		block.Position = position
+		if err := n.blocksRepository.Insert(newToken, block); err != nil {
+			return err
+		}
+		position++
+	}
+
+	if err := n.copyImages(newToken, note); err != nil {
+		return err
+	}
+	if images {
+		return n.copyAttachments(userID, noteToken, newToken)
+	}
+	return nil
+}
+
+// copyImages gives the copy the icon and the cover of the note. An image
+// that cannot be copied is left out.
+func (n *NotesApp) copyImages(newToken string, note entity.Note) error {
+	if note.Icon != nil {
+		icon := *note.Icon
+		if icon.Type == entity.IconImage {
+			icon.Value = n.copyImage(icon.Value)
+		}
+		if icon.Value != "" {
+			if err := n.notesRepository.SetIcon(newToken, &icon); err != nil {
+				n.deleteImage(iconImage(&icon))
+				return err
+			}
+		}
+	}
+
+	if note.Cover != "" {
+		if cover := n.copyImage(note.Cover); cover != "" {
+			if err := n.notesRepository.SetCover(newToken, cover); err != nil {
+				n.deleteImage(cover)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (n *NotesApp) copyImage(imageName string) string {
+	copyName, err := n.imageRepository.CopyFile(imageName)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "copyImage",
+		}).Warning(err)
+		return ""
+	}
+	return copyName
+}
+
+// copyAttachments attaches copies of the files of the note to the copy. A
+// file that cannot be copied is left out.
+func (n *NotesApp) copyAttachments(userID string, noteToken string, newToken string) error {
+	attachments, err := n.attachmentsRepository.AllByNote(noteToken)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		objectName, err := n.fileRepository.CopyFile(attachment.ObjectName)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"package":      packageName,
+				"function":     "copyAttachments",
+				"attachmentID": attachment.ID,
+			}).Warning(err)
+			continue
+		}
+
+		attachment.NoteToken = newToken
+		attachment.ObjectName = objectName
+		attachment.UploadedBy = userID
+		attachment.CreatedAt = time.Now()
+		if _, err := n.attachmentsRepository.Save(attachment); err != nil {
+			n.deleteAttachmentFile(attachment)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/contains"
+	log "github.com/sirupsen/logrus"
+)
+
+// eventsBuffer is how many events a subscriber may fall behind, newer
+// events are dropped for it after that.
+const eventsBuffer = 64
+
+// NotesEvents returns the note events addressed to the user. The returned
+// function stops the subscription and closes the channel.
+func (n *NotesApp) NotesEvents(userID string) (<-chan entity.NoteEvent, func()) {
+	events := make(chan entity.NoteEvent, eventsBuffer)
+	unsubscribe := n.eventBus.Subscribe(func(event entity.NoteEvent) {
+		if !contains.Contains(event.UserIDs, userID) {
+			return
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	})
+
+	return events, func() {
+		unsubscribe()
+		close(events)
+	}
+}
+
+// publish sends the event about the note to all of its members.
+func (n *NotesApp) publish(eventType string, userID string, noteToken string) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "publish",
+		"noteToken": noteToken,
+	})
+
+	note, err := n.notesRepository.Find(noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	members, err := n.usersNotesRepository.Members(noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+
+	event := entity.NoteEvent{
+		Type:      eventType,
+		NoteToken: noteToken,
+		Name:      note.Name,
+		Parent:    note.Parent,
+		UserID:    userID,
+	}
+	for _, member := range members {
+		event.UserIDs = append(event.UserIDs, member.UserID)
+	}
+	n.eventBus.Publish(event)
+}
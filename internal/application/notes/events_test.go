@@ -0,0 +1,51 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestNotesEvents(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	userEvents, stopUser := notesService.NotesEvents(userID)
+	otherEvents, stopOther := notesService.NotesEvents(otherID)
+
+	require.Equal(t, nil, notesService.SaveNote(userID, entity.NoteRequest{Name: "4th note", Parent: "1"}))
+	created := <-userEvents
+	require.Equal(t, entity.NoteCreated, created.Type)
+	require.Equal(t, "4th note", created.Name)
+	require.Equal(t, "1", created.Parent)
+
+	_, err := notesService.UpdateNote(userID, "1", entity.NoteRequest{Name: "Renamed"}, 0)
+	require.Equal(t, nil, err)
+	updated := <-userEvents
+	require.Equal(t, entity.NoteEvent{
+		Type:      entity.NoteUpdated,
+		NoteToken: "1",
+		Name:      "Renamed",
+		UserID:    userID,
+		UserIDs:   []string{userID},
+	}, updated)
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "3"))
+	require.Equal(t, entity.NoteDeleted, (<-userEvents).Type)
+
+	stopOther()
+	_, ok := <-otherEvents
+	require.Equal(t, false, ok)
+
+	stopUser()
+	_, ok = <-userEvents
+	require.Equal(t, false, ok)
+	log.Println("SUCCESS")
+}
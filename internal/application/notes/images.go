@@ -0,0 +1,249 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"github.com/minio/minio-go/v7"
+	log "github.com/sirupsen/logrus"
+	"mime/multipart"
+	"strings"
+)
+
+var ErrNotImage = errors.New("The file is not an image.")
+var ErrNoNoteIcon = errors.New("The note has no uploaded icon.")
+var ErrNoNoteCover = errors.New("The note has no cover.")
+
+// SetEmojiIcon makes the emoji the icon of the note.
+func (n *NotesApp) SetEmojiIcon(userID string, noteToken string, iconRequest entity.IconRequest) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "SetEmojiIcon",
+		"noteToken": noteToken,
+	})
+
+	note, err := n.editableNote(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.notesRepository.SetIcon(noteToken, &entity.NoteIcon{Type: entity.IconEmoji, Value: iconRequest.Emoji}); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	n.deleteImage(iconImage(note.Icon))
+	n.publish(entity.NoteUpdated, userID, noteToken)
+	return nil
+}
+
+// UploadIcon makes the uploaded image the icon of the note.
+func (n *NotesApp) UploadIcon(userID string, noteToken string, src multipart.File, hdr *multipart.FileHeader) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "UploadIcon",
+		"noteToken": noteToken,
+	})
+	defer src.Close()
+
+	note, err := n.editableNote(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	imageName, err := n.uploadImage(src, hdr)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.notesRepository.SetIcon(noteToken, &entity.NoteIcon{Type: entity.IconImage, Value: imageName}); err != nil {
+		logger.Error(err)
+		n.deleteImage(imageName)
+		return err
+	}
+
+	n.deleteImage(iconImage(note.Icon))
+	n.publish(entity.NoteUpdated, userID, noteToken)
+	return nil
+}
+
+func (n *NotesApp) DownloadIcon(userID string, noteToken string) (*minio.Object, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "DownloadIcon",
+		"noteToken": noteToken,
+	})
+
+	note, err := n.readableNote(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return nil, err
+	}
+
+	imageName := iconImage(note.Icon)
+	if imageName == "" {
+		logger.Debug(ErrNoNoteIcon)
+		return nil, ErrNoNoteIcon
+	}
+	return n.downloadImage(imageName)
+}
+
+// DeleteIcon removes the icon of the note, whether emoji or uploaded.
+func (n *NotesApp) DeleteIcon(userID string, noteToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "DeleteIcon",
+		"noteToken": noteToken,
+	})
+
+	note, err := n.editableNote(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.notesRepository.SetIcon(noteToken, nil); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	n.deleteImage(iconImage(note.Icon))
+	n.publish(entity.NoteUpdated, userID, noteToken)
+	return nil
+}
+
+func (n *NotesApp) UploadCover(userID string, noteToken string, src multipart.File, hdr *multipart.FileHeader) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "UploadCover",
+		"noteToken": noteToken,
+	})
+	defer src.Close()
+
+	note, err := n.editableNote(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	imageName, err := n.uploadImage(src, hdr)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.notesRepository.SetCover(noteToken, imageName); err != nil {
+		logger.Error(err)
+		n.deleteImage(imageName)
+		return err
+	}
+
+	n.deleteImage(note.Cover)
+	return nil
+}
+
+func (n *NotesApp) DownloadCover(userID string, noteToken string) (*minio.Object, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "DownloadCover",
+		"noteToken": noteToken,
+	})
+
+	note, err := n.readableNote(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return nil, err
+	}
+
+	if note.Cover == "" {
+		logger.Debug(ErrNoNoteCover)
+		return nil, ErrNoNoteCover
+	}
+	return n.downloadImage(note.Cover)
+}
+
+func (n *NotesApp) DeleteCover(userID string, noteToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "DeleteCover",
+		"noteToken": noteToken,
+	})
+
+	note, err := n.editableNote(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.notesRepository.SetCover(noteToken, ""); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	n.deleteImage(note.Cover)
+	return nil
+}
+
+// editableNote returns the note if the user may edit it.
+func (n *NotesApp) editableNote(userID string, noteToken string) (entity.Note, error) {
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		return entity.Note{}, err
+	}
+	return n.notesRepository.Find(noteToken)
+}
+
+// readableNote returns the note if the user may read it.
+func (n *NotesApp) readableNote(userID string, noteToken string) (entity.Note, error) {
+	if err := n.checkRole(userID, noteToken, entity.RoleViewer); err != nil {
+		return entity.Note{}, err
+	}
+	return n.notesRepository.Find(noteToken)
+}
+
+func (n *NotesApp) uploadImage(src multipart.File, hdr *multipart.FileHeader) (string, error) {
+	if !strings.HasPrefix(hdr.Header.Get("Content-Type"), "image/") {
+		return "", ErrNotImage
+	}
+
+	return n.imageRepository.UploadFile(entity.ImageUnit{
+		Payload:     src,
+		PayloadSize: hdr.Size,
+	})
+}
+
+func (n *NotesApp) downloadImage(imageName string) (*minio.Object, error) {
+	img, err := n.imageRepository.DownloadFile(imageName)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "downloadImage",
+		}).Error(err)
+		return nil, err
+	}
+	return img, nil
+}
+
+// deleteImage removes an image that is no longer used. A failure only leaves
+// an orphaned file, so it is logged and not returned.
+func (n *NotesApp) deleteImage(imageName string) {
+	if imageName == "" {
+		return
+	}
+	if err := n.imageRepository.DeleteFile(imageName); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "deleteImage",
+		}).Warning(err)
+	}
+}
+
+// iconImage returns the name of the uploaded image of the icon, or an empty
+// string for emoji icons and notes without one.
+func iconImage(icon *entity.NoteIcon) string {
+	if icon == nil || icon.Type != entity.IconImage {
+		return ""
+	}
+	return icon.Value
+}
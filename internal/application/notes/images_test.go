@@ -0,0 +1,236 @@
+package notes
+
+import (
+	"bytes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"errors"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/textproto"
+	"path"
+	"strconv"
+	"testing"
+)
+
+// filesStorage keeps the content types of the uploaded files by name.
+type filesStorage struct {
+	lastID  int
+	files   map[string]string
+	uploads map[string]*fileUpload
+}
+
+// fileUpload is a multipart upload with the sizes of its parts by number.
+type fileUpload struct {
+	objectName  string
+	contentType string
+	parts       map[int]int64
+}
+
+func newFilesStorage() *filesStorage {
+	return &filesStorage{files: map[string]string{}, uploads: map[string]*fileUpload{}}
+}
+
+func (store *filesStorage) UploadFile(image entity.ImageUnit) (string, error) {
+	store.lastID++
+	imageName := fmt.Sprintf("%d.png", store.lastID)
+	store.files[imageName] = "image/png"
+	return imageName, nil
+}
+
+func (store *filesStorage) UploadObject(file entity.FileUnit) (string, error) {
+	store.lastID++
+	objectName := strconv.Itoa(store.lastID)
+	store.files[objectName] = file.ContentType
+	return objectName, nil
+}
+
+func (store *filesStorage) DownloadFile(fileID string) (*minio.Object, error) {
+	if _, ok := store.files[fileID]; !ok {
+		return nil, errors.New("no file")
+	}
+	return nil, nil
+}
+
+func (store *filesStorage) DeleteFile(fileID string) error {
+	delete(store.files, fileID)
+	return nil
+}
+
+func (store *filesStorage) CopyFile(fileID string) (string, error) {
+	contentType, ok := store.files[fileID]
+	if !ok {
+		return "", errors.New("no file")
+	}
+	store.lastID++
+	copyName := strconv.Itoa(store.lastID) + path.Ext(fileID)
+	store.files[copyName] = contentType
+	return copyName, nil
+}
+
+func (store *filesStorage) CreateUpload(contentType string) (string, string, error) {
+	store.lastID++
+	objectName := strconv.Itoa(store.lastID)
+	uploadID := "upload-" + objectName
+	store.uploads[uploadID] = &fileUpload{objectName: objectName, contentType: contentType, parts: map[int]int64{}}
+	return objectName, uploadID, nil
+}
+
+func (store *filesStorage) UploadPart(fileID string, uploadID string, partNumber int, part io.Reader, size int64) (string, error) {
+	upload, ok := store.uploads[uploadID]
+	if !ok || upload.objectName != fileID {
+		return "", errors.New("no upload")
+	}
+	written, err := io.Copy(io.Discard, part)
+	if err != nil {
+		return "", err
+	}
+	if written != size {
+		return "", errors.New("wrong part size")
+	}
+	upload.parts[partNumber] = size
+	return fmt.Sprintf("etag-%d", partNumber), nil
+}
+
+func (store *filesStorage) CompleteUpload(fileID string, uploadID string, parts []entity.UploadPart) error {
+	upload, ok := store.uploads[uploadID]
+	if !ok || upload.objectName != fileID || len(parts) != len(upload.parts) {
+		return errors.New("no upload")
+	}
+	for _, part := range parts {
+		if part.ETag != fmt.Sprintf("etag-%d", part.Number) {
+			return errors.New("wrong part")
+		}
+	}
+	delete(store.uploads, uploadID)
+	store.files[fileID] = upload.contentType
+	return nil
+}
+
+func (store *filesStorage) AbortUpload(fileID string, uploadID string) error {
+	delete(store.uploads, uploadID)
+	return nil
+}
+
+func formFile(t *testing.T, fileName string, contentType string, content []byte) (multipart.File, *multipart.FileHeader) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, fileName))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	require.Equal(t, nil, err)
+	_, err = part.Write(content)
+	require.Equal(t, nil, err)
+	require.Equal(t, nil, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(1 << 20)
+	require.Equal(t, nil, err)
+	hdr := form.File["file"][0]
+	src, err := hdr.Open()
+	require.Equal(t, nil, err)
+	return src, hdr
+}
+
+func imageFile(t *testing.T, contentType string) (multipart.File, *multipart.FileHeader) {
+	return formFile(t, "icon.png", contentType, []byte("image"))
+}
+
+func TestIcons(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	images := newFilesStorage()
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Images = images
+	notesService := NewNotesApp(deps)
+
+	require.Equal(t, ErrNoteAccess, notesService.SetEmojiIcon(userID, "2", entity.IconRequest{Emoji: "🔥"}))
+	require.Equal(t, nil, notesService.SetEmojiIcon(userID, "1", entity.IconRequest{Emoji: "🔥"}))
+	_, err := notesService.DownloadIcon(userID, "1")
+	require.Equal(t, ErrNoNoteIcon, err)
+
+	notes, err := notesService.AllNotesByUserID(userID, entity.NotesFilter{Tokens: []string{"1"}})
+	require.Equal(t, nil, err)
+	require.Equal(t, &entity.NoteIcon{Type: entity.IconEmoji, Value: "🔥"}, notes.ShortNote[0].Icon)
+
+	src, hdr := imageFile(t, "text/plain")
+	require.Equal(t, ErrNotImage, notesService.UploadIcon(userID, "1", src, hdr))
+	src, hdr = imageFile(t, "image/png")
+	require.Equal(t, nil, notesService.UploadIcon(userID, "1", src, hdr))
+	_, err = notesService.DownloadIcon(userID, "1")
+	require.Equal(t, nil, err)
+
+	src, hdr = imageFile(t, "image/png")
+	require.Equal(t, nil, notesService.UploadIcon(userID, "1", src, hdr))
+	require.Equal(t, map[string]string{"2.png": "image/png"}, images.files)
+
+	require.Equal(t, nil, notesService.DeleteIcon(userID, "1"))
+	require.Equal(t, map[string]string{}, images.files)
+	note, err := notesService.GetNote(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, (*entity.NoteIcon)(nil), note.Icon)
+	log.Println("SUCCESS")
+}
+
+func TestCovers(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	images := newFilesStorage()
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Images = images
+	notesService := NewNotesApp(deps)
+
+	_, err := notesService.DownloadCover(userID, "1")
+	require.Equal(t, ErrNoNoteCover, err)
+
+	src, hdr := imageFile(t, "image/jpeg")
+	require.Equal(t, nil, notesService.UploadCover(userID, "1", src, hdr))
+	_, err = notesService.DownloadCover(userID, "1")
+	require.Equal(t, nil, err)
+	_, err = notesService.DownloadCover(userID, "2")
+	require.Equal(t, ErrNoteAccess, err)
+
+	require.Equal(t, nil, notesService.MoveNote(userID, "3", "1"))
+	src, hdr = imageFile(t, "image/jpeg")
+	require.Equal(t, nil, notesService.UploadIcon(userID, "3", src, hdr))
+	require.Equal(t, 2, len(images.files))
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, nil, notesService.DeleteNoteForever(userID, "1"))
+	require.Equal(t, map[string]string{}, images.files)
+	log.Println("SUCCESS")
+}
+
+func TestIconRequest(t *testing.T) {
+	cases := map[string]struct {
+		inEmoji  string
+		expected error
+	}{
+		"Emoji":          {inEmoji: "🔥", expected: nil},
+		"Joined emoji":   {inEmoji: "👩‍💻", expected: nil},
+		"Keycap":         {inEmoji: "1️⃣", expected: nil},
+		"Empty":          {inEmoji: "", expected: entity.ErrInvalidEmoji},
+		"Letters":        {inEmoji: "ab", expected: entity.ErrInvalidEmoji},
+		"Markup":         {inEmoji: "<b>", expected: entity.ErrInvalidEmoji},
+		"Too many":       {inEmoji: "🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥", expected: entity.ErrInvalidEmoji},
+		"Non-ASCII text": {inEmoji: "ж", expected: entity.ErrInvalidEmoji},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			iconRequest := entity.IconRequest{Emoji: tc.inEmoji}
+			require.Equal(t, tc.expected, iconRequest.Validate())
+		})
+		log.Println("SUCCESS")
+	}
+}
@@ -0,0 +1,156 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/contains"
+	"cotion/internal/pkg/markdown"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrUnknownExportFormat = errors.New("The export format is not supported.")
+var ErrNoImportFiles = errors.New("There are no files to import.")
+var ErrNotMarkdown = errors.New("Only Markdown files can be imported.")
+
+// ExportNote renders the note to a file in the format.
+func (n *NotesApp) ExportNote(userID string, noteToken string, format string) (entity.ExportedNote, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ExportNote",
+	})
+
+	if format != entity.ExportMarkdown {
+		logger.Warning(ErrUnknownExportFormat)
+		return entity.ExportedNote{}, ErrUnknownExportFormat
+	}
+
+	note, err := n.GetNote(userID, noteToken)
+	if err != nil {
+		return entity.ExportedNote{}, err
+	}
+
+	return entity.ExportedNote{
+		FileName:    exportFileName(note.Name) + ".md",
+		ContentType: entity.MarkdownContentType,
+		Data:        []byte(markdown.Render(note)),
+	}, nil
+}
+
+// ImportNotes creates a note under the parent from every Markdown file. A
+// document without a title is named after its file. All files are read
+// before the first note is created and the created notes are deleted again
+// if a later one cannot be saved, so a failed import imports nothing.
+func (n *NotesApp) ImportNotes(userID string, parentToken string, files []*multipart.FileHeader) (entity.ImportedNotes, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ImportNotes",
+	})
+
+	if len(files) == 0 {
+		logger.Warning(ErrNoImportFiles)
+		return entity.ImportedNotes{}, ErrNoImportFiles
+	}
+
+	var requests []entity.NoteRequest
+	for _, file := range files {
+		noteRequest, err := readMarkdown(file)
+		if err != nil {
+			logger.Warning(err)
+			return entity.ImportedNotes{}, err
+		}
+		noteRequest.Parent = parentToken
+		if err := noteRequest.Validate(); err != nil {
+			logger.Warning(err)
+			return entity.ImportedNotes{}, err
+		}
+		requests = append(requests, noteRequest)
+	}
+
+	imported := entity.ImportedNotes{Names: []string{}}
+	var tokens []string
+	for _, noteRequest := range requests {
+		token, err := n.createNote(userID, noteRequest)
+		if err != nil {
+			logger.Error(err)
+			n.removeImported(userID, tokens)
+			return entity.ImportedNotes{}, err
+		}
+		tokens = append(tokens, token)
+		imported.Names = append(imported.Names, noteRequest.Name)
+	}
+	return imported, nil
+}
+
+// removeImported deletes the notes of an import that has failed.
+func (n *NotesApp) removeImported(userID string, tokens []string) {
+	for _, token := range tokens {
+		n.publish(entity.NoteDeleted, userID, token)
+		if err := n.deleteForever(token); err != nil {
+			log.WithFields(log.Fields{
+				"package":   packageName,
+				"function":  "removeImported",
+				"noteToken": token,
+			}).Error(err)
+		}
+	}
+}
+
+func readMarkdown(file *multipart.FileHeader) (entity.NoteRequest, error) {
+	extension := strings.ToLower(filepath.Ext(file.Filename))
+	if !contains.Contains(entity.MarkdownExtensions, extension) {
+		return entity.NoteRequest{}, ErrNotMarkdown
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return entity.NoteRequest{}, err
+	}
+	defer src.Close()
+
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return entity.NoteRequest{}, err
+	}
+	if !utf8.Valid(data) {
+		return entity.NoteRequest{}, ErrNotMarkdown
+	}
+
+	name, body := markdown.Parse(string(data))
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+	}
+	return entity.NoteRequest{Name: truncateName(name, entity.MaxNameLength), Body: body}, nil
+}
+
+// truncateName cuts the name to the given length without splitting a
+// character.
+func truncateName(name string, length int) string {
+	if len(name) <= length {
+		return name
+	}
+	name = name[:length]
+	for !utf8.ValidString(name) {
+		name = name[:len(name)-1]
+	}
+	return strings.TrimSpace(name)
+}
+
+// exportFileName replaces the characters that file systems do not allow.
+func exportFileName(name string) string {
+	fileName := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if fileName == "" || strings.Trim(fileName, ".") == "" {
+		return "note"
+	}
+	return fileName
+}
@@ -0,0 +1,119 @@
+package notes
+
+import (
+	"bytes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"log"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func markdownFiles(t *testing.T, files map[string]string) []*multipart.FileHeader {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		require.Equal(t, nil, err)
+		_, err = part.Write([]byte(content))
+		require.Equal(t, nil, err)
+	}
+	require.Equal(t, nil, writer.Close())
+
+	form, err := multipart.NewReader(&buf, writer.Boundary()).ReadForm(1 << 20)
+	require.Equal(t, nil, err)
+	return form.File["files"]
+}
+
+// failingNotes fails to save notes after the given number of them.
+type failingNotes struct {
+	*storage.NotesStorage
+	saves int
+}
+
+func (store *failingNotes) Save(token string, note entity.Note) error {
+	if store.saves == 0 {
+		return errors.New("no connection")
+	}
+	store.saves--
+	return store.NotesStorage.Save(token, note)
+}
+
+func TestMarkdown(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	exported, err := notesService.ExportNote(userID, "1", entity.ExportMarkdown)
+	require.Equal(t, nil, err)
+	require.Equal(t, "1st note.md", exported.FileName)
+	require.Equal(t, "# 1st note\n\nHello everybody. This is Body of the 1st note)\n", string(exported.Data))
+	_, err = notesService.ExportNote(userID, "1", "pdf")
+	require.Equal(t, ErrUnknownExportFormat, err)
+	_, err = notesService.ExportNote(userID, "2", entity.ExportMarkdown)
+	require.Equal(t, ErrNoteAccess, err)
+
+	_, err = notesService.ImportNotes(userID, "", markdownFiles(t, map[string]string{"a.md": "# A", "b.txt": "B"}))
+	require.Equal(t, ErrNotMarkdown, err)
+	_, err = notesService.ImportNotes(userID, "2", markdownFiles(t, map[string]string{"a.md": "# A"}))
+	require.Equal(t, ErrNoteAccess, err)
+	notes, err := notesService.AllNotesByUserID(userID, entity.NotesFilter{})
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(notes.ShortNote))
+
+	imported, err := notesService.ImportNotes(userID, "1", markdownFiles(t, map[string]string{
+		"roadmap.md": "---\ntitle: Plans\n---\nBody",
+	}))
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.ImportedNotes{Names: []string{"Plans"}}, imported)
+	imported, err = notesService.ImportNotes(userID, "1", markdownFiles(t, map[string]string{
+		"Meeting notes.markdown": "Just text",
+	}))
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.ImportedNotes{Names: []string{"Meeting notes"}}, imported)
+
+	notes, err = notesService.AllNotesByUserID(userID, entity.NotesFilter{Sort: entity.SortName})
+	require.Equal(t, nil, err)
+	require.Equal(t, 4, len(notes.ShortNote))
+	require.Equal(t, "Meeting notes", notes.ShortNote[2].Name)
+	require.Equal(t, "Just text", notes.ShortNote[2].Body)
+	require.Equal(t, "1", notes.ShortNote[2].Parent)
+	require.Equal(t, "Plans", notes.ShortNote[3].Name)
+	require.Equal(t, "Body", notes.ShortNote[3].Body)
+
+	imported, err = notesService.ImportNotes(userID, "1", markdownFiles(t, map[string]string{
+		"long.md": "# Long\n\n" + strings.Repeat("Some text of a real document.\n", 1000),
+	}))
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.ImportedNotes{Names: []string{"Long"}}, imported)
+	log.Println("SUCCESS")
+}
+
+func TestImportNotesRollback(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	failing := &failingNotes{NotesStorage: notesStorage, saves: 1}
+	usersNotesStorage := storage.NewUsersNotesStorage(failing)
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Notes = failing
+	notesService := NewNotesApp(deps)
+
+	imported, err := notesService.ImportNotes(userID, "1", markdownFiles(t, map[string]string{
+		"a.md": "# A",
+		"b.md": "# B",
+	}))
+	require.NotEqual(t, nil, err)
+	require.Equal(t, entity.ImportedNotes{}, imported)
+
+	notes, err := notesService.AllNotesByUserID(userID, entity.NotesFilter{})
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(notes.ShortNote))
+	log.Println("SUCCESS")
+}
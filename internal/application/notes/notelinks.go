@@ -0,0 +1,166 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/contains"
+	"cotion/internal/pkg/wikilink"
+	log "github.com/sirupsen/logrus"
+)
+
+// SyncNote updates what is derived from the text of a saved note: the
+// mentions in it, the notes it links to and, if it was renamed from
+// oldName, the links to it in other notes. The note is already saved, so
+// errors are only logged.
+func (n *NotesApp) SyncNote(userID string, noteToken string, oldName string, note entity.Note) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "SyncNote",
+		"noteToken": noteToken,
+	})
+
+	if err := n.notificationsApp.Mention(userID, noteToken, 0, note.Body); err != nil {
+		logger.Warning(err)
+	}
+	if err := n.updateNoteLinks(userID, noteToken, note.Body); err != nil {
+		logger.Warning(err)
+	}
+	if oldName != note.Name {
+		n.renameNoteLinks(noteToken, oldName, note.Name)
+	}
+}
+
+func (n *NotesApp) Backlinks(userID string, noteToken string) (entity.Backlinks, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Backlinks",
+	})
+
+	if !n.usersNotesRepository.CheckLink(userID, noteToken) {
+		logger.Warning(ErrNoteAccess)
+		return entity.Backlinks{}, ErrNoteAccess
+	}
+
+	tokens, err := n.noteLinksRepository.Backlinks(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Backlinks{}, err
+	}
+
+	// Notes the user cannot open are left out, their names are private.
+	backlinks := []entity.Backlink{}
+	for _, token := range tokens {
+		if !n.usersNotesRepository.CheckLink(userID, token) {
+			continue
+		}
+		note, err := n.notesRepository.Find(token)
+		if err != nil {
+			logger.Warning(err)
+			continue
+		}
+		backlinks = append(backlinks, entity.Backlink{Token: token, Name: note.Name})
+	}
+
+	return entity.Backlinks{Backlinks: backlinks}, nil
+}
+
+// NotesGraph returns the notes the user can open as nodes and the links
+// between them as edges.
+func (n *NotesApp) NotesGraph(userID string) (entity.NotesGraph, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "NotesGraph",
+	})
+
+	notes, err := n.usersNotesRepository.AllNotesByUserID(userID, entity.NotesFilter{Body: entity.BodyNone})
+	if err != nil {
+		logger.Error(err)
+		return entity.NotesGraph{}, err
+	}
+
+	graph := entity.NotesGraph{Nodes: []entity.GraphNode{}, Edges: []entity.NoteLink{}}
+	var tokens []string
+	for _, note := range notes.ShortNote {
+		graph.Nodes = append(graph.Nodes, entity.GraphNode{Token: note.Token, Name: note.Name, Parent: note.Parent})
+		tokens = append(tokens, note.Token)
+	}
+	if len(tokens) == 0 {
+		return graph, nil
+	}
+
+	links, err := n.noteLinksRepository.AllByNotes(tokens)
+	if err != nil {
+		logger.Error(err)
+		return entity.NotesGraph{}, err
+	}
+	graph.Edges = append(graph.Edges, links...)
+
+	return graph, nil
+}
+
+// updateNoteLinks stores the notes the body links to. A link is the token or
+// the name of a note the user can open, a name shared by several notes
+// means the first of them in the user's list.
+func (n *NotesApp) updateNoteLinks(userID string, noteToken string, body string) error {
+	targets := wikilink.Parse(body)
+
+	var toTokens []string
+	if len(targets) != 0 {
+		notes, err := n.usersNotesRepository.AllNotesByUserID(userID, entity.NotesFilter{Body: entity.BodyNone})
+		if err != nil {
+			return err
+		}
+		isToken := map[string]bool{}
+		byName := map[string]string{}
+		for _, note := range notes.ShortNote {
+			isToken[note.Token] = true
+			if _, ok := byName[note.Name]; !ok {
+				byName[note.Name] = note.Token
+			}
+		}
+
+		for _, target := range targets {
+			token, ok := target, isToken[target]
+			if !ok {
+				token, ok = byName[target]
+			}
+			if ok && token != noteToken && !contains.Contains(toTokens, token) {
+				toTokens = append(toTokens, token)
+			}
+		}
+	}
+
+	return n.noteLinksRepository.Replace(noteToken, toTokens)
+}
+
+// renameNoteLinks points the links to the old name of the note to the new
+// one, so that they keep leading to it. Only the links change, the notes
+// keep their last edit.
+func (n *NotesApp) renameNoteLinks(noteToken string, oldName string, newName string) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "renameNoteLinks",
+		"noteToken": noteToken,
+	})
+
+	tokens, err := n.noteLinksRepository.Backlinks(noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+
+	for _, token := range tokens {
+		note, err := n.notesRepository.Find(token)
+		if err != nil {
+			logger.Warning(err)
+			continue
+		}
+		body := wikilink.Rename(note.Body, oldName, newName)
+		if body == note.Body {
+			continue
+		}
+		note.Body = body
+		if _, err := n.notesRepository.Update(token, note); err != nil {
+			logger.Warning(err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestNoteLinks(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	// Note 2 belongs to another user, so the link to it is ignored.
+	_, err := notesService.UpdateNote(userID, "1", entity.NoteRequest{Name: "1st note", Body: "See [[3st note]], [[2]] and [[1st note]]."}, 0)
+	require.Equal(t, nil, err)
+
+	backlinks, err := notesService.Backlinks(userID, "3")
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.Backlinks{Backlinks: []entity.Backlink{{Token: "1", Name: "1st note"}}}, backlinks)
+	_, err = notesService.Backlinks(otherID, "3")
+	require.Equal(t, ErrNoteAccess, err)
+	backlinks, err = notesService.Backlinks(otherID, "2")
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, len(backlinks.Backlinks))
+
+	graph, err := notesService.NotesGraph(userID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(graph.Nodes))
+	require.Equal(t, []entity.NoteLink{{From: "1", To: "3"}}, graph.Edges)
+
+	_, err = notesService.UpdateNote(userID, "3", entity.NoteRequest{Name: "Plans", Body: "[[1]]"}, 0)
+	require.Equal(t, nil, err)
+	note, err := notesService.GetNote(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, "See [[Plans]], [[2]] and [[1st note]].", note.Body)
+
+	graph, err = notesService.NotesGraph(userID)
+	require.Equal(t, nil, err)
+	require.Equal(t, []entity.NoteLink{{From: "1", To: "3"}, {From: "3", To: "1"}}, graph.Edges)
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	backlinks, err = notesService.Backlinks(userID, "3")
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, len(backlinks.Backlinks))
+
+	require.Equal(t, nil, notesService.DeleteNoteForever(userID, "1"))
+	graph, err = notesService.NotesGraph(userID)
+	require.Equal(t, nil, err)
+	require.Equal(t, []entity.GraphNode{{Token: "3", Name: "Plans"}}, graph.Nodes)
+	require.Equal(t, []entity.NoteLink{}, graph.Edges)
+	log.Println("SUCCESS")
+}
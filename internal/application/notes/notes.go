@@ -1,56 +1,206 @@
 package notes
 
 import (
+	"cotion/internal/application/notifications"
 	"cotion/internal/domain/entity"
 	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/events"
 	"cotion/internal/pkg/generator"
 	"errors"
 	log "github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
 )
 
-const packageName = "app notes"
+const (
+	packageName   = "app notes"
+	blockIDLength = 16
+)
 
 var ErrNoteAccess = errors.New("The user does not have access to this note. Or the note does not exist.")
+var ErrNoteRole = errors.New("The user's role does not allow this action on the note.")
+var ErrMoveIntoSubtree = errors.New("The note cannot be moved into itself or its subpages.")
+var ErrEmptySearchQuery = errors.New("The search query is empty.")
+var ErrNoteInTrash = errors.New("The note is in the trash.")
+var ErrNoteNotInTrash = errors.New("The note is not in the trash.")
+var ErrNoteTag = errors.New("The tag does not belong to the user.")
 
 type NotesApp struct {
-	notesRepository      repository.NotesRepository
-	usersNotesRepository repository.UsersNotesRepository
+	notesRepository       repository.NotesRepository
+	usersNotesRepository  repository.UsersNotesRepository
+	blocksRepository      repository.BlocksRepository
+	revisionsRepository   repository.RevisionsRepository
+	tagsRepository        repository.TagsRepository
+	noteLinksRepository   repository.NoteLinksRepository
+	imageRepository       repository.ImageRepository
+	attachmentsRepository repository.AttachmentsRepository
+	fileRepository        repository.FileRepository
+	uploadsRepository     repository.UploadsRepository
+	notificationsApp      *notifications.NotificationsApp
+	eventBus              *events.Bus
+
+	// receiving holds the uploads a chunk is being stored for.
+	uploadsMu sync.Mutex
+	receiving map[string]struct{}
+}
+
+// Dependencies are the repositories and services NotesApp is built from.
+// The ones for images and files may be nil when those features are unused.
+type Dependencies struct {
+	Notes         repository.NotesRepository
+	UsersNotes    repository.UsersNotesRepository
+	Blocks        repository.BlocksRepository
+	Revisions     repository.RevisionsRepository
+	Tags          repository.TagsRepository
+	NoteLinks     repository.NoteLinksRepository
+	Images        repository.ImageRepository
+	Attachments   repository.AttachmentsRepository
+	Files         repository.FileRepository
+	Uploads       repository.UploadsRepository
+	Notifications *notifications.NotificationsApp
+	EventBus      *events.Bus
 }
 
-func NewNotesApp(notesRepo repository.NotesRepository, usersNotesRepository repository.UsersNotesRepository) *NotesApp {
+func NewNotesApp(deps Dependencies) *NotesApp {
 	return &NotesApp{
-		notesRepository:      notesRepo,
-		usersNotesRepository: usersNotesRepository,
+		notesRepository:       deps.Notes,
+		usersNotesRepository:  deps.UsersNotes,
+		blocksRepository:      deps.Blocks,
+		revisionsRepository:   deps.Revisions,
+		tagsRepository:        deps.Tags,
+		noteLinksRepository:   deps.NoteLinks,
+		imageRepository:       deps.Images,
+		attachmentsRepository: deps.Attachments,
+		fileRepository:        deps.Files,
+		uploadsRepository:     deps.Uploads,
+		notificationsApp:      deps.Notifications,
+		eventBus:              deps.EventBus,
+		receiving:             map[string]struct{}{},
 	}
 }
 
-func (n *NotesApp) AllNotesByUserID(userID string) (entity.ShortNotes, error) {
-	return n.usersNotesRepository.AllNotesByUserID(userID)
+func (n *NotesApp) AllNotesByUserID(userID string, filter entity.NotesFilter) (entity.ShortNotes, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AllNotesByUserID",
+	})
+
+	if len(filter.Tags) != 0 {
+		tokens, err := n.tagsRepository.NoteTokens(userID, filter.Tags, filter.Match == entity.TagsMatchAll)
+		if err != nil {
+			logger.Error(err)
+			return entity.ShortNotes{}, err
+		}
+		if len(tokens) == 0 {
+			return entity.ShortNotes{}, nil
+		}
+		filter.Tokens = tokens
+	}
+
+	notes, err := n.usersNotesRepository.AllNotesByUserID(userID, filter)
+	if err != nil {
+		return entity.ShortNotes{}, err
+	}
+
+	tags, err := n.tagsRepository.NotesTags(userID)
+	if err != nil {
+		logger.Error(err)
+		return entity.ShortNotes{}, err
+	}
+	for i := range notes.ShortNote {
+		notes.ShortNote[i].Tags = tags[notes.ShortNote[i].Token]
+	}
+
+	return notes, nil
+}
+
+func (n *NotesApp) NotesTree(userID string) (entity.NotesTree, error) {
+	notes, err := n.usersNotesRepository.AllNotesByUserID(userID, entity.NotesFilter{})
+	if err != nil {
+		return entity.NotesTree{}, err
+	}
+	return buildTree(notes.ShortNote), nil
+}
+
+func (n *NotesApp) SearchNotes(userID string, query string) (entity.FoundNotes, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "SearchNotes",
+	})
+
+	if strings.TrimSpace(query) == "" {
+		logger.Warning(ErrEmptySearchQuery)
+		return entity.FoundNotes{}, ErrEmptySearchQuery
+	}
+
+	notes, err := n.usersNotesRepository.SearchNotes(userID, query, entity.MaxSearchResults)
+	if err != nil {
+		logger.Error(err)
+		return entity.FoundNotes{}, err
+	}
+	return entity.FoundNotes{Notes: notes}, nil
 }
 
 func (n *NotesApp) SaveNote(userID string, noteRequest entity.NoteRequest) error {
+	_, err := n.createNote(userID, noteRequest)
+	return err
+}
+
+// createNote saves a new note of the user and returns its token.
+func (n *NotesApp) createNote(userID string, noteRequest entity.NoteRequest) (string, error) {
 	logger := log.WithFields(log.Fields{
 		"package":  packageName,
-		"function": "SaveNote",
+		"function": "createNote",
 	})
 
+	if noteRequest.Parent != "" {
+		if err := n.checkRole(userID, noteRequest.Parent, entity.RoleEditor); err != nil {
+			logger.Warning(err)
+			return "", err
+		}
+	}
+
+	for _, tagID := range noteRequest.Tags {
+		if tag, err := n.tagsRepository.Find(tagID); err != nil || tag.UserID != userID {
+			logger.Warning(ErrNoteTag)
+			return "", ErrNoteTag
+		}
+	}
+
 	newToken := generator.RandToken()
+	now := time.Now()
 	newNote := entity.Note{
-		Name: noteRequest.Name,
-		Body: noteRequest.Body,
+		Name:         noteRequest.Name,
+		Body:         noteRequest.Body,
+		Parent:       noteRequest.Parent,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		CreatedBy:    userID,
+		LastEditedBy: userID,
 	}
 
 	if err := n.notesRepository.Save(newToken, newNote); err != nil {
 		logger.Error(err)
-		return err
+		return "", err
 	}
 
-	if err := n.usersNotesRepository.AddLink(userID, newToken); err != nil {
+	if err := n.usersNotesRepository.AddLink(userID, newToken, entity.RoleOwner); err != nil {
 		logger.Error(err)
-		return err
+		return "", err
 	}
 
-	return nil
+	for _, tagID := range noteRequest.Tags {
+		if err := n.tagsRepository.Attach(tagID, newToken); err != nil {
+			logger.Error(err)
+			return "", err
+		}
+	}
+
+	n.SyncNote(userID, newToken, newNote.Name, newNote)
+	n.publish(entity.NoteCreated, userID, newToken)
+	n.saveRevision(userID, newToken, newNote)
+	return newToken, nil
 }
 
 func (n *NotesApp) GetNote(userID string, noteToken string) (entity.Note, error) {
@@ -59,9 +209,9 @@ func (n *NotesApp) GetNote(userID string, noteToken string) (entity.Note, error)
 		"function": "GetNote",
 	})
 
-	if !n.usersNotesRepository.CheckLink(userID, noteToken) {
-		logger.Warning(ErrNoteAccess)
-		return entity.Note{}, ErrNoteAccess
+	if err := n.checkRole(userID, noteToken, entity.RoleViewer); err != nil {
+		logger.Warning(err)
+		return entity.Note{}, err
 	}
 
 	note, err := n.notesRepository.Find(noteToken)
@@ -70,41 +220,219 @@ func (n *NotesApp) GetNote(userID string, noteToken string) (entity.Note, error)
 		return entity.Note{}, err
 	}
 
+	note.Blocks, err = n.blocksRepository.AllByNote(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Note{}, err
+	}
+
 	return note, nil
 }
 
-func (n *NotesApp) UpdateNote(userID string, noteToken string, noteRequest entity.NoteRequest) error {
-	if !n.usersNotesRepository.CheckLink(userID, noteToken) {
-		log.WithFields(log.Fields{
-			"package":  packageName,
-			"function": "UpdateNote",
-		}).Warning(ErrNoteAccess)
-		return ErrNoteAccess
+// UpdateNote saves the note if it has not been changed since the given
+// version, a zero version overwrites the note unconditionally. It returns
+// the new version of the note, or the current one with
+// entity.ErrNoteVersionConflict if the given version is stale.
+func (n *NotesApp) UpdateNote(userID string, noteToken string, noteRequest entity.NoteRequest, version int) (int, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateNote",
+	})
+
+	current, err := n.checkMember(userID, noteToken, entity.RoleEditor)
+	if err != nil {
+		logger.Warning(err)
+		return 0, err
+	}
+	if current.DeletedAt != nil {
+		logger.Warning(ErrNoteInTrash)
+		return 0, ErrNoteInTrash
 	}
 
 	updateNote := entity.Note{
-		Name: noteRequest.Name,
-		Body: noteRequest.Body,
+		Name:         noteRequest.Name,
+		Body:         noteRequest.Body,
+		Version:      version,
+		UpdatedAt:    time.Now(),
+		LastEditedBy: userID,
+	}
+
+	newVersion, err := n.notesRepository.Update(noteToken, updateNote)
+	if err == entity.ErrNoteVersionConflict {
+		logger.Warning(err)
+		latest, findErr := n.notesRepository.Find(noteToken)
+		if findErr != nil {
+			logger.Error(findErr)
+			return 0, findErr
+		}
+		return latest.Version, err
+	}
+	if err != nil {
+		logger.Error(err)
+		return 0, err
 	}
 
-	return n.notesRepository.Update(noteToken, updateNote)
+	n.SyncNote(userID, noteToken, current.Name, updateNote)
+	n.publish(entity.NoteUpdated, userID, noteToken)
+	n.saveRevision(userID, noteToken, updateNote)
+	return newVersion, nil
 }
 
+func (n *NotesApp) MoveNote(userID string, noteToken string, parentToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "MoveNote",
+	})
+
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		logger.Warning(err)
+		return err
+	}
+	if parentToken != "" {
+		if err := n.checkRole(userID, parentToken, entity.RoleEditor); err != nil {
+			logger.Warning(err)
+			return err
+		}
+	}
+
+	err := n.notesRepository.Move(noteToken, parentToken)
+	if err == entity.ErrNoteCycle {
+		logger.Warning(ErrMoveIntoSubtree)
+		return ErrMoveIntoSubtree
+	}
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	n.publish(entity.NoteUpdated, userID, noteToken)
+	return nil
+}
+
+// DeleteNote moves the note with all its subpages to the trash.
 func (n *NotesApp) DeleteNote(userID string, noteToken string) error {
 	logger := log.WithFields(log.Fields{
 		"package":  packageName,
 		"function": "DeleteNote",
 	})
 
-	if !n.usersNotesRepository.CheckLink(userID, noteToken) {
-		logger.Warning(ErrNoteAccess)
-		return ErrNoteAccess
+	if err := n.checkRole(userID, noteToken, entity.RoleOwner); err != nil {
+		logger.Warning(err)
+		return err
 	}
 
-	if err := n.notesRepository.Delete(noteToken); err != nil {
+	if err := n.notesRepository.Trash(noteToken, time.Now()); err != nil {
 		logger.Error(err)
 		return err
 	}
 
-	return n.usersNotesRepository.DeleteLink(userID, noteToken)
+	n.publish(entity.NoteDeleted, userID, noteToken)
+	return nil
+}
+
+// deleteForever removes the note with all its subpages, their links,
+// images and attachments.
+func (n *NotesApp) deleteForever(noteToken string) error {
+	subtree, err := n.notesRepository.Subtree(noteToken)
+	if err != nil {
+		return err
+	}
+
+	var images []string
+	var attachments []entity.Attachment
+	for _, token := range subtree {
+		if note, err := n.notesRepository.Find(token); err == nil {
+			images = append(images, iconImage(note.Icon), note.Cover)
+		}
+		if noteAttachments, err := n.attachmentsRepository.AllByNote(token); err == nil {
+			attachments = append(attachments, noteAttachments...)
+		}
+	}
+
+	// The parts of unfinished uploads are kept by the object storage until
+	// the upload is aborted.
+	var uploads []entity.Upload
+	for _, token := range subtree {
+		if noteUploads, err := n.uploadsRepository.AllByNote(token); err == nil {
+			uploads = append(uploads, noteUploads...)
+		}
+	}
+
+	if err := n.notesRepository.Delete(noteToken); err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		if err := n.uploadsRepository.Delete(upload.ID); err != nil {
+			log.WithFields(log.Fields{
+				"package":   packageName,
+				"function":  "deleteForever",
+				"noteToken": upload.NoteToken,
+			}).Warning(err)
+		}
+		n.abortStoredUpload(upload)
+	}
+	for _, image := range images {
+		n.deleteImage(image)
+	}
+	for _, attachment := range attachments {
+		n.deleteAttachmentFile(attachment)
+	}
+	for _, token := range subtree {
+		n.deleteLinks(token)
+		if err := n.noteLinksRepository.Delete(token); err != nil {
+			log.WithFields(log.Fields{
+				"package":   packageName,
+				"function":  "deleteForever",
+				"noteToken": token,
+			}).Warning(err)
+		}
+	}
+
+	return nil
+}
+
+// deleteLinks unlinks every member from a deleted note.
+func (n *NotesApp) deleteLinks(noteToken string) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "deleteLinks",
+		"noteToken": noteToken,
+	})
+
+	members, err := n.usersNotesRepository.Members(noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	for _, member := range members {
+		if err := n.usersNotesRepository.DeleteLink(member.UserID, noteToken); err != nil {
+			logger.Warning(err)
+		}
+	}
+}
+
+// checkRole returns ErrNoteAccess if the user is not a member of the note,
+// ErrNoteRole if the user's role is lower than the required one and
+// ErrNoteInTrash if the note has been deleted.
+func (n *NotesApp) checkRole(userID string, noteToken string, required string) error {
+	note, err := n.checkMember(userID, noteToken, required)
+	if err != nil {
+		return err
+	}
+	if note.DeletedAt != nil {
+		return ErrNoteInTrash
+	}
+	return nil
+}
+
+func (n *NotesApp) checkMember(userID string, noteToken string, required string) (entity.Note, error) {
+	role, err := n.usersNotesRepository.Role(userID, noteToken)
+	if err != nil {
+		return entity.Note{}, ErrNoteAccess
+	}
+	if !entity.RoleAllows(role, required) {
+		return entity.Note{}, ErrNoteRole
+	}
+	return n.notesRepository.Find(noteToken)
 }
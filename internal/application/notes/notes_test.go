@@ -1,8 +1,10 @@
 package notes
 
 import (
+	"cotion/internal/application/notifications"
 	"cotion/internal/domain/entity"
 	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/events"
 	"cotion/internal/pkg/security"
 	"errors"
 	"github.com/stretchr/testify/require"
@@ -10,6 +12,22 @@ import (
 	"testing"
 )
 
+// testDependencies builds NotesApp on in-memory storages without images,
+// files or notifications.
+func testDependencies(notesStorage *storage.NotesStorage, usersNotesStorage *storage.UsersNotesStorage) Dependencies {
+	return Dependencies{
+		Notes:       notesStorage,
+		UsersNotes:  usersNotesStorage,
+		Blocks:      storage.NewBlocksStorage(),
+		Revisions:   storage.NewRevisionsStorage(),
+		Tags:        storage.NewTagsStorage(),
+		NoteLinks:   storage.NewNoteLinksStorage(notesStorage),
+		Attachments: storage.NewAttachmentsStorage(),
+		Uploads:     storage.NewUploadsStorage(),
+		EventBus:    events.NewBus(),
+	}
+}
+
 func TestFindByToken(t *testing.T) {
 	cases := map[string]struct {
 		inUserID    string
@@ -22,8 +40,9 @@ func TestFindByToken(t *testing.T) {
 			expected: func(actualNote entity.Note, actualErr error) {
 				require.Equal(t, nil, actualErr)
 				require.Equal(t, entity.Note{
-					Name: "1st note",
-					Body: "Hello everybody. This is Body of the 1st note)",
+					Name:    "1st note",
+					Body:    "Hello everybody. This is Body of the 1st note)",
+					Version: 1,
 				}, actualNote)
 			},
 		},
@@ -39,7 +58,7 @@ func TestFindByToken(t *testing.T) {
 
 	notesStorage := storage.NewNotesStorage()
 	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
-	notesService := NewNotesApp(notesStorage, usersNotesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
 
 	for name, tc := range cases {
 		tc := tc
@@ -62,12 +81,15 @@ func TestAllNotesByUserID(t *testing.T) {
 			expected: func(actualNote entity.ShortNotes, actualErr error) {
 				require.Equal(t, nil, actualErr)
 				require.Equal(t, entity.ShortNotes{ShortNote: []entity.ShortNote{{
-					Name: "1st note",
-					Body: "Hello everybody. This is Body of the 1st note)",
+					Name:  "1st note",
+					Body:  "Hello everybody. This is Body of the 1st note)",
+					Token: "1",
 				}, {
-					Name: "3st note",
-					Body: "Hello everybody. This is Body of the 3st note)",
+					Name:  "3st note",
+					Body:  "Hello everybody. This is Body of the 3st note)",
+					Token: "3",
 				}},
+					Total: 2,
 				}, actualNote)
 			},
 		},
@@ -89,12 +111,12 @@ func TestAllNotesByUserID(t *testing.T) {
 
 	notesStorage := storage.NewNotesStorage()
 	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
-	notesService := NewNotesApp(notesStorage, usersNotesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
 
 	for name, tc := range cases {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			result, err := notesService.AllNotesByUserID(tc.in)
+			result, err := notesService.AllNotesByUserID(tc.in, entity.NotesFilter{})
 			tc.expected(result, err)
 
 		})
@@ -122,7 +144,7 @@ func TestSaveNote(t *testing.T) {
 
 	notesStorage := storage.NewNotesStorage()
 	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
-	notesService := NewNotesApp(notesStorage, usersNotesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
 
 	for name, tc := range cases {
 		tc := tc
@@ -147,8 +169,9 @@ func TestGetNote(t *testing.T) {
 			expected: func(actualNote entity.Note, actualErr error) {
 				require.Equal(t, nil, actualErr)
 				require.Equal(t, entity.Note{
-					Name: "1st note",
-					Body: "Hello everybody. This is Body of the 1st note)",
+					Name:    "1st note",
+					Body:    "Hello everybody. This is Body of the 1st note)",
+					Version: 1,
 				}, actualNote)
 			},
 		},
@@ -170,9 +193,9 @@ func TestGetNote(t *testing.T) {
 
 	notesStorage := storage.NewNotesStorage()
 	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
-	notesService := NewNotesApp(notesStorage, usersNotesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
 
-	usersNotesStorage.AddLink(string(security.Hash("test@mail.ru")), "0")
+	usersNotesStorage.AddLink(string(security.Hash("test@mail.ru")), "0", entity.RoleOwner)
 
 	for name, tc := range cases {
 		tc := tc
@@ -190,7 +213,8 @@ func TestUpdateNote(t *testing.T) {
 		inUserID      string
 		inNoteToken   string
 		inNoteRequest entity.NoteRequest
-		expected      func(error)
+		inVersion     int
+		expected      func(int, error)
 	}{
 		"Success": {
 			inUserID:    string(security.Hash("test@mail.ru")),
@@ -199,8 +223,22 @@ func TestUpdateNote(t *testing.T) {
 				Name: "Updated 1st note",
 				Body: "Hello everybody. This is Body of the updated 1st note)",
 			},
-			expected: func(actualErr error) {
+			inVersion: 1,
+			expected: func(actualVersion int, actualErr error) {
 				require.Equal(t, nil, actualErr)
+				require.Equal(t, 2, actualVersion)
+			},
+		},
+		"Stale version": {
+			inUserID:    string(security.Hash("test@mail.ru")),
+			inNoteToken: "3",
+			inNoteRequest: entity.NoteRequest{
+				Name: "Updated 3st note",
+			},
+			inVersion: 5,
+			expected: func(actualVersion int, actualErr error) {
+				require.Equal(t, entity.ErrNoteVersionConflict, actualErr)
+				require.Equal(t, 1, actualVersion)
 			},
 		},
 		"ErrNoteAccess": {
@@ -210,7 +248,7 @@ func TestUpdateNote(t *testing.T) {
 				Name: "Updated 1st note",
 				Body: "Hello everybody. This is Body of the updated 1st note)",
 			},
-			expected: func(actualErr error) {
+			expected: func(actualVersion int, actualErr error) {
 				require.Equal(t, ErrNoteAccess, actualErr)
 			},
 		},
@@ -218,13 +256,13 @@ func TestUpdateNote(t *testing.T) {
 
 	notesStorage := storage.NewNotesStorage()
 	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
-	notesService := NewNotesApp(notesStorage, usersNotesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
 
 	for name, tc := range cases {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			err := notesService.UpdateNote(tc.inUserID, tc.inNoteToken, tc.inNoteRequest)
-			tc.expected(err)
+			version, err := notesService.UpdateNote(tc.inUserID, tc.inNoteToken, tc.inNoteRequest, tc.inVersion)
+			tc.expected(version, err)
 
 		})
 		log.Println("SUCCESS")
@@ -248,7 +286,7 @@ func TestDeleteNote(t *testing.T) {
 
 	notesStorage := storage.NewNotesStorage()
 	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
-	notesService := NewNotesApp(notesStorage, usersNotesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
 
 	for name, tc := range cases {
 		tc := tc
@@ -260,3 +298,349 @@ func TestDeleteNote(t *testing.T) {
 		log.Println("SUCCESS")
 	}
 }
+
+func TestMoveNote(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	cases := map[string]struct {
+		inNoteToken   string
+		inParentToken string
+		expected      func(error)
+	}{
+		"Success": {
+			inNoteToken:   "3",
+			inParentToken: "1",
+			expected: func(actualErr error) {
+				require.Equal(t, nil, actualErr)
+			},
+		},
+		"Move to root": {
+			inNoteToken:   "3",
+			inParentToken: "",
+			expected: func(actualErr error) {
+				require.Equal(t, nil, actualErr)
+			},
+		},
+		"Move into itself": {
+			inNoteToken:   "1",
+			inParentToken: "1",
+			expected: func(actualErr error) {
+				require.Equal(t, ErrMoveIntoSubtree, actualErr)
+			},
+		},
+		"ErrNoteAccess to parent": {
+			inNoteToken:   "1",
+			inParentToken: "2",
+			expected: func(actualErr error) {
+				require.Equal(t, ErrNoteAccess, actualErr)
+			},
+		},
+	}
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := notesService.MoveNote(userID, tc.inNoteToken, tc.inParentToken)
+			tc.expected(err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestNotesTree(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.MoveNote(userID, "3", "1"))
+	require.Equal(t, ErrMoveIntoSubtree, notesService.MoveNote(userID, "1", "3"))
+
+	tree, err := notesService.NotesTree(userID)
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.NotesTree{Notes: []entity.NoteTreeItem{{
+		Name:  "1st note",
+		Token: "1",
+		Children: []entity.NoteTreeItem{{
+			Name:     "3st note",
+			Token:    "3",
+			Children: []entity.NoteTreeItem{},
+		}},
+	}}}, tree)
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, nil, notesService.DeleteNoteForever(userID, "1"))
+	_, err = notesStorage.Find("3")
+	require.Equal(t, storage.ErrNoNoteInDB, err)
+	require.Equal(t, false, usersNotesStorage.CheckLink(userID, "3"))
+}
+
+func TestSearchNotes(t *testing.T) {
+	cases := map[string]struct {
+		inUserID string
+		inQuery  string
+		expected func(entity.FoundNotes, error)
+	}{
+		"Match in body": {
+			inUserID: security.Hash("test@mail.ru"),
+			inQuery:  "3st",
+			expected: func(actual entity.FoundNotes, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 1, len(actual.Notes))
+				require.Equal(t, "3", actual.Notes[0].Token)
+				require.Contains(t, actual.Notes[0].Snippet, "<b>3st</b>")
+			},
+		},
+		"Only accessible notes": {
+			inUserID: security.Hash("test@mail.ru"),
+			inQuery:  "2st",
+			expected: func(actual entity.FoundNotes, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 0, len(actual.Notes))
+			},
+		},
+		"Empty query": {
+			inUserID: security.Hash("test@mail.ru"),
+			inQuery:  "  ",
+			expected: func(actual entity.FoundNotes, actualErr error) {
+				require.Equal(t, ErrEmptySearchQuery, actualErr)
+			},
+		},
+	}
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			result, err := notesService.SearchNotes(tc.inUserID, tc.inQuery)
+			tc.expected(result, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestRoles(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	viewerID := security.Hash("nikita@mail.ru")
+	editorID := security.Hash("test2@mail.ru")
+	noteRequest := entity.NoteRequest{Name: "Shared", Body: "Shared body"}
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	usersNotesStorage.AddLink(viewerID, "1", entity.RoleViewer)
+	usersNotesStorage.AddLink(editorID, "1", entity.RoleEditor)
+
+	_, err := notesService.GetNote(viewerID, "1")
+	require.Equal(t, nil, err)
+	_, err = notesService.UpdateNote(viewerID, "1", noteRequest, 0)
+	require.Equal(t, ErrNoteRole, err)
+	require.Equal(t, ErrNoteRole, notesService.DeleteNote(viewerID, "1"))
+
+	_, err = notesService.UpdateNote(editorID, "1", noteRequest, 0)
+	require.Equal(t, nil, err)
+	require.Equal(t, ErrNoteRole, notesService.DeleteNote(editorID, "1"))
+
+	require.Equal(t, nil, notesService.DeleteNote(ownerID, "1"))
+	_, err = notesService.GetNote(viewerID, "1")
+	require.Equal(t, ErrNoteInTrash, err)
+	require.Equal(t, ErrNoteRole, notesService.DeleteNoteForever(editorID, "1"))
+
+	require.Equal(t, nil, notesService.DeleteNoteForever(ownerID, "1"))
+	require.Equal(t, false, usersNotesStorage.CheckLink(viewerID, "1"))
+	require.Equal(t, false, usersNotesStorage.CheckLink(editorID, "1"))
+}
+
+func TestAuthorship(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	editorID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.SaveNote(ownerID, entity.NoteRequest{Name: "4th note"}))
+	notes, err := notesService.AllNotesByUserID(ownerID, entity.NotesFilter{Sort: entity.SortCreated, Desc: true})
+	require.Equal(t, nil, err)
+	created := notes.ShortNote[0]
+	require.Equal(t, "4th note", created.Name)
+	require.Equal(t, ownerID, created.CreatedBy)
+	require.Equal(t, ownerID, created.LastEditedBy)
+	require.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	require.Equal(t, nil, usersNotesStorage.AddLink(editorID, created.Token, entity.RoleEditor))
+	_, err = notesService.UpdateNote(editorID, created.Token, entity.NoteRequest{Name: "edited"}, 0)
+	require.Equal(t, nil, err)
+
+	note, err := notesService.GetNote(ownerID, created.Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, ownerID, note.CreatedBy)
+	require.Equal(t, editorID, note.LastEditedBy)
+	require.Equal(t, created.CreatedAt, note.CreatedAt)
+	require.False(t, note.UpdatedAt.Before(note.CreatedAt))
+	log.Println("SUCCESS")
+}
+
+func TestMentions(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	nikitaID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notificationsService := notifications.NewNotificationsApp(storage.NewNotificationsStorage(), storage.NewMentionsStorage(),
+		storage.NewUserCacheStorage(security.NewSimpleSecurityManager()), usersNotesStorage)
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Notifications = notificationsService
+	notesService := NewNotesApp(deps)
+
+	require.Equal(t, nil, usersNotesStorage.AddLink(nikitaID, "1", entity.RoleEditor))
+	_, err := notesService.UpdateNote(ownerID, "1", entity.NoteRequest{Name: "1st note", Body: "@nikita please check"}, 0)
+	require.Equal(t, nil, err)
+
+	result, err := notificationsService.Notifications(nikitaID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, result.Unread)
+	require.Equal(t, "1", result.Notifications[0].NoteToken)
+	require.Equal(t, ownerID, result.Notifications[0].AuthorID)
+	log.Println("SUCCESS")
+}
+
+func TestDuplicateNote(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.MoveNote(userID, "3", "1"))
+	_, err := notesService.InsertBlock(userID, "1", entity.BlockRequest{Type: entity.BlockText, Content: []byte(`{"text":"hello"}`)})
+	require.Equal(t, nil, err)
+	_, err = notesService.InsertBlock(userID, "1", entity.BlockRequest{Type: entity.BlockImage, Content: []byte(`{"url":"1.jpg"}`)})
+	require.Equal(t, nil, err)
+
+	_, err = notesService.DuplicateNote(userID, "2", userID, entity.DuplicateRequest{})
+	require.Equal(t, ErrNoteAccess, err)
+	_, err = notesService.DuplicateNote(userID, "1", otherID, entity.DuplicateRequest{})
+	require.Equal(t, ErrDuplicateOwner, err)
+
+	duplicated, err := notesService.DuplicateNote(userID, "1", userID, entity.DuplicateRequest{})
+	require.Equal(t, nil, err)
+	note, err := notesService.GetNote(userID, duplicated.Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, "1st note (copy)", note.Name)
+	require.Equal(t, 1, len(note.Blocks))
+	require.Equal(t, entity.BlockText, note.Blocks[0].Type)
+
+	duplicated, err = notesService.DuplicateNote(userID, "1", userID, entity.DuplicateRequest{Subtree: true, Images: true})
+	require.Equal(t, nil, err)
+	note, err = notesService.GetNote(userID, duplicated.Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(note.Blocks))
+
+	tree, err := notesService.NotesTree(userID)
+	require.Equal(t, nil, err)
+	var copied []entity.NoteTreeItem
+	for _, item := range tree.Notes {
+		if item.Token == duplicated.Token {
+			copied = item.Children
+		}
+	}
+	require.Equal(t, 1, len(copied))
+	require.Equal(t, "3st note", copied[0].Name)
+
+	require.Equal(t, nil, usersNotesStorage.AddLink(otherID, "1", entity.RoleViewer))
+	duplicated, err = notesService.DuplicateNote(userID, "1", otherID, entity.DuplicateRequest{})
+	require.Equal(t, nil, err)
+	role, err := usersNotesStorage.Role(otherID, duplicated.Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.RoleOwner, role)
+	require.Equal(t, false, usersNotesStorage.CheckLink(userID, duplicated.Token))
+
+	// The subpage is not shared with the owner, so it is not copied.
+	duplicated, err = notesService.DuplicateNote(userID, "1", otherID, entity.DuplicateRequest{Subtree: true})
+	require.Equal(t, nil, err)
+	subtree, err := notesStorage.Subtree(duplicated.Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, []string{duplicated.Token}, subtree)
+	log.Println("SUCCESS")
+}
+
+func TestDuplicateNoteRollback(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	failing := &failingNotes{NotesStorage: notesStorage, saves: 1}
+	usersNotesStorage := storage.NewUsersNotesStorage(failing)
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Notes = failing
+	notesService := NewNotesApp(deps)
+
+	require.Equal(t, nil, notesService.MoveNote(userID, "3", "1"))
+	duplicated, err := notesService.DuplicateNote(userID, "1", userID, entity.DuplicateRequest{Subtree: true})
+	require.NotEqual(t, nil, err)
+	require.Equal(t, entity.DuplicatedNote{}, duplicated)
+
+	notes, err := notesService.AllNotesByUserID(userID, entity.NotesFilter{})
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(notes.ShortNote))
+	log.Println("SUCCESS")
+}
+
+func TestDuplicateNoteFiles(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	attachmentsStorage := storage.NewAttachmentsStorage()
+	files := newFilesStorage()
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Images = files
+	deps.Files = files
+	deps.Attachments = attachmentsStorage
+	notesService := NewNotesApp(deps)
+
+	files.files["icon.png"] = "image/png"
+	files.files["cover.png"] = "image/png"
+	files.files["report"] = "application/pdf"
+	require.Equal(t, nil, notesStorage.SetIcon("1", &entity.NoteIcon{Type: entity.IconImage, Value: "icon.png"}))
+	require.Equal(t, nil, notesStorage.SetCover("1", "cover.png"))
+	_, err := attachmentsStorage.Save(entity.Attachment{NoteToken: "1", ObjectName: "report", FileName: "report.pdf", ContentType: "application/pdf"})
+	require.Equal(t, nil, err)
+
+	duplicated, err := notesService.DuplicateNote(userID, "1", userID, entity.DuplicateRequest{})
+	require.Equal(t, nil, err)
+	attachments, err := attachmentsStorage.AllByNote(duplicated.Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, len(attachments))
+
+	duplicated, err = notesService.DuplicateNote(userID, "1", userID, entity.DuplicateRequest{Images: true})
+	require.Equal(t, nil, err)
+	copied, err := notesStorage.Find(duplicated.Token)
+	require.Equal(t, nil, err)
+	require.NotEqual(t, "icon.png", copied.Icon.Value)
+	require.NotEqual(t, "cover.png", copied.Cover)
+	attachments, err = attachmentsStorage.AllByNote(duplicated.Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, len(attachments))
+	require.Equal(t, "report.pdf", attachments[0].FileName)
+	require.NotEqual(t, "report", attachments[0].ObjectName)
+
+	// The copy keeps its files when the note is deleted.
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, nil, notesService.DeleteNoteForever(userID, "1"))
+	require.Equal(t, "image/png", files.files[copied.Icon.Value])
+	require.Equal(t, "image/png", files.files[copied.Cover])
+	require.Equal(t, "application/pdf", files.files[attachments[0].ObjectName])
+	require.Equal(t, "", files.files["icon.png"])
+	log.Println("SUCCESS")
+}
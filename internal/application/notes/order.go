@@ -0,0 +1,59 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// Pinned and favorite notes as well as the manual order are kept per user,
+// so every member of a note may change them.
+
+func (n *NotesApp) PinNote(userID string, noteToken string, pinned bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "PinNote",
+	})
+
+	if err := n.checkRole(userID, noteToken, entity.RoleViewer); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	return n.usersNotesRepository.SetPinned(userID, noteToken, pinned)
+}
+
+func (n *NotesApp) FavoriteNote(userID string, noteToken string, favorite bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "FavoriteNote",
+	})
+
+	if err := n.checkRole(userID, noteToken, entity.RoleViewer); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	return n.usersNotesRepository.SetFavorite(userID, noteToken, favorite)
+}
+
+// ReorderNotes puts the given notes at the top of the user's list in the
+// given order. The rest of the notes keep their order after them.
+func (n *NotesApp) ReorderNotes(userID string, noteTokens []string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ReorderNotes",
+	})
+
+	for _, token := range noteTokens {
+		if !n.usersNotesRepository.CheckLink(userID, token) {
+			logger.Warning(ErrNoteAccess)
+			return ErrNoteAccess
+		}
+	}
+
+	if err := n.usersNotesRepository.Reorder(userID, noteTokens); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestNotesOrder(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.SaveNote(userID, entity.NoteRequest{Name: "4th note"}))
+
+	names := func(filter entity.NotesFilter) []string {
+		notes, err := notesService.AllNotesByUserID(userID, filter)
+		require.Equal(t, nil, err)
+		var result []string
+		for _, note := range notes.ShortNote {
+			result = append(result, note.Name)
+		}
+		return result
+	}
+
+	require.Equal(t, ErrNoteAccess, notesService.ReorderNotes(userID, []string{"2"}))
+	require.Equal(t, nil, notesService.ReorderNotes(userID, []string{"3"}))
+	require.Equal(t, []string{"3st note", "1st note", "4th note"}, names(entity.NotesFilter{}))
+
+	require.Equal(t, nil, notesService.PinNote(userID, "1", true))
+	require.Equal(t, []string{"1st note", "3st note", "4th note"}, names(entity.NotesFilter{}))
+	require.Equal(t, nil, notesService.PinNote(userID, "1", false))
+	require.Equal(t, []string{"3st note", "1st note", "4th note"}, names(entity.NotesFilter{}))
+
+	require.Equal(t, ErrNoteAccess, notesService.FavoriteNote(userID, "2", true))
+	require.Equal(t, nil, notesService.FavoriteNote(userID, "1", true))
+	require.Equal(t, []string{"1st note"}, names(entity.NotesFilter{Favorites: true}))
+	log.Println("SUCCESS")
+}
+
+func TestNotesPagination(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.SaveNote(userID, entity.NoteRequest{Name: "2nd note", Body: "Short"}))
+
+	filter := entity.NotesFilter{Sort: entity.SortName, Desc: true, Body: entity.BodyNone, Limit: 2}
+	page, err := notesService.AllNotesByUserID(userID, filter)
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, page.Total)
+	require.Equal(t, 2, len(page.ShortNote))
+	require.Equal(t, "3st note", page.ShortNote[0].Name)
+	require.Equal(t, "2nd note", page.ShortNote[1].Name)
+	require.Equal(t, "", page.ShortNote[0].Body)
+	require.NotEqual(t, "", page.NextCursor)
+
+	filter.Cursor = page.NextCursor
+	page, err = notesService.AllNotesByUserID(userID, filter)
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, len(page.ShortNote))
+	require.Equal(t, "1st note", page.ShortNote[0].Name)
+	require.Equal(t, "", page.NextCursor)
+
+	filter.Cursor = "bad"
+	_, err = notesService.AllNotesByUserID(userID, filter)
+	require.Equal(t, entity.ErrInvalidCursor, err)
+	log.Println("SUCCESS")
+}
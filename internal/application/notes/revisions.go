@@ -0,0 +1,158 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/diff"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+// saveRevision records a full snapshot of the note made by the user, with
+// its current blocks. The note itself is saved already, so a failure is
+// only logged.
+func (n *NotesApp) saveRevision(userID string, noteToken string, note entity.Note) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "saveRevision",
+		"noteToken": noteToken,
+	})
+
+	blocks, err := n.blocksRepository.AllByNote(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	if blocks == nil {
+		blocks = []entity.Block{}
+	}
+
+	revision := entity.Revision{
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		Name:      note.Name,
+		Body:      note.Body,
+		Blocks:    blocks,
+	}
+	if err := n.revisionsRepository.Save(noteToken, revision); err != nil {
+		logger.Error(err)
+	}
+}
+
+// saveBlocksRevision records a revision after the blocks of the note have
+// been changed.
+func (n *NotesApp) saveBlocksRevision(userID string, noteToken string) {
+	note, err := n.notesRepository.Find(noteToken)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "saveBlocksRevision",
+			"noteToken": noteToken,
+		}).Error(err)
+		return
+	}
+	n.saveRevision(userID, noteToken, note)
+}
+
+func (n *NotesApp) Revisions(userID string, noteToken string) (entity.Revisions, error) {
+	if err := n.checkRole(userID, noteToken, entity.RoleViewer); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Revisions",
+		}).Warning(err)
+		return entity.Revisions{}, err
+	}
+
+	revisions, err := n.revisionsRepository.AllByNote(noteToken)
+	if err != nil {
+		return entity.Revisions{}, err
+	}
+	return entity.Revisions{Revisions: revisions}, nil
+}
+
+func (n *NotesApp) GetRevision(userID string, noteToken string, revisionID int) (entity.Revision, error) {
+	if err := n.checkRole(userID, noteToken, entity.RoleViewer); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "GetRevision",
+		}).Warning(err)
+		return entity.Revision{}, err
+	}
+
+	return n.revisionsRepository.Find(noteToken, revisionID)
+}
+
+func (n *NotesApp) DiffRevisions(userID string, noteToken string, fromID int, toID int) (entity.RevisionDiff, error) {
+	from, err := n.GetRevision(userID, noteToken, fromID)
+	if err != nil {
+		return entity.RevisionDiff{}, err
+	}
+
+	to, err := n.revisionsRepository.Find(noteToken, toID)
+	if err != nil {
+		return entity.RevisionDiff{}, err
+	}
+
+	return entity.RevisionDiff{
+		From:     from.ID,
+		To:       to.ID,
+		FromName: from.Name,
+		ToName:   to.Name,
+		Diff:     diff.Lines(from.Body, to.Body),
+	}, nil
+}
+
+// RestoreRevision makes an old revision the current content of the note,
+// together with its blocks if the revision has them. The restore is itself
+// recorded as a new revision.
+func (n *NotesApp) RestoreRevision(userID string, noteToken string, revisionID int) error {
+	revision, err := n.GetRevision(userID, noteToken, revisionID)
+	if err != nil {
+		return err
+	}
+	note, err := n.checkMember(userID, noteToken, entity.RoleEditor)
+	if err != nil {
+		return err
+	}
+	if note.DeletedAt != nil {
+		return ErrNoteInTrash
+	}
+
+	if revision.Blocks != nil {
+		if err := n.replaceBlocks(noteToken, revision.Blocks); err != nil {
+			log.WithFields(log.Fields{
+				"package":   packageName,
+				"function":  "RestoreRevision",
+				"noteToken": noteToken,
+			}).Error(err)
+			return err
+		}
+	}
+
+	_, err = n.UpdateNote(userID, noteToken, entity.NoteRequest{
+		Name: revision.Name,
+		Body: revision.Body,
+	}, 0)
+	return err
+}
+
+// replaceBlocks makes the blocks the only blocks of the note, in their
+// order.
+func (n *NotesApp) replaceBlocks(noteToken string, blocks []entity.Block) error {
+	current, err := n.blocksRepository.AllByNote(noteToken)
+	if err != nil {
+		return err
+	}
+	for _, block := range current {
+		if err := n.blocksRepository.Delete(noteToken, block.ID); err != nil {
+			return err
+		}
+	}
+
+	for position, block := range blocks {
+		block.Position = position
+		if err := n.blocksRepository.Insert(noteToken, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// failingRevisions cannot save revisions.
+type failingRevisions struct {
+	*storage.RevisionsStorage
+}
+
+func (store failingRevisions) Save(noteToken string, revision entity.Revision) error {
+	return errors.New("no connection")
+}
+
+func TestRevisions(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	_, err := notesService.UpdateNote(userID, "1", entity.NoteRequest{Name: "Plan", Body: "first\nsecond"}, 0)
+	require.Equal(t, nil, err)
+	_, err = notesService.UpdateNote(userID, "1", entity.NoteRequest{Name: "Plan v2", Body: "first\n2nd"}, 0)
+	require.Equal(t, nil, err)
+
+	revisions, err := notesService.Revisions(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(revisions.Revisions))
+	require.Equal(t, "Plan v2", revisions.Revisions[0].Name)
+	require.Equal(t, userID, revisions.Revisions[0].UserID)
+
+	oldID, newID := revisions.Revisions[1].ID, revisions.Revisions[0].ID
+	revisionDiff, err := notesService.DiffRevisions(userID, "1", oldID, newID)
+	require.Equal(t, nil, err)
+	require.Equal(t, " first\n-second\n+2nd", revisionDiff.Diff)
+
+	require.Equal(t, nil, notesService.RestoreRevision(userID, "1", oldID))
+	note, err := notesService.GetNote(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, "first\nsecond", note.Body)
+
+	revisions, err = notesService.Revisions(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, len(revisions.Revisions))
+
+	_, err = notesService.GetRevision(userID, "2", oldID)
+	require.Equal(t, ErrNoteAccess, err)
+	_, err = notesService.GetRevision(userID, "1", 100)
+	require.Equal(t, storage.ErrNoRevisionInDB, err)
+}
+
+func TestRevisionBlocks(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	text, err := notesService.InsertBlock(userID, "1", entity.BlockRequest{Type: entity.BlockText, Content: json.RawMessage(`{"text":"a"}`)})
+	require.Equal(t, nil, err)
+	_, err = notesService.InsertBlock(userID, "1", entity.BlockRequest{Type: entity.BlockTodo, Content: json.RawMessage(`{"text":"b"}`)})
+	require.Equal(t, nil, err)
+	require.Equal(t, nil, notesService.DeleteBlock(userID, "1", text.ID))
+
+	revisions, err := notesService.Revisions(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, len(revisions.Revisions))
+
+	twoBlocks, err := notesService.GetRevision(userID, "1", revisions.Revisions[1].ID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(twoBlocks.Blocks))
+
+	require.Equal(t, nil, notesService.RestoreRevision(userID, "1", twoBlocks.ID))
+	note, err := notesService.GetNote(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, twoBlocks.Blocks, note.Blocks)
+
+	revisions, err = notesService.Revisions(userID, "1")
+	require.Equal(t, nil, err)
+	restored, err := notesService.GetRevision(userID, "1", revisions.Revisions[0].ID)
+	require.Equal(t, nil, err)
+	require.Equal(t, twoBlocks.Blocks, restored.Blocks)
+}
+
+func TestUpdateNoteWithoutRevision(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Revisions = failingRevisions{storage.NewRevisionsStorage()}
+	notesService := NewNotesApp(deps)
+
+	version, err := notesService.UpdateNote(userID, "1", entity.NoteRequest{Name: "Plan", Body: "first"}, 1)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, version)
+}
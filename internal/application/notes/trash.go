@@ -0,0 +1,129 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+func (n *NotesApp) Trash(userID string) (entity.TrashedNotes, error) {
+	notes, err := n.usersNotesRepository.TrashByUserID(userID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Trash",
+		}).Error(err)
+		return entity.TrashedNotes{}, err
+	}
+	return entity.TrashedNotes{Notes: notes}, nil
+}
+
+// RestoreNote takes the note out of the trash together with the subpages
+// deleted along with it. If its parent is still in the trash, the note
+// becomes a top-level one.
+func (n *NotesApp) RestoreNote(userID string, noteToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "RestoreNote",
+	})
+
+	note, err := n.checkTrashed(userID, noteToken)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.notesRepository.Restore(noteToken); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if note.Parent != "" {
+		parent, err := n.notesRepository.Find(note.Parent)
+		if err != nil || parent.DeletedAt != nil {
+			if err := n.notesRepository.Move(noteToken, ""); err != nil {
+				logger.Error(err)
+				return err
+			}
+		}
+	}
+
+	n.publish(entity.NoteCreated, userID, noteToken)
+	return nil
+}
+
+func (n *NotesApp) DeleteNoteForever(userID string, noteToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteNoteForever",
+	})
+
+	if _, err := n.checkTrashed(userID, noteToken); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.deleteForever(noteToken); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes the notes that have been in the trash
+// for longer than the retention period.
+func (n *NotesApp) PurgeTrash(retention time.Duration) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "PurgeTrash",
+	})
+
+	tokens, err := n.notesRepository.ExpiredTrash(time.Now().Add(-retention))
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	for _, token := range tokens {
+		// A subpage may already be gone together with its parent.
+		if _, err := n.notesRepository.Find(token); err != nil {
+			continue
+		}
+		if err := n.deleteForever(token); err != nil {
+			logger.Error(err)
+		}
+	}
+
+	if len(tokens) != 0 {
+		logger.Infof("Purged %d notes from the trash.", len(tokens))
+	}
+	return nil
+}
+
+// RunTrashPurge calls PurgeTrash and PurgeUploads every interval until stop
+// is closed.
+func (n *NotesApp) RunTrashPurge(interval time.Duration, retention time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n.PurgeTrash(retention)
+		n.PurgeUploads()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (n *NotesApp) checkTrashed(userID string, noteToken string) (entity.Note, error) {
+	note, err := n.checkMember(userID, noteToken, entity.RoleOwner)
+	if err != nil {
+		return entity.Note{}, err
+	}
+	if note.DeletedAt == nil {
+		return entity.Note{}, ErrNoteNotInTrash
+	}
+	return note, nil
+}
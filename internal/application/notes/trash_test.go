@@ -0,0 +1,81 @@
+package notes
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.MoveNote(userID, "3", "1"))
+	require.Equal(t, ErrNoteNotInTrash, notesService.RestoreNote(userID, "1"))
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, ErrNoteInTrash, notesService.DeleteNote(userID, "1"))
+
+	notes, err := notesService.AllNotesByUserID(userID, entity.NotesFilter{})
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, len(notes.ShortNote))
+
+	trash, err := notesService.Trash(userID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(trash.Notes))
+
+	require.Equal(t, nil, notesService.RestoreNote(userID, "1"))
+	notes, err = notesService.AllNotesByUserID(userID, entity.NotesFilter{})
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(notes.ShortNote))
+	log.Println("SUCCESS")
+}
+
+func TestRestoreSubpage(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.MoveNote(userID, "3", "1"))
+	require.Equal(t, nil, notesService.DeleteNote(userID, "3"))
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+
+	// The subpage was deleted on its own, so it stays in the trash.
+	require.Equal(t, nil, notesService.RestoreNote(userID, "1"))
+	_, err := notesService.GetNote(userID, "3")
+	require.Equal(t, ErrNoteInTrash, err)
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, nil, notesService.RestoreNote(userID, "3"))
+	note, err := notesService.GetNote(userID, "3")
+	require.Equal(t, nil, err)
+	require.Equal(t, "", note.Parent)
+	log.Println("SUCCESS")
+}
+
+func TestPurgeTrash(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	notesService := NewNotesApp(testDependencies(notesStorage, usersNotesStorage))
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, nil, notesService.PurgeTrash(entity.DefaultTrashRetention))
+	_, err := notesStorage.Find("1")
+	require.Equal(t, nil, err)
+
+	require.Equal(t, nil, notesService.PurgeTrash(-time.Minute))
+	_, err = notesStorage.Find("1")
+	require.Equal(t, storage.ErrNoNoteInDB, err)
+	require.Equal(t, false, usersNotesStorage.CheckLink(userID, "1"))
+	log.Println("SUCCESS")
+}
@@ -0,0 +1,36 @@
+package notes
+
+import "cotion/internal/domain/entity"
+
+// buildTree nests notes under their parents. Notes whose parent is not
+// in the list (e.g. a subpage shared without its parent) become roots.
+func buildTree(notes []entity.ShortNote) entity.NotesTree {
+	tokens := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		tokens[note.Token] = true
+	}
+
+	children := make(map[string][]entity.ShortNote)
+	var roots []entity.ShortNote
+	for _, note := range notes {
+		if note.Parent == "" || !tokens[note.Parent] {
+			roots = append(roots, note)
+			continue
+		}
+		children[note.Parent] = append(children[note.Parent], note)
+	}
+
+	return entity.NotesTree{Notes: treeItems(roots, children)}
+}
+
+func treeItems(notes []entity.ShortNote, children map[string][]entity.ShortNote) []entity.NoteTreeItem {
+	items := make([]entity.NoteTreeItem, 0, len(notes))
+	for _, note := range notes {
+		items = append(items, entity.NoteTreeItem{
+			Name:     note.Name,
+			Token:    note.Token,
+			Children: treeItems(children[note.Token], children),
+		})
+	}
+	return items
+}
@@ -0,0 +1,304 @@
+package notes
+
+import (
+	"bytes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/generator"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"time"
+)
+
+const uploadIDLength = 16
+
+var ErrUploadNotFound = errors.New("The upload does not exist or has expired.")
+var ErrUploadOffset = errors.New("The chunk does not start at the offset of the upload.")
+var ErrChunkSize = errors.New("The size of the chunk is out of range.")
+
+// CreateUpload starts a chunked upload of an attachment. The chunks are
+// sent with UploadChunk, the upload expires if it is not finished in time.
+func (n *NotesApp) CreateUpload(userID string, noteToken string, uploadRequest entity.UploadRequest) (entity.Upload, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "CreateUpload",
+		"noteToken": noteToken,
+	})
+
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		logger.Warning(err)
+		return entity.Upload{}, err
+	}
+
+	uploadID, err := generator.RandSecureToken(uploadIDLength)
+	if err != nil {
+		logger.Error(err)
+		return entity.Upload{}, err
+	}
+
+	upload := entity.Upload{
+		ID:        uploadID,
+		NoteToken: noteToken,
+		UserID:    userID,
+		FileName:  attachmentFileName(uploadRequest.FileName),
+		Size:      uploadRequest.Size,
+		ExpiresAt: time.Now().Add(entity.UploadExpiration),
+	}
+	if err := n.uploadsRepository.Save(upload); err != nil {
+		logger.Error(err)
+		return entity.Upload{}, err
+	}
+	return upload, nil
+}
+
+// UploadStatus returns how much of the file has been received.
+func (n *NotesApp) UploadStatus(userID string, noteToken string, uploadID string) (entity.Upload, error) {
+	upload, err := n.findUpload(userID, noteToken, uploadID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "UploadStatus",
+			"noteToken": noteToken,
+		}).Warning(err)
+		return entity.Upload{}, err
+	}
+	return upload, nil
+}
+
+// UploadChunk appends the chunk of the given length to the upload. The chunk
+// has to start where the previous one ended and every chunk but the last one
+// has to be at least MinChunkSize long. The type of the file is detected
+// from the first chunk. The upload returned after the last chunk has the
+// attachment it became.
+func (n *NotesApp) UploadChunk(userID string, noteToken string, uploadID string, offset int64, chunk io.Reader, length int64) (entity.Upload, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "UploadChunk",
+		"noteToken": noteToken,
+	})
+
+	// Two chunks at the same offset would be stored as the same part, so
+	// the upload takes one chunk at a time and the offset is checked once
+	// the chunk has it.
+	if !n.claimUpload(uploadID) {
+		logger.Warning(ErrUploadOffset)
+		return entity.Upload{}, ErrUploadOffset
+	}
+	defer n.releaseUpload(uploadID)
+
+	upload, err := n.findUpload(userID, noteToken, uploadID)
+	if err != nil {
+		logger.Warning(err)
+		return entity.Upload{}, err
+	}
+	if offset != upload.Offset {
+		logger.Warning(ErrUploadOffset)
+		return entity.Upload{}, ErrUploadOffset
+	}
+	if err := checkChunkSize(upload, length); err != nil {
+		logger.Warning(err)
+		return entity.Upload{}, err
+	}
+
+	started := upload.StorageID == ""
+	if started {
+		head := make([]byte, minInt64(sniffLength, length))
+		if _, err := io.ReadFull(chunk, head); err != nil {
+			logger.Warning(err)
+			return entity.Upload{}, err
+		}
+		chunk = io.MultiReader(bytes.NewReader(head), chunk)
+
+		upload.ContentType = sniffContentType(head, upload.FileName)
+		upload.ObjectName, upload.StorageID, err = n.fileRepository.CreateUpload(upload.ContentType)
+		if err != nil {
+			logger.Error(err)
+			return entity.Upload{}, err
+		}
+	}
+
+	partNumber := len(upload.Parts) + 1
+	etag, err := n.fileRepository.UploadPart(upload.ObjectName, upload.StorageID, partNumber, chunk, length)
+	if err != nil {
+		logger.Error(err)
+		if started {
+			n.abortStoredUpload(upload)
+		}
+		return entity.Upload{}, err
+	}
+
+	upload.Parts = append(upload.Parts, entity.UploadPart{Number: partNumber, ETag: etag})
+	upload.Offset += length
+	if err := n.uploadsRepository.Update(upload, offset); err != nil {
+		if started {
+			n.abortStoredUpload(upload)
+		}
+		if err == entity.ErrUploadOffsetConflict {
+			logger.Warning(err)
+			return entity.Upload{}, ErrUploadOffset
+		}
+		logger.Error(err)
+		return entity.Upload{}, err
+	}
+
+	if upload.Offset < upload.Size {
+		return upload, nil
+	}
+
+	attachment, err := n.completeUpload(upload)
+	if err != nil {
+		logger.Error(err)
+		return entity.Upload{}, err
+	}
+	upload.Attachment = &attachment
+	return upload, nil
+}
+
+// AbortUpload cancels the upload and drops the chunks received so far.
+func (n *NotesApp) AbortUpload(userID string, noteToken string, uploadID string) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "AbortUpload",
+		"noteToken": noteToken,
+	})
+
+	upload, err := n.findUpload(userID, noteToken, uploadID)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	if err := n.uploadsRepository.Delete(uploadID); err != nil {
+		logger.Error(err)
+		return err
+	}
+	n.abortStoredUpload(upload)
+	return nil
+}
+
+// PurgeUploads drops the uploads that have expired.
+func (n *NotesApp) PurgeUploads() error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "PurgeUploads",
+	})
+
+	uploads, err := n.uploadsRepository.Expired(time.Now())
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	for _, upload := range uploads {
+		if err := n.uploadsRepository.Delete(upload.ID); err != nil {
+			logger.Error(err)
+			continue
+		}
+		n.abortStoredUpload(upload)
+	}
+
+	if len(uploads) != 0 {
+		logger.Infof("Purged %d expired uploads.", len(uploads))
+	}
+	return nil
+}
+
+// claimUpload marks the upload as receiving a chunk. It fails while another
+// chunk of the upload is being received.
+func (n *NotesApp) claimUpload(uploadID string) bool {
+	n.uploadsMu.Lock()
+	defer n.uploadsMu.Unlock()
+
+	if _, ok := n.receiving[uploadID]; ok {
+		return false
+	}
+	n.receiving[uploadID] = struct{}{}
+	return true
+}
+
+func (n *NotesApp) releaseUpload(uploadID string) {
+	n.uploadsMu.Lock()
+	defer n.uploadsMu.Unlock()
+
+	delete(n.receiving, uploadID)
+}
+
+// completeUpload assembles the file from its chunks and attaches it to the
+// note. The upload is dropped whether or not this succeeds, as it cannot be
+// continued once all of it is received.
+func (n *NotesApp) completeUpload(upload entity.Upload) (entity.Attachment, error) {
+	if err := n.uploadsRepository.Delete(upload.ID); err != nil {
+		return entity.Attachment{}, err
+	}
+
+	if err := n.fileRepository.CompleteUpload(upload.ObjectName, upload.StorageID, upload.Parts); err != nil {
+		n.abortStoredUpload(upload)
+		return entity.Attachment{}, err
+	}
+
+	attachment := entity.Attachment{
+		NoteToken:   upload.NoteToken,
+		ObjectName:  upload.ObjectName,
+		FileName:    upload.FileName,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		UploadedBy:  upload.UserID,
+		CreatedAt:   time.Now(),
+	}
+	var err error
+	attachment.ID, err = n.attachmentsRepository.Save(attachment)
+	if err != nil {
+		n.deleteAttachmentFile(attachment)
+		return entity.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// findUpload returns the upload if the user started it on the note, can
+// still edit the note and the upload has not expired.
+func (n *NotesApp) findUpload(userID string, noteToken string, uploadID string) (entity.Upload, error) {
+	if err := n.checkRole(userID, noteToken, entity.RoleEditor); err != nil {
+		return entity.Upload{}, err
+	}
+
+	upload, err := n.uploadsRepository.Find(uploadID)
+	if err != nil || upload.NoteToken != noteToken || upload.UserID != userID {
+		return entity.Upload{}, ErrUploadNotFound
+	}
+	if !upload.ExpiresAt.After(time.Now()) {
+		return entity.Upload{}, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// abortStoredUpload drops the chunks in the object storage, a failure is
+// only logged like for deleted files.
+func (n *NotesApp) abortStoredUpload(upload entity.Upload) {
+	if upload.StorageID == "" {
+		return
+	}
+	if err := n.fileRepository.AbortUpload(upload.ObjectName, upload.StorageID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "abortStoredUpload",
+			"uploadID": upload.ID,
+		}).Warning(err)
+	}
+}
+
+func checkChunkSize(upload entity.Upload, length int64) error {
+	if length <= 0 || length > entity.MaxChunkSize || upload.Offset+length > upload.Size {
+		return ErrChunkSize
+	}
+	if upload.Offset+length < upload.Size && length < entity.MinChunkSize {
+		return ErrChunkSize
+	}
+	return nil
+}
+
+func minInt64(a int64, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
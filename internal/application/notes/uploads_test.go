@@ -0,0 +1,168 @@
+package notes
+
+import (
+	"bytes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestUploads(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	files := newFilesStorage()
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Files = files
+	notesService := NewNotesApp(deps)
+
+	first := append([]byte("%PDF-1.4 "), make([]byte, entity.MinChunkSize-9)...)
+	last := []byte("report end")
+	size := int64(len(first) + len(last))
+
+	_, err := notesService.CreateUpload(userID, "2", entity.UploadRequest{FileName: "report.pdf", Size: size})
+	require.Equal(t, ErrNoteAccess, err)
+
+	upload, err := notesService.CreateUpload(userID, "1", entity.UploadRequest{FileName: "../report.pdf", Size: size})
+	require.Equal(t, nil, err)
+	require.Equal(t, "report.pdf", upload.FileName)
+
+	require.Equal(t, nil, usersNotesStorage.AddLink(otherID, "1", entity.RoleEditor))
+	_, err = notesService.UploadStatus(otherID, "1", upload.ID)
+	require.Equal(t, ErrUploadNotFound, err)
+	_, err = notesService.UploadStatus(userID, "3", upload.ID)
+	require.Equal(t, ErrUploadNotFound, err)
+
+	_, err = notesService.UploadChunk(userID, "1", upload.ID, 5, bytes.NewReader(first), int64(len(first)))
+	require.Equal(t, ErrUploadOffset, err)
+	_, err = notesService.UploadChunk(userID, "1", upload.ID, 0, bytes.NewReader(last), int64(len(last)))
+	require.Equal(t, ErrChunkSize, err)
+
+	upload, err = notesService.UploadChunk(userID, "1", upload.ID, 0, bytes.NewReader(first), int64(len(first)))
+	require.Equal(t, nil, err)
+	require.Equal(t, int64(len(first)), upload.Offset)
+	require.Equal(t, (*entity.Attachment)(nil), upload.Attachment)
+
+	status, err := notesService.UploadStatus(userID, "1", upload.ID)
+	require.Equal(t, nil, err)
+	require.Equal(t, upload.Offset, status.Offset)
+
+	_, err = notesService.UploadChunk(userID, "1", upload.ID, 0, bytes.NewReader(first), int64(len(first)))
+	require.Equal(t, ErrUploadOffset, err)
+	_, err = notesService.UploadChunk(userID, "1", upload.ID, upload.Offset, bytes.NewReader(first), int64(len(first)))
+	require.Equal(t, ErrChunkSize, err)
+
+	upload, err = notesService.UploadChunk(userID, "1", upload.ID, upload.Offset, bytes.NewReader(last), int64(len(last)))
+	require.Equal(t, nil, err)
+	require.NotEqual(t, (*entity.Attachment)(nil), upload.Attachment)
+	require.Equal(t, "report.pdf", upload.Attachment.FileName)
+	require.Equal(t, "application/pdf", upload.Attachment.ContentType)
+	require.Equal(t, size, upload.Attachment.Size)
+	require.Equal(t, "application/pdf", files.files[upload.Attachment.ObjectName])
+	require.Equal(t, 0, len(files.uploads))
+
+	attachments, err := notesService.Attachments(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, []entity.Attachment{*upload.Attachment}, attachments.Attachments)
+	_, err = notesService.UploadStatus(userID, "1", upload.ID)
+	require.Equal(t, ErrUploadNotFound, err)
+
+	small, err := notesService.CreateUpload(otherID, "1", entity.UploadRequest{FileName: "a.txt", Size: 3})
+	require.Equal(t, nil, err)
+	small, err = notesService.UploadChunk(otherID, "1", small.ID, 0, bytes.NewReader([]byte("abc")), 3)
+	require.Equal(t, nil, err)
+	require.Equal(t, int64(3), small.Attachment.Size)
+	log.Println("SUCCESS")
+}
+
+func TestAbortUpload(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	uploadsStorage := storage.NewUploadsStorage()
+	files := newFilesStorage()
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Files = files
+	deps.Uploads = uploadsStorage
+	notesService := NewNotesApp(deps)
+
+	chunk := make([]byte, entity.MinChunkSize)
+	upload, err := notesService.CreateUpload(userID, "1", entity.UploadRequest{FileName: "big.bin", Size: 2 * entity.MinChunkSize})
+	require.Equal(t, nil, err)
+	_, err = notesService.UploadChunk(userID, "1", upload.ID, 0, bytes.NewReader(chunk), int64(len(chunk)))
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, len(files.uploads))
+
+	require.Equal(t, nil, notesService.AbortUpload(userID, "1", upload.ID))
+	require.Equal(t, 0, len(files.uploads))
+	require.Equal(t, ErrUploadNotFound, notesService.AbortUpload(userID, "1", upload.ID))
+
+	expired := entity.Upload{ID: "expired", NoteToken: "1", UserID: userID, FileName: "old.bin", Size: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+	require.Equal(t, nil, uploadsStorage.Save(expired))
+	_, err = notesService.UploadStatus(userID, "1", expired.ID)
+	require.Equal(t, ErrUploadNotFound, err)
+
+	require.Equal(t, nil, notesService.PurgeUploads())
+	_, err = uploadsStorage.Find(expired.ID)
+	require.Equal(t, storage.ErrNoUploadInDB, err)
+
+	// Deleting the note aborts its unfinished uploads.
+	upload, err = notesService.CreateUpload(userID, "1", entity.UploadRequest{FileName: "big.bin", Size: 2 * entity.MinChunkSize})
+	require.Equal(t, nil, err)
+	_, err = notesService.UploadChunk(userID, "1", upload.ID, 0, bytes.NewReader(chunk), int64(len(chunk)))
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, len(files.uploads))
+
+	require.Equal(t, nil, notesService.DeleteNote(userID, "1"))
+	require.Equal(t, nil, notesService.DeleteNoteForever(userID, "1"))
+	require.Equal(t, 0, len(files.uploads))
+	_, err = uploadsStorage.Find(upload.ID)
+	require.Equal(t, storage.ErrNoUploadInDB, err)
+	log.Println("SUCCESS")
+}
+
+func TestOverlappingChunks(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	files := newFilesStorage()
+	deps := testDependencies(notesStorage, usersNotesStorage)
+	deps.Files = files
+	notesService := NewNotesApp(deps)
+
+	chunk := make([]byte, entity.MinChunkSize)
+	upload, err := notesService.CreateUpload(userID, "1", entity.UploadRequest{FileName: "big.bin", Size: 2 * entity.MinChunkSize})
+	require.Equal(t, nil, err)
+
+	// The first chunk is still being received when its retry comes in.
+	reader, writer := io.Pipe()
+	result := make(chan error)
+	go func() {
+		_, err := notesService.UploadChunk(userID, "1", upload.ID, 0, reader, int64(len(chunk)))
+		result <- err
+	}()
+	_, err = writer.Write(chunk[:1])
+	require.Equal(t, nil, err)
+
+	_, err = notesService.UploadChunk(userID, "1", upload.ID, 0, bytes.NewReader(chunk), int64(len(chunk)))
+	require.Equal(t, ErrUploadOffset, err)
+
+	_, err = writer.Write(chunk[1:])
+	require.Equal(t, nil, err)
+	require.Equal(t, nil, writer.Close())
+	require.Equal(t, nil, <-result)
+
+	upload, err = notesService.UploadChunk(userID, "1", upload.ID, int64(len(chunk)), bytes.NewReader(chunk), int64(len(chunk)))
+	require.Equal(t, nil, err)
+	require.NotEqual(t, (*entity.Attachment)(nil), upload.Attachment)
+	log.Println("SUCCESS")
+}
@@ -0,0 +1,178 @@
+package notifications
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/mention"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	packageName = "app notifications"
+	// notificationsLimit is how many of the newest notifications are shown.
+	notificationsLimit = 50
+)
+
+var ErrNotificationNotFound = errors.New("The notification does not exist.")
+
+type NotificationsApp struct {
+	notificationsRepository repository.NotificationsRepository
+	mentionsRepository      repository.MentionsRepository
+	userRepository          repository.UserRepository
+	usersNotesRepository    repository.UsersNotesRepository
+}
+
+func NewNotificationsApp(notificationsRepo repository.NotificationsRepository, mentionsRepo repository.MentionsRepository,
+	userRepo repository.UserRepository, usersNotesRepo repository.UsersNotesRepository) *NotificationsApp {
+	return &NotificationsApp{
+		notificationsRepository: notificationsRepo,
+		mentionsRepository:      mentionsRepo,
+		userRepository:          userRepo,
+		usersNotesRepository:    usersNotesRepo,
+	}
+}
+
+// Notifications returns the newest notifications of the user and how many
+// of all their notifications are unread.
+func (a *NotificationsApp) Notifications(userID string) (entity.Notifications, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Notifications",
+	})
+
+	notifications, err := a.notificationsRepository.AllByUserID(userID, notificationsLimit)
+	if err != nil {
+		logger.Error(err)
+		return entity.Notifications{}, err
+	}
+	unread, err := a.notificationsRepository.CountUnread(userID)
+	if err != nil {
+		logger.Error(err)
+		return entity.Notifications{}, err
+	}
+
+	if notifications == nil {
+		notifications = []entity.Notification{}
+	}
+	return entity.Notifications{Notifications: notifications, Unread: unread}, nil
+}
+
+func (a *NotificationsApp) MarkRead(userID string, notificationID int) error {
+	if err := a.notificationsRepository.MarkRead(userID, notificationID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "MarkRead",
+		}).Warning(err)
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (a *NotificationsApp) MarkAllRead(userID string) error {
+	return a.notificationsRepository.MarkAllRead(userID)
+}
+
+// Mention stores who is mentioned in the text of the note or, if commentID
+// is set, of the comment, and notifies the users who were not mentioned in
+// it before. Only members of the note can be mentioned, so that nobody is
+// told about a note they cannot open, and authors do not mention themselves.
+// Usernames are not unique, a mention reaches every member with the name.
+// A nil NotificationsApp ignores mentions.
+func (a *NotificationsApp) Mention(authorID string, noteToken string, commentID int, text string) error {
+	if a == nil {
+		return nil
+	}
+
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Mention",
+		"noteToken": noteToken,
+	})
+
+	members, err := a.membersByUsername(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	var mentioned []string
+	isMentioned := map[string]bool{}
+	for _, username := range mention.Parse(text) {
+		for _, userID := range members[username] {
+			if userID == authorID || isMentioned[userID] {
+				continue
+			}
+			isMentioned[userID] = true
+			mentioned = append(mentioned, userID)
+		}
+	}
+
+	previous, err := a.mentionsRepository.Mentioned(noteToken, commentID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	wasMentioned := map[string]bool{}
+	for _, userID := range previous {
+		wasMentioned[userID] = true
+		if isMentioned[userID] {
+			continue
+		}
+		mention := entity.Mention{NoteToken: noteToken, CommentID: commentID, UserID: userID}
+		if err := a.mentionsRepository.Delete(mention); err != nil {
+			logger.Error(err)
+			return err
+		}
+	}
+
+	now := time.Now()
+	for _, userID := range mentioned {
+		if wasMentioned[userID] {
+			continue
+		}
+		mention := entity.Mention{NoteToken: noteToken, CommentID: commentID, UserID: userID}
+		if err := a.mentionsRepository.Save(mention); err != nil {
+			logger.Error(err)
+			return err
+		}
+		notification := entity.Notification{
+			UserID:    userID,
+			Type:      entity.NotificationMention,
+			NoteToken: noteToken,
+			CommentID: commentID,
+			AuthorID:  authorID,
+			CreatedAt: now,
+		}
+		if _, err := a.notificationsRepository.Save(notification); err != nil {
+			logger.Error(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// membersByUsername returns the IDs of the members of the note by their
+// usernames. The username is looked up if the members come without it.
+func (a *NotificationsApp) membersByUsername(noteToken string) (map[string][]string, error) {
+	members, err := a.usersNotesRepository.Members(noteToken)
+	if err != nil {
+		return nil, err
+	}
+
+	byUsername := map[string][]string{}
+	for _, member := range members {
+		username := member.Username
+		if username == "" {
+			user, err := a.userRepository.Get(member.UserID)
+			if err != nil {
+				continue
+			}
+			username = user.Username
+		}
+		byUsername[username] = append(byUsername[username], member.UserID)
+	}
+	return byUsername, nil
+}
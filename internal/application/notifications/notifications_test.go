@@ -0,0 +1,86 @@
+package notifications
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestMention(t *testing.T) {
+	securityManager := security.NewSimpleSecurityManager()
+	authorID := security.Hash("test@mail.ru")
+	nikitaID := security.Hash("nikita@mail.ru")
+	strangerID := security.Hash("test2@mail.ru")
+
+	usersNotesStorage := storage.NewUsersNotesStorage(storage.NewNotesStorage())
+	usersNotesStorage.AddLink(nikitaID, "1", entity.RoleViewer)
+	notificationsService := NewNotificationsApp(storage.NewNotificationsStorage(), storage.NewMentionsStorage(),
+		storage.NewUserCacheStorage(securityManager), usersNotesStorage)
+
+	// test2 has no access to the note and test is the author.
+	require.Equal(t, nil, notificationsService.Mention(authorID, "1", 0, "@nikita @test2 @test @nobody"))
+	require.Equal(t, nil, notificationsService.Mention(authorID, "1", 0, "still @nikita"))
+
+	notifications, err := notificationsService.Notifications(nikitaID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, notifications.Unread)
+	require.Equal(t, 1, len(notifications.Notifications))
+	mention := notifications.Notifications[0]
+	require.Equal(t, entity.NotificationMention, mention.Type)
+	require.Equal(t, "1", mention.NoteToken)
+	require.Equal(t, authorID, mention.AuthorID)
+
+	notifications, err = notificationsService.Notifications(strangerID)
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.Notifications{Notifications: []entity.Notification{}}, notifications)
+
+	// Removing the mention and adding it again notifies again, so does a
+	// mention in a comment.
+	require.Equal(t, nil, notificationsService.Mention(authorID, "1", 0, "nobody"))
+	require.Equal(t, nil, notificationsService.Mention(authorID, "1", 0, "@nikita again"))
+	require.Equal(t, nil, notificationsService.Mention(authorID, "1", 7, "@nikita look"))
+
+	notifications, err = notificationsService.Notifications(nikitaID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, notifications.Unread)
+	require.Equal(t, 7, notifications.Notifications[0].CommentID)
+
+	require.Equal(t, ErrNotificationNotFound, notificationsService.MarkRead(strangerID, mention.ID))
+	require.Equal(t, nil, notificationsService.MarkRead(nikitaID, mention.ID))
+	notifications, err = notificationsService.Notifications(nikitaID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, notifications.Unread)
+
+	require.Equal(t, nil, notificationsService.MarkAllRead(nikitaID))
+	notifications, err = notificationsService.Notifications(nikitaID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, notifications.Unread)
+	log.Println("SUCCESS")
+}
+
+func TestMentionSameUsername(t *testing.T) {
+	securityManager := security.NewSimpleSecurityManager()
+	authorID := security.Hash("test@mail.ru")
+
+	userStorage := storage.NewUserCacheStorage(securityManager)
+	require.Equal(t, nil, userStorage.Save(entity.User{UserID: "a", Username: "sam", Email: "sam@mail.ru"}))
+	require.Equal(t, nil, userStorage.Save(entity.User{UserID: "b", Username: "sam", Email: "sam2@mail.ru"}))
+	usersNotesStorage := storage.NewUsersNotesStorage(storage.NewNotesStorage())
+	usersNotesStorage.AddLink("b", "1", entity.RoleViewer)
+	notificationsService := NewNotificationsApp(storage.NewNotificationsStorage(), storage.NewMentionsStorage(),
+		userStorage, usersNotesStorage)
+
+	// Only the sam who is a member of the note is mentioned.
+	require.Equal(t, nil, notificationsService.Mention(authorID, "1", 0, "@sam"))
+
+	notifications, err := notificationsService.Notifications("b")
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, notifications.Unread)
+	notifications, err = notificationsService.Notifications("a")
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, notifications.Unread)
+	log.Println("SUCCESS")
+}
@@ -0,0 +1,241 @@
+package presence
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/generator"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	packageName     = "app presence"
+	sessionIDLength = 8
+	// subscriberBuffer is how many events a subscriber may fall behind,
+	// newer events are dropped for it after that.
+	subscriberBuffer = 64
+)
+
+var ErrNoPresenceSession = errors.New("The presence session does not exist.")
+
+// PresenceApp keeps track of who has a note open and where their cursor is.
+// The state lives in memory only, it is rebuilt as clients reconnect.
+type PresenceApp struct {
+	usersNotesRepository repository.UsersNotesRepository
+
+	mu    sync.Mutex
+	notes map[string]*notePresence
+}
+
+type notePresence struct {
+	users       map[string]*entity.PresenceUser
+	subscribers map[chan entity.PresenceEvent]struct{}
+}
+
+func NewPresenceApp(usersNotesRepo repository.UsersNotesRepository) *PresenceApp {
+	return &PresenceApp{
+		usersNotesRepository: usersNotesRepo,
+		notes:                map[string]*notePresence{},
+	}
+}
+
+// Presence returns the users who have the note open.
+func (p *PresenceApp) Presence(userID string, noteToken string) (entity.Presence, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Presence",
+	})
+
+	if _, err := p.usersNotesRepository.Role(userID, noteToken); err != nil {
+		logger.Warning(err)
+		return entity.Presence{}, notes.ErrNoteAccess
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	presence := entity.Presence{Users: []entity.PresenceUser{}}
+	if note, ok := p.notes[noteToken]; ok {
+		for _, user := range note.users {
+			presence.Users = append(presence.Users, *user)
+		}
+	}
+	sort.Slice(presence.Users, func(i, j int) bool {
+		return presence.Users[i].SessionID < presence.Users[j].SessionID
+	})
+	return presence, nil
+}
+
+// Join marks the user as present on the note and returns the id of the new
+// presence session.
+func (p *PresenceApp) Join(noteToken string, userID string, username string) (string, error) {
+	sessionID, err := generator.RandSecureToken(sessionIDLength)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Join",
+		}).Error(err)
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user := &entity.PresenceUser{
+		SessionID: sessionID,
+		UserID:    userID,
+		Username:  username,
+		LastSeen:  time.Now(),
+	}
+	p.note(noteToken).users[sessionID] = user
+	p.publish(noteToken, entity.PresenceJoin, *user)
+	return sessionID, nil
+}
+
+// Heartbeat keeps the session from expiring.
+func (p *PresenceApp) Heartbeat(noteToken string, sessionID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user, err := p.user(noteToken, sessionID)
+	if err != nil {
+		return err
+	}
+	user.LastSeen = time.Now()
+	return nil
+}
+
+// MoveCursor stores the cursor of the session and tells the subscribers.
+func (p *PresenceApp) MoveCursor(noteToken string, sessionID string, cursor entity.Cursor) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user, err := p.user(noteToken, sessionID)
+	if err != nil {
+		return err
+	}
+	user.Cursor = &cursor
+	user.LastSeen = time.Now()
+	p.publish(noteToken, entity.PresenceCursor, *user)
+	return nil
+}
+
+// Leave removes the session from the note.
+func (p *PresenceApp) Leave(noteToken string, sessionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.leave(noteToken, sessionID)
+}
+
+// Subscribe returns the presence events of the note. The returned function
+// stops the subscription and closes the channel.
+func (p *PresenceApp) Subscribe(noteToken string) (<-chan entity.PresenceEvent, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := make(chan entity.PresenceEvent, subscriberBuffer)
+	p.note(noteToken).subscribers[events] = struct{}{}
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			if note, ok := p.notes[noteToken]; ok {
+				delete(note.subscribers, events)
+				p.cleanup(noteToken)
+			}
+			close(events)
+		})
+	}
+}
+
+// Expire removes the sessions that have not been seen for longer than
+// timeout.
+func (p *PresenceApp) Expire(timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	before := time.Now().Add(-timeout)
+	for noteToken, note := range p.notes {
+		for sessionID, user := range note.users {
+			if user.LastSeen.Before(before) {
+				p.leave(noteToken, sessionID)
+			}
+		}
+	}
+}
+
+// RunExpiry calls Expire every interval until stop is closed.
+func (p *PresenceApp) RunExpiry(interval time.Duration, timeout time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.Expire(timeout)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// The functions below are called with p.mu held.
+
+func (p *PresenceApp) note(noteToken string) *notePresence {
+	note, ok := p.notes[noteToken]
+	if !ok {
+		note = &notePresence{
+			users:       map[string]*entity.PresenceUser{},
+			subscribers: map[chan entity.PresenceEvent]struct{}{},
+		}
+		p.notes[noteToken] = note
+	}
+	return note
+}
+
+func (p *PresenceApp) user(noteToken string, sessionID string) (*entity.PresenceUser, error) {
+	note, ok := p.notes[noteToken]
+	if !ok {
+		return nil, ErrNoPresenceSession
+	}
+	user, ok := note.users[sessionID]
+	if !ok {
+		return nil, ErrNoPresenceSession
+	}
+	return user, nil
+}
+
+func (p *PresenceApp) leave(noteToken string, sessionID string) {
+	user, err := p.user(noteToken, sessionID)
+	if err != nil {
+		return
+	}
+	delete(p.notes[noteToken].users, sessionID)
+	p.publish(noteToken, entity.PresenceLeave, *user)
+	p.cleanup(noteToken)
+}
+
+// publish never blocks: a subscriber that does not keep up misses events.
+func (p *PresenceApp) publish(noteToken string, eventType string, user entity.PresenceUser) {
+	event := entity.PresenceEvent{Type: eventType, User: user}
+	for events := range p.notes[noteToken].subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+func (p *PresenceApp) cleanup(noteToken string) {
+	if note := p.notes[noteToken]; len(note.users) == 0 && len(note.subscribers) == 0 {
+		delete(p.notes, noteToken)
+	}
+}
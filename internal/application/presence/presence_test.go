@@ -0,0 +1,62 @@
+package presence
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestPresence(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	usersNotesStorage := storage.NewUsersNotesStorage(storage.NewNotesStorage())
+	presenceService := NewPresenceApp(usersNotesStorage)
+
+	events, stop := presenceService.Subscribe("1")
+
+	first, err := presenceService.Join("1", userID, "test")
+	require.Equal(t, nil, err)
+	second, err := presenceService.Join("1", userID, "test")
+	require.Equal(t, nil, err)
+	require.NotEqual(t, first, second)
+
+	require.Equal(t, nil, presenceService.MoveCursor("1", first, entity.Cursor{Anchor: 3, Head: 5}))
+	require.Equal(t, ErrNoPresenceSession, presenceService.MoveCursor("3", first, entity.Cursor{}))
+
+	require.Equal(t, entity.PresenceJoin, (<-events).Type)
+	require.Equal(t, entity.PresenceJoin, (<-events).Type)
+	event := <-events
+	require.Equal(t, entity.PresenceCursor, event.Type)
+	require.Equal(t, first, event.User.SessionID)
+	require.Equal(t, &entity.Cursor{Anchor: 3, Head: 5}, event.User.Cursor)
+
+	presence, err := presenceService.Presence(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(presence.Users))
+
+	_, err = presenceService.Presence(userID, "2")
+	require.Equal(t, notes.ErrNoteAccess, err)
+
+	presenceService.Leave("1", second)
+	event = <-events
+	require.Equal(t, entity.PresenceLeave, event.Type)
+	require.Equal(t, second, event.User.SessionID)
+
+	time.Sleep(10 * time.Millisecond)
+	presenceService.Expire(5 * time.Millisecond)
+	require.Equal(t, entity.PresenceLeave, (<-events).Type)
+
+	presence, err = presenceService.Presence(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, len(presence.Users))
+
+	stop()
+	_, ok := <-events
+	require.Equal(t, false, ok)
+	log.Println("SUCCESS")
+}
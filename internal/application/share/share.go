@@ -0,0 +1,174 @@
+package share
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/generator"
+	"cotion/internal/pkg/password"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	packageName      = "app share"
+	shareTokenLength = 32
+)
+
+var ErrShareLinkNotFound = errors.New("The share link does not exist or has expired.")
+var ErrShareLinkPassword = errors.New("Wrong password for the share link.")
+
+type ShareApp struct {
+	shareLinksRepository repository.ShareLinksRepository
+	usersNotesRepository repository.UsersNotesRepository
+	notesRepository      repository.NotesRepository
+	blocksRepository     repository.BlocksRepository
+}
+
+func NewShareApp(shareLinksRepo repository.ShareLinksRepository, usersNotesRepo repository.UsersNotesRepository,
+	notesRepo repository.NotesRepository, blocksRepo repository.BlocksRepository) *ShareApp {
+	return &ShareApp{
+		shareLinksRepository: shareLinksRepo,
+		usersNotesRepository: usersNotesRepo,
+		notesRepository:      notesRepo,
+		blocksRepository:     blocksRepo,
+	}
+}
+
+func (s *ShareApp) CreateLink(userID string, noteToken string, linkRequest entity.ShareLinkRequest) (entity.ShareLink, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateLink",
+	})
+
+	if err := s.checkEditor(userID, noteToken); err != nil {
+		logger.Warning(err)
+		return entity.ShareLink{}, err
+	}
+
+	shareToken, err := generator.RandSecureToken(shareTokenLength)
+	if err != nil {
+		logger.Error(err)
+		return entity.ShareLink{}, err
+	}
+
+	link := entity.ShareLink{
+		Token:     shareToken,
+		NoteToken: noteToken,
+		CreatedBy: userID,
+		ExpiresAt: linkRequest.ExpiresAt,
+	}
+	if linkRequest.Password != "" {
+		link.Password, err = password.Hash(linkRequest.Password)
+		if err != nil {
+			logger.Error(err)
+			return entity.ShareLink{}, err
+		}
+	}
+
+	if err := s.shareLinksRepository.Save(link); err != nil {
+		logger.Error(err)
+		return entity.ShareLink{}, err
+	}
+
+	link.Protected = link.Password != ""
+	return link, nil
+}
+
+func (s *ShareApp) Links(userID string, noteToken string) (entity.ShareLinks, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Links",
+	})
+
+	if err := s.checkEditor(userID, noteToken); err != nil {
+		logger.Warning(err)
+		return entity.ShareLinks{}, err
+	}
+
+	links, err := s.shareLinksRepository.AllByNote(noteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.ShareLinks{}, err
+	}
+	for i := range links {
+		links[i].Protected = links[i].Password != ""
+	}
+
+	return entity.ShareLinks{Links: links}, nil
+}
+
+func (s *ShareApp) RevokeLink(userID string, noteToken string, shareToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "RevokeLink",
+	})
+
+	if err := s.checkEditor(userID, noteToken); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	link, err := s.shareLinksRepository.Find(shareToken)
+	if err != nil || link.NoteToken != noteToken {
+		logger.Warning(ErrShareLinkNotFound)
+		return ErrShareLinkNotFound
+	}
+
+	return s.shareLinksRepository.Delete(shareToken)
+}
+
+// PublicNote returns the note behind a share link to anyone who knows it.
+func (s *ShareApp) PublicNote(shareToken string, linkPassword string) (entity.Note, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "PublicNote",
+	})
+
+	link, err := s.shareLinksRepository.Find(shareToken)
+	if err != nil || link.Expired() {
+		logger.Warning(ErrShareLinkNotFound)
+		return entity.Note{}, ErrShareLinkNotFound
+	}
+
+	if link.Password != "" {
+		if err := password.Compare(link.Password, linkPassword); err != nil {
+			logger.Warning(ErrShareLinkPassword)
+			return entity.Note{}, ErrShareLinkPassword
+		}
+	}
+
+	note, err := s.notesRepository.Find(link.NoteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Note{}, ErrShareLinkNotFound
+	}
+	if note.DeletedAt != nil {
+		logger.Warning(ErrShareLinkNotFound)
+		return entity.Note{}, ErrShareLinkNotFound
+	}
+
+	note.Blocks, err = s.blocksRepository.AllByNote(link.NoteToken)
+	if err != nil {
+		logger.Error(err)
+		return entity.Note{}, err
+	}
+
+	// Tokens of other notes and the members of the note are not exposed to
+	// anonymous readers.
+	note.Parent = ""
+	note.CreatedBy = ""
+	note.LastEditedBy = ""
+	return note, nil
+}
+
+func (s *ShareApp) checkEditor(userID string, noteToken string) error {
+	role, err := s.usersNotesRepository.Role(userID, noteToken)
+	if err != nil {
+		return notes.ErrNoteAccess
+	}
+	if !entity.RoleAllows(role, entity.RoleEditor) {
+		return notes.ErrNoteRole
+	}
+	return nil
+}
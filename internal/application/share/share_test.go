@@ -0,0 +1,57 @@
+package share
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestShareLinks(t *testing.T) {
+	ownerID := security.Hash("test@mail.ru")
+	past := time.Now().Add(-time.Hour)
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	shareLinksStorage := storage.NewShareLinksStorage()
+	shareService := NewShareApp(shareLinksStorage, usersNotesStorage, notesStorage, storage.NewBlocksStorage())
+
+	link, err := shareService.CreateLink(ownerID, "1", entity.ShareLinkRequest{})
+	require.Equal(t, nil, err)
+	require.Equal(t, 64, len(link.Token))
+
+	_, err = notesStorage.Update("1", entity.Note{Name: "1st note", LastEditedBy: ownerID})
+	require.Equal(t, nil, err)
+	note, err := shareService.PublicNote(link.Token, "")
+	require.Equal(t, nil, err)
+	require.Equal(t, "1st note", note.Name)
+	require.Equal(t, "", note.LastEditedBy)
+	require.Equal(t, "", note.CreatedBy)
+
+	protected, err := shareService.CreateLink(ownerID, "1", entity.ShareLinkRequest{Password: "secret"})
+	require.Equal(t, nil, err)
+	require.Equal(t, true, protected.Protected)
+	_, err = shareService.PublicNote(protected.Token, "wrong")
+	require.Equal(t, ErrShareLinkPassword, err)
+	_, err = shareService.PublicNote(protected.Token, "secret")
+	require.Equal(t, nil, err)
+
+	require.Equal(t, nil, shareLinksStorage.Save(entity.ShareLink{Token: "expired", NoteToken: "1", ExpiresAt: &past}))
+	_, err = shareService.PublicNote("expired", "")
+	require.Equal(t, ErrShareLinkNotFound, err)
+
+	links, err := shareService.Links(ownerID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, len(links.Links))
+
+	_, err = shareService.CreateLink(ownerID, "2", entity.ShareLinkRequest{})
+	require.Equal(t, notes.ErrNoteAccess, err)
+	require.Equal(t, ErrShareLinkNotFound, shareService.RevokeLink(ownerID, "3", link.Token))
+
+	require.Equal(t, nil, shareService.RevokeLink(ownerID, "1", link.Token))
+	_, err = shareService.PublicNote(link.Token, "")
+	require.Equal(t, ErrShareLinkNotFound, err)
+}
@@ -0,0 +1,159 @@
+package tags
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const packageName = "app tags"
+
+var ErrTagNotFound = errors.New("The tag does not exist.")
+var ErrTagExists = errors.New("The user already has a tag with this name.")
+
+type TagsApp struct {
+	tagsRepository       repository.TagsRepository
+	usersNotesRepository repository.UsersNotesRepository
+}
+
+func NewTagsApp(tagsRepo repository.TagsRepository, usersNotesRepo repository.UsersNotesRepository) *TagsApp {
+	return &TagsApp{
+		tagsRepository:       tagsRepo,
+		usersNotesRepository: usersNotesRepo,
+	}
+}
+
+func (t *TagsApp) Tags(userID string) (entity.Tags, error) {
+	tags, err := t.tagsRepository.AllByUserID(userID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Tags",
+		}).Error(err)
+		return entity.Tags{}, err
+	}
+	return entity.Tags{Tags: tags}, nil
+}
+
+func (t *TagsApp) CreateTag(userID string, tagRequest entity.TagRequest) (entity.Tag, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateTag",
+	})
+
+	if err := t.checkName(userID, 0, tagRequest.Name); err != nil {
+		logger.Warning(err)
+		return entity.Tag{}, err
+	}
+
+	tag := entity.Tag{
+		UserID: userID,
+		Name:   tagRequest.Name,
+		Color:  tagRequest.Color,
+	}
+	tagID, err := t.tagsRepository.Save(tag)
+	if err != nil {
+		logger.Error(err)
+		return entity.Tag{}, err
+	}
+	tag.ID = tagID
+
+	return tag, nil
+}
+
+func (t *TagsApp) UpdateTag(userID string, tagID int, tagRequest entity.TagRequest) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateTag",
+	})
+
+	tag, err := t.findTag(userID, tagID)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+	if err := t.checkName(userID, tagID, tagRequest.Name); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	tag.Name = tagRequest.Name
+	tag.Color = tagRequest.Color
+	return t.tagsRepository.Update(tag)
+}
+
+func (t *TagsApp) DeleteTag(userID string, tagID int) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteTag",
+	})
+
+	if _, err := t.findTag(userID, tagID); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	return t.tagsRepository.Delete(tagID)
+}
+
+func (t *TagsApp) AttachTag(userID string, noteToken string, tagID int) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AttachTag",
+	})
+
+	if err := t.checkNote(userID, noteToken, tagID); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	return t.tagsRepository.Attach(tagID, noteToken)
+}
+
+func (t *TagsApp) DetachTag(userID string, noteToken string, tagID int) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DetachTag",
+	})
+
+	if err := t.checkNote(userID, noteToken, tagID); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	return t.tagsRepository.Detach(tagID, noteToken)
+}
+
+// findTag hides tags of other users behind ErrTagNotFound.
+func (t *TagsApp) findTag(userID string, tagID int) (entity.Tag, error) {
+	tag, err := t.tagsRepository.Find(tagID)
+	if err != nil || tag.UserID != userID {
+		return entity.Tag{}, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+func (t *TagsApp) checkName(userID string, tagID int, name string) error {
+	tags, err := t.tagsRepository.AllByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if tag.Name == name && tag.ID != tagID {
+			return ErrTagExists
+		}
+	}
+	return nil
+}
+
+// checkNote allows tagging any note the user has access to, since tags
+// are visible only to their owner.
+func (t *TagsApp) checkNote(userID string, noteToken string, tagID int) error {
+	if !t.usersNotesRepository.CheckLink(userID, noteToken) {
+		return notes.ErrNoteAccess
+	}
+	_, err := t.findTag(userID, tagID)
+	return err
+}
@@ -0,0 +1,109 @@
+package tags
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/events"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	tagsStorage := storage.NewTagsStorage()
+	tagsService := NewTagsApp(tagsStorage, usersNotesStorage)
+
+	work, err := tagsService.CreateTag(userID, entity.TagRequest{Name: "work", Color: "#ff0000"})
+	require.Equal(t, nil, err)
+	_, err = tagsService.CreateTag(userID, entity.TagRequest{Name: "work", Color: "#00ff00"})
+	require.Equal(t, ErrTagExists, err)
+	home, err := tagsService.CreateTag(userID, entity.TagRequest{Name: "home", Color: "#00ff00"})
+	require.Equal(t, nil, err)
+
+	require.Equal(t, ErrTagNotFound, tagsService.UpdateTag(otherID, work.ID, entity.TagRequest{Name: "mine", Color: "#000000"}))
+	require.Equal(t, ErrTagExists, tagsService.UpdateTag(userID, work.ID, entity.TagRequest{Name: "home", Color: "#000000"}))
+	require.Equal(t, nil, tagsService.UpdateTag(userID, work.ID, entity.TagRequest{Name: "job", Color: "#000000"}))
+
+	tags, err := tagsService.Tags(userID)
+	require.Equal(t, nil, err)
+	require.Equal(t, entity.Tags{Tags: []entity.Tag{
+		{ID: home.ID, UserID: userID, Name: "home", Color: "#00ff00"},
+		{ID: work.ID, UserID: userID, Name: "job", Color: "#000000"},
+	}}, tags)
+
+	require.Equal(t, ErrTagNotFound, tagsService.DeleteTag(otherID, home.ID))
+	require.Equal(t, nil, tagsService.DeleteTag(userID, home.ID))
+	log.Println("SUCCESS")
+}
+
+func TestFilterByTags(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	tagsStorage := storage.NewTagsStorage()
+	tagsService := NewTagsApp(tagsStorage, usersNotesStorage)
+	notesService := notes.NewNotesApp(notes.Dependencies{
+		Notes:       notesStorage,
+		UsersNotes:  usersNotesStorage,
+		Blocks:      storage.NewBlocksStorage(),
+		Revisions:   storage.NewRevisionsStorage(),
+		Tags:        tagsStorage,
+		NoteLinks:   storage.NewNoteLinksStorage(notesStorage),
+		Attachments: storage.NewAttachmentsStorage(),
+		Uploads:     storage.NewUploadsStorage(),
+		EventBus:    events.NewBus(),
+	})
+
+	work, _ := tagsService.CreateTag(userID, entity.TagRequest{Name: "work", Color: "#ff0000"})
+	urgent, _ := tagsService.CreateTag(userID, entity.TagRequest{Name: "urgent", Color: "#00ff00"})
+
+	require.Equal(t, notes.ErrNoteAccess, tagsService.AttachTag(userID, "2", work.ID))
+	require.Equal(t, nil, tagsService.AttachTag(userID, "1", work.ID))
+	require.Equal(t, nil, tagsService.AttachTag(userID, "1", urgent.ID))
+	require.Equal(t, nil, tagsService.AttachTag(userID, "3", work.ID))
+
+	cases := map[string]struct {
+		inFilter entity.NotesFilter
+		expected []string
+	}{
+		"Any": {
+			inFilter: entity.NotesFilter{Tags: []int{work.ID, urgent.ID}, Match: entity.TagsMatchAny},
+			expected: []string{"1", "3"},
+		},
+		"All": {
+			inFilter: entity.NotesFilter{Tags: []int{work.ID, urgent.ID}, Match: entity.TagsMatchAll},
+			expected: []string{"1"},
+		},
+		"No tags": {
+			inFilter: entity.NotesFilter{},
+			expected: []string{"1", "3"},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			result, err := notesService.AllNotesByUserID(userID, tc.inFilter)
+			require.Equal(t, nil, err)
+			var tokens []string
+			for _, note := range result.ShortNote {
+				tokens = append(tokens, note.Token)
+			}
+			require.Equal(t, tc.expected, tokens)
+		})
+		log.Println("SUCCESS")
+	}
+
+	require.Equal(t, nil, tagsService.DetachTag(userID, "1", urgent.ID))
+	result, err := notesService.AllNotesByUserID(userID, entity.NotesFilter{Tags: []int{urgent.ID}})
+	require.Equal(t, nil, err)
+	require.Equal(t, 0, len(result.ShortNote))
+}
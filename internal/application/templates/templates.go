@@ -0,0 +1,224 @@
+package templates
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/application/tags"
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	packageName = "app templates"
+
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+)
+
+var ErrTemplateNotFound = errors.New("The template does not exist.")
+var ErrBuiltInTemplate = errors.New("Built-in templates cannot be changed.")
+
+type TemplatesApp struct {
+	templatesRepository repository.TemplatesRepository
+	tagsRepository      repository.TagsRepository
+	notesApp            *notes.NotesApp
+}
+
+func NewTemplatesApp(templatesRepo repository.TemplatesRepository, tagsRepo repository.TagsRepository,
+	notesApp *notes.NotesApp) *TemplatesApp {
+	return &TemplatesApp{
+		templatesRepository: templatesRepo,
+		tagsRepository:      tagsRepo,
+		notesApp:            notesApp,
+	}
+}
+
+// Templates returns the built-in templates and then the user's own.
+func (t *TemplatesApp) Templates(userID string) (entity.Templates, error) {
+	templates, err := t.templatesRepository.AllByUserID(userID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Templates",
+		}).Error(err)
+		return entity.Templates{}, err
+	}
+
+	for i := range templates {
+		templates[i].BuiltIn = templates[i].UserID == ""
+	}
+	if templates == nil {
+		templates = []entity.Template{}
+	}
+	return entity.Templates{Templates: templates}, nil
+}
+
+func (t *TemplatesApp) CreateTemplate(userID string, templateRequest entity.TemplateRequest) (entity.Template, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateTemplate",
+	})
+
+	if err := t.checkTags(userID, templateRequest.Tags); err != nil {
+		logger.Warning(err)
+		return entity.Template{}, err
+	}
+
+	template := entity.Template{
+		UserID: userID,
+		Name:   templateRequest.Name,
+		Body:   templateRequest.Body,
+		Tags:   templateRequest.Tags,
+	}
+	if template.Tags == nil {
+		template.Tags = []int{}
+	}
+
+	templateID, err := t.templatesRepository.Save(template)
+	if err != nil {
+		logger.Error(err)
+		return entity.Template{}, err
+	}
+	template.ID = templateID
+
+	return template, nil
+}
+
+func (t *TemplatesApp) UpdateTemplate(userID string, templateID int, templateRequest entity.TemplateRequest) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateTemplate",
+	})
+
+	template, err := t.ownTemplate(userID, templateID)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+	if err := t.checkTags(userID, templateRequest.Tags); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	template.Name = templateRequest.Name
+	template.Body = templateRequest.Body
+	template.Tags = templateRequest.Tags
+	if template.Tags == nil {
+		template.Tags = []int{}
+	}
+	return t.templatesRepository.Update(template)
+}
+
+func (t *TemplatesApp) DeleteTemplate(userID string, templateID int) error {
+	if _, err := t.ownTemplate(userID, templateID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "DeleteTemplate",
+		}).Warning(err)
+		return err
+	}
+	return t.templatesRepository.Delete(templateID)
+}
+
+// SaveNoteAsTemplate makes a template of the name, body and the user's tags
+// of the note.
+func (t *TemplatesApp) SaveNoteAsTemplate(userID string, noteToken string) (entity.Template, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "SaveNoteAsTemplate",
+	})
+
+	note, err := t.notesApp.GetNote(userID, noteToken)
+	if err != nil {
+		return entity.Template{}, err
+	}
+	notesTags, err := t.tagsRepository.NotesTags(userID)
+	if err != nil {
+		logger.Error(err)
+		return entity.Template{}, err
+	}
+
+	templateRequest := entity.TemplateRequest{Name: note.Name, Body: note.Body, Tags: []int{}}
+	for _, tag := range notesTags[noteToken] {
+		templateRequest.Tags = append(templateRequest.Tags, tag.ID)
+	}
+	if err := templateRequest.Validate(); err != nil {
+		logger.Warning(err)
+		return entity.Template{}, err
+	}
+
+	return t.CreateTemplate(userID, templateRequest)
+}
+
+// CreateNote creates a note from the template with the variables in its
+// name and body expanded. The name and the parent of the request, if set,
+// are used instead of the template's. Tags of the template that the user
+// has deleted since are skipped.
+func (t *TemplatesApp) CreateNote(userID string, username string, templateID int, noteRequest entity.NoteRequest) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateNote",
+	})
+
+	template, err := t.findTemplate(userID, templateID)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	now := time.Now()
+	variables := strings.NewReplacer(
+		entity.TemplateDate, now.Format(dateLayout),
+		entity.TemplateTime, now.Format(timeLayout),
+		entity.TemplateUsername, username,
+	)
+
+	if noteRequest.Name == "" {
+		noteRequest.Name = variables.Replace(template.Name)
+	}
+	noteRequest.Body = variables.Replace(template.Body)
+	noteRequest.Tags = nil
+	for _, tagID := range template.Tags {
+		if tag, err := t.tagsRepository.Find(tagID); err == nil && tag.UserID == userID {
+			noteRequest.Tags = append(noteRequest.Tags, tagID)
+		}
+	}
+	if err := noteRequest.Validate(); err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	return t.notesApp.SaveNote(userID, noteRequest)
+}
+
+// findTemplate returns the template if it is built in or the user's own.
+func (t *TemplatesApp) findTemplate(userID string, templateID int) (entity.Template, error) {
+	template, err := t.templatesRepository.Find(templateID)
+	if err != nil || (template.UserID != "" && template.UserID != userID) {
+		return entity.Template{}, ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+func (t *TemplatesApp) ownTemplate(userID string, templateID int) (entity.Template, error) {
+	template, err := t.findTemplate(userID, templateID)
+	if err != nil {
+		return entity.Template{}, err
+	}
+	if template.UserID == "" {
+		return entity.Template{}, ErrBuiltInTemplate
+	}
+	return template, nil
+}
+
+func (t *TemplatesApp) checkTags(userID string, tagIDs []int) error {
+	for _, tagID := range tagIDs {
+		if tag, err := t.tagsRepository.Find(tagID); err != nil || tag.UserID != userID {
+			return tags.ErrTagNotFound
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package templates
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/infrastructure/storage"
+	"cotion/internal/pkg/events"
+	"cotion/internal/pkg/security"
+	"github.com/stretchr/testify/require"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTemplatesApp() (*TemplatesApp, *notes.NotesApp, *storage.TagsStorage) {
+	notesStorage := storage.NewNotesStorage()
+	usersNotesStorage := storage.NewUsersNotesStorage(notesStorage)
+	tagsStorage := storage.NewTagsStorage()
+	notesService := notes.NewNotesApp(notes.Dependencies{
+		Notes:       notesStorage,
+		UsersNotes:  usersNotesStorage,
+		Blocks:      storage.NewBlocksStorage(),
+		Revisions:   storage.NewRevisionsStorage(),
+		Tags:        tagsStorage,
+		NoteLinks:   storage.NewNoteLinksStorage(notesStorage),
+		Attachments: storage.NewAttachmentsStorage(),
+		Uploads:     storage.NewUploadsStorage(),
+		EventBus:    events.NewBus(),
+	})
+	return NewTemplatesApp(storage.NewTemplatesStorage(), tagsStorage, notesService), notesService, tagsStorage
+}
+
+func TestTemplates(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+	templatesService, _, tagsStorage := newTemplatesApp()
+
+	tagID, _ := tagsStorage.Save(entity.Tag{UserID: userID, Name: "work", Color: "#ff0000"})
+	otherTagID, _ := tagsStorage.Save(entity.Tag{UserID: otherID, Name: "home", Color: "#00ff00"})
+
+	_, err := templatesService.CreateTemplate(userID, entity.TemplateRequest{Name: "Daily", Tags: []int{otherTagID}})
+	require.NotEqual(t, nil, err)
+	daily, err := templatesService.CreateTemplate(userID, entity.TemplateRequest{Name: "Daily", Body: "Today", Tags: []int{tagID}})
+	require.Equal(t, nil, err)
+
+	result, err := templatesService.Templates(userID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, len(result.Templates))
+	require.Equal(t, true, result.Templates[0].BuiltIn)
+	require.Equal(t, true, result.Templates[1].BuiltIn)
+	require.Equal(t, daily.ID, result.Templates[2].ID)
+	require.Equal(t, false, result.Templates[2].BuiltIn)
+
+	result, err = templatesService.Templates(otherID)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(result.Templates))
+
+	builtInID := result.Templates[0].ID
+	require.Equal(t, ErrBuiltInTemplate, templatesService.UpdateTemplate(userID, builtInID, entity.TemplateRequest{Name: "Mine"}))
+	require.Equal(t, ErrBuiltInTemplate, templatesService.DeleteTemplate(userID, builtInID))
+	require.Equal(t, ErrTemplateNotFound, templatesService.UpdateTemplate(otherID, daily.ID, entity.TemplateRequest{Name: "Mine"}))
+	require.Equal(t, ErrTemplateNotFound, templatesService.DeleteTemplate(otherID, daily.ID))
+	require.Equal(t, nil, templatesService.UpdateTemplate(userID, daily.ID, entity.TemplateRequest{Name: "Weekly", Body: "This week"}))
+	require.Equal(t, nil, templatesService.DeleteTemplate(userID, daily.ID))
+	require.Equal(t, ErrTemplateNotFound, templatesService.DeleteTemplate(userID, daily.ID))
+	log.Println("SUCCESS")
+}
+
+func TestCreateNote(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	otherID := security.Hash("nikita@mail.ru")
+	templatesService, notesService, tagsStorage := newTemplatesApp()
+
+	tagID, _ := tagsStorage.Save(entity.Tag{UserID: userID, Name: "work", Color: "#ff0000"})
+	standup, err := templatesService.CreateTemplate(userID, entity.TemplateRequest{
+		Name: "Standup {{date}}",
+		Body: "Written by {{username}} at {{time}}",
+		Tags: []int{tagID},
+	})
+	require.Equal(t, nil, err)
+
+	require.Equal(t, ErrTemplateNotFound, templatesService.CreateNote(otherID, "nikita", standup.ID, entity.NoteRequest{}))
+	require.Equal(t, nil, templatesService.CreateNote(userID, "test", standup.ID, entity.NoteRequest{}))
+
+	result, err := notesService.AllNotesByUserID(userID, entity.NotesFilter{Tags: []int{tagID}})
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, len(result.ShortNote))
+	require.Equal(t, "Standup "+time.Now().Format(dateLayout), result.ShortNote[0].Name)
+
+	note, err := notesService.GetNote(userID, result.ShortNote[0].Token)
+	require.Equal(t, nil, err)
+	require.Equal(t, true, strings.HasPrefix(note.Body, "Written by test at "))
+	require.Equal(t, false, strings.Contains(note.Body, "{{"))
+
+	require.Equal(t, nil, templatesService.CreateNote(userID, "test", standup.ID, entity.NoteRequest{Name: "Planning"}))
+	result, err = notesService.AllNotesByUserID(userID, entity.NotesFilter{Tags: []int{tagID}})
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(result.ShortNote))
+	log.Println("SUCCESS")
+}
+
+func TestSaveNoteAsTemplate(t *testing.T) {
+	userID := security.Hash("test@mail.ru")
+	templatesService, _, tagsStorage := newTemplatesApp()
+
+	tagID, _ := tagsStorage.Save(entity.Tag{UserID: userID, Name: "work", Color: "#ff0000"})
+	require.Equal(t, nil, tagsStorage.Attach(tagID, "1"))
+
+	_, err := templatesService.SaveNoteAsTemplate(userID, "2")
+	require.Equal(t, notes.ErrNoteAccess, err)
+
+	template, err := templatesService.SaveNoteAsTemplate(userID, "1")
+	require.Equal(t, nil, err)
+	require.Equal(t, "1st note", template.Name)
+	require.Equal(t, []int{tagID}, template.Tags)
+	log.Println("SUCCESS")
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// MaxFileNameLength is the length of the name an attachment is downloaded
+// with.
+const MaxFileNameLength = 255
+
+// Attachment is a file uploaded to a note. The file itself is kept in the
+// object storage under ObjectName.
+type Attachment struct {
+	ID          int       `json:"id"`
+	NoteToken   string    `json:"-"`
+	ObjectName  string    `json:"-"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Attachments struct {
+	Attachments []Attachment `json:"attachments"`
+}
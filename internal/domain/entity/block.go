@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"cotion/internal/pkg/contains"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const (
+	BlockText    = "text"
+	BlockHeading = "heading"
+	BlockTodo    = "todo"
+	BlockCode    = "code"
+	BlockImage   = "image"
+
+	MaxBlockContentLength = 10000
+)
+
+var BlockTypes = []string{BlockText, BlockHeading, BlockTodo, BlockCode, BlockImage}
+
+var ErrUnknownBlockType = errors.New("unknown block type")
+var ErrBlockContentLengthExceedsLimit = errors.New("block content length exceeds limit")
+
+type Block struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Position int             `json:"position"`
+	Content  json.RawMessage `json:"content"`
+}
+
+type BlockRequest struct {
+	Type     string          `json:"type"`
+	Position *int            `json:"position"`
+	Content  json.RawMessage `json:"content"`
+}
+
+func (b *BlockRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		return err
+	}
+
+	return b.Validate()
+}
+
+func (b *BlockRequest) Validate() error {
+	if !contains.Contains(BlockTypes, b.Type) {
+		return ErrUnknownBlockType
+	}
+	if len(b.Content) == 0 {
+		b.Content = json.RawMessage("{}")
+	}
+	if len(b.Content) > MaxBlockContentLength {
+		return ErrBlockContentLengthExceedsLimit
+	}
+	return nil
+}
+
+type MoveBlockRequest struct {
+	Position int `json:"position"`
+}
+
+func (m *MoveBlockRequest) Bind(r *http.Request) error {
+	return json.NewDecoder(r.Body).Decode(&m)
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "cotion/internal/pkg/ot"
+
+// Types of the messages of a collaborative editing session.
+const (
+	// CollabInit is the first message a client gets: the current name and
+	// body of the note, the revision they are at and the id of the
+	// client's presence session.
+	CollabInit = "init"
+	// CollabOperation carries a change of the body. Clients send it with
+	// the revision it is based on, the server forwards it to the other
+	// clients with the revision it has created.
+	CollabOperation = "operation"
+	// CollabAck confirms the client's own operation and gives its revision.
+	CollabAck = "ack"
+	// CollabName renames the note, the last rename wins.
+	CollabName = "name"
+	// CollabCursor moves the client's cursor.
+	CollabCursor = "cursor"
+	// CollabHeartbeat keeps the client present while it is idle, it has to
+	// be sent more often than PresenceTimeout. Editors send the revision
+	// they are at with it, so that older operations can be forgotten.
+	CollabHeartbeat = "heartbeat"
+	// CollabPresence forwards a presence event to the clients.
+	CollabPresence = "presence"
+	CollabError    = "error"
+)
+
+type CollabMessage struct {
+	Type      string         `json:"type"`
+	Revision  int            `json:"revision"`
+	Operation ot.Operation   `json:"operation,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Body      string         `json:"body,omitempty"`
+	UserID    string         `json:"userID,omitempty"`
+	SessionID string         `json:"sessionID,omitempty"`
+	Cursor    *Cursor        `json:"cursor,omitempty"`
+	Presence  *PresenceEvent `json:"presence,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const MaxCommentLength = 2000
+
+var ErrEmptyComment = errors.New("comment is empty")
+var ErrCommentLengthExceedsLimit = errors.New("comment length exceeds limit")
+var ErrInvalidCommentAnchor = errors.New("comment anchor must be a range from <= to")
+
+// CommentAnchor ties a comment to a range of the body, or of a block if
+// BlockID is set.
+type CommentAnchor struct {
+	BlockID string `json:"blockID,omitempty"`
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+}
+
+// Comment is either the start of a thread or, if ParentID is set, a reply
+// in the thread.
+type Comment struct {
+	ID        int            `json:"id"`
+	NoteToken string         `json:"-"`
+	ParentID  int            `json:"parentID,omitempty"`
+	UserID    string         `json:"userID"`
+	Body      string         `json:"body"`
+	Anchor    *CommentAnchor `json:"anchor,omitempty"`
+	Resolved  bool           `json:"resolved"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Replies   []Comment      `json:"replies,omitempty"`
+}
+
+type Comments struct {
+	Comments []Comment `json:"comments"`
+}
+
+type CommentRequest struct {
+	Body     string         `json:"body"`
+	ParentID int            `json:"parentID"`
+	Anchor   *CommentAnchor `json:"anchor"`
+}
+
+func (c *CommentRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		return err
+	}
+
+	return c.Validate()
+}
+
+func (c *CommentRequest) Validate() error {
+	if strings.TrimSpace(c.Body) == "" {
+		return ErrEmptyComment
+	}
+	if len(c.Body) > MaxCommentLength {
+		return ErrCommentLengthExceedsLimit
+	}
+	if c.Anchor != nil && (c.Anchor.From < 0 || c.Anchor.From > c.Anchor.To) {
+		return ErrInvalidCommentAnchor
+	}
+	return nil
+}
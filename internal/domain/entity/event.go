@@ -0,0 +1,20 @@
+package entity
+
+// Types of note events.
+const (
+	NoteCreated = "created"
+	NoteUpdated = "updated"
+	NoteDeleted = "deleted"
+	NoteShared  = "shared"
+)
+
+// NoteEvent is a change of a note that is shown in the sidebar. It is
+// delivered to the users in UserIDs.
+type NoteEvent struct {
+	Type      string   `json:"type"`
+	NoteToken string   `json:"token"`
+	Name      string   `json:"name,omitempty"`
+	Parent    string   `json:"parent,omitempty"`
+	UserID    string   `json:"userID,omitempty"`
+	UserIDs   []string `json:"-"`
+}
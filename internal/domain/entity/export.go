@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+const (
+	ExportMarkdown = "md"
+
+	MarkdownContentType = "text/markdown; charset=utf-8"
+)
+
+var MarkdownExtensions = []string{".md", ".markdown"}
+
+// ExportedNote is a note rendered to a file.
+type ExportedNote struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+type ImportedNotes struct {
+	Names []string `json:"notes"`
+}
+
+// ExportManifest describes a workspace archive: who it belongs to and, for
+// every note, where its files are, where it is in the tree and who it is
+// shared with.
+type ExportManifest struct {
+	ExportedAt time.Time            `json:"exported_at"`
+	User       ExportUser           `json:"user"`
+	Avatar     string               `json:"avatar,omitempty"`
+	Notes      []ExportManifestNote `json:"notes"`
+}
+
+type ExportUser struct {
+	UserID   string `json:"userID"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type ExportManifestNote struct {
+	Token      string      `json:"token"`
+	Name       string      `json:"name"`
+	Parent     string      `json:"parent,omitempty"`
+	Role       string      `json:"role"`
+	Markdown   string      `json:"markdown"`
+	JSON       string      `json:"json"`
+	Members    []Member    `json:"members"`
+	ShareLinks []ShareLink `json:"share_links"`
+}
@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"cotion/internal/pkg/contains"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	TagsMatchAny = "any"
+	TagsMatchAll = "all"
+
+	SortPosition = "position"
+	SortName     = "name"
+	SortCreated  = "created"
+	SortUpdated  = "updated"
+
+	BodyFull    = "full"
+	BodyPreview = "preview"
+	BodyNone    = "none"
+
+	BodyPreviewLength = 100
+	MaxNotesLimit     = 100
+)
+
+var NotesSorts = []string{SortPosition, SortName, SortCreated, SortUpdated}
+var BodyModes = []string{BodyFull, BodyPreview, BodyNone}
+
+var ErrInvalidTagID = errors.New("tag id must be a number")
+var ErrUnknownTagsMatch = errors.New("tags match must be any or all")
+var ErrUnknownSort = errors.New("notes can be sorted by position, name, created or updated")
+var ErrUnknownOrder = errors.New("order must be asc or desc")
+var ErrUnknownBodyMode = errors.New("body must be full, preview or none")
+var ErrInvalidLimit = errors.New("limit must be a number from 1 to 100")
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NotesFilter narrows, orders and pages the notes list. Tags are resolved
+// to note tokens before the list is queried. A zero Limit means no limit.
+type NotesFilter struct {
+	Tags      []int
+	Match     string
+	Favorites bool
+	Tokens    []string
+	Sort      string
+	Desc      bool
+	Body      string
+	Limit     int
+	Cursor    string
+}
+
+func (f *NotesFilter) Bind(r *http.Request) error {
+	query := r.URL.Query()
+	seen := make(map[int]bool)
+	for _, value := range query["tag"] {
+		tagID, err := strconv.Atoi(value)
+		if err != nil {
+			return ErrInvalidTagID
+		}
+		if !seen[tagID] {
+			seen[tagID] = true
+			f.Tags = append(f.Tags, tagID)
+		}
+	}
+
+	f.Favorites = query.Get("favorite") == "true"
+	f.Match = query.Get("match")
+	f.Sort = query.Get("sort")
+	f.Body = query.Get("body")
+	f.Cursor = query.Get("cursor")
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		f.Desc = true
+	default:
+		return ErrUnknownOrder
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return ErrInvalidLimit
+		}
+		f.Limit = limit
+		if f.Limit < 1 || f.Limit > MaxNotesLimit {
+			return ErrInvalidLimit
+		}
+	}
+
+	return f.Validate()
+}
+
+// Validate fills in the defaults and checks the enumerated options.
+func (f *NotesFilter) Validate() error {
+	if f.Match == "" {
+		f.Match = TagsMatchAny
+	}
+	if f.Match != TagsMatchAny && f.Match != TagsMatchAll {
+		return ErrUnknownTagsMatch
+	}
+	if f.Sort == "" {
+		f.Sort = SortPosition
+	}
+	if !contains.Contains(NotesSorts, f.Sort) {
+		return ErrUnknownSort
+	}
+	if f.Body == "" {
+		f.Body = BodyFull
+	}
+	if !contains.Contains(BodyModes, f.Body) {
+		return ErrUnknownBodyMode
+	}
+	return nil
+}
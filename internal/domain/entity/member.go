@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"cotion/internal/pkg/email"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const (
+	RoleOwner     = "owner"
+	RoleEditor    = "editor"
+	RoleCommenter = "commenter"
+	RoleViewer    = "viewer"
+)
+
+// roleRanks orders roles so that every role is allowed to do
+// everything the roles below it can.
+var roleRanks = map[string]int{
+	RoleViewer:    1,
+	RoleCommenter: 2,
+	RoleEditor:    3,
+	RoleOwner:     4,
+}
+
+var ErrUnknownRole = errors.New("unknown role")
+
+func IsRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows reports whether a member with the role may perform
+// an action that requires the required role.
+func RoleAllows(role string, required string) bool {
+	return IsRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+type Member struct {
+	UserID   string `json:"userID"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+type Members struct {
+	Members []Member `json:"members"`
+}
+
+type MemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+func (m *MemberRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		return err
+	}
+
+	return m.Validate()
+}
+
+func (m *MemberRequest) Validate() error {
+	if err := email.ValidateEmail(m.Email); err != nil {
+		return err
+	}
+	if !IsRole(m.Role) {
+		return ErrUnknownRole
+	}
+	return nil
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+func (rr *RoleRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&rr); err != nil {
+		return err
+	}
+
+	if !IsRole(rr.Role) {
+		return ErrUnknownRole
+	}
+	return nil
+}
@@ -1,22 +1,56 @@
 package entity
 
 import (
+	"cotion/internal/pkg/email"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
 	MaxNameLength = 30
-	MaxBodyLength = 500
+	// MaxBodyLength is in bytes. It is far beyond what is written by hand
+	// and keeps imported documents, revisions and diffs of a note bounded.
+	MaxBodyLength = 1 << 20
+
+	// IconEmoji icons are shown as is, IconImage icons are uploaded images
+	// that are downloaded from the note.
+	IconEmoji = "emoji"
+	IconImage = "image"
+
+	// MaxEmojiLength is the length of an emoji icon in characters, enough
+	// for sequences of emoji joined into one.
+	MaxEmojiLength = 10
 )
 
 var ErrNoteNameLengthExceedsLimit error = errors.New("note name length exceeds limit")
-var ErrNoteBodyLengthExceedsLimit error = errors.New("note name length exceeds limit")
+var ErrNoteBodyLengthExceedsLimit error = errors.New("note body length exceeds limit")
+var ErrNoteVersionConflict error = errors.New("note has been changed since this version")
+var ErrNoteCycle error = errors.New("note cannot be moved into its own subtree")
+var ErrInvalidEmoji error = errors.New("icon is not an emoji")
 
 type Note struct {
-	Name string `json:"name"`
-	Body string `json:"body"`
+	Name         string     `json:"name"`
+	Body         string     `json:"body"`
+	Parent       string     `json:"parent"`
+	Icon         *NoteIcon  `json:"icon,omitempty"`
+	Cover        string     `json:"cover,omitempty"`
+	Blocks       []Block    `json:"blocks,omitempty"`
+	Version      int        `json:"version"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CreatedBy    string     `json:"created_by"`
+	LastEditedBy string     `json:"last_edited_by"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+// NoteVersion is sent back when an update is based on a stale version.
+type NoteVersion struct {
+	Version int `json:"version"`
 }
 
 type Notes struct {
@@ -24,19 +58,43 @@ type Notes struct {
 }
 
 type ShortNote struct {
-	Name string `json:"name"`
-	//Favicon string `json:"favicon"`
-	Body  string `json:"body"`
-	Token string `json:"token"`
+	Name         string    `json:"name"`
+	Icon         *NoteIcon `json:"icon,omitempty"`
+	Body         string    `json:"body"`
+	Token        string    `json:"token"`
+	Parent       string    `json:"parent"`
+	Pinned       bool      `json:"pinned"`
+	Favorite     bool      `json:"favorite"`
+	Tags         []Tag     `json:"tags,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	CreatedBy    string    `json:"created_by"`
+	LastEditedBy string    `json:"last_edited_by"`
 }
 
 type ShortNotes struct {
-	ShortNote []ShortNote `json:"notes"`
+	ShortNote  []ShortNote `json:"notes"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+type NoteTreeItem struct {
+	Name     string         `json:"name"`
+	Token    string         `json:"token"`
+	Children []NoteTreeItem `json:"children"`
 }
 
+type NotesTree struct {
+	Notes []NoteTreeItem `json:"notes"`
+}
+
+// NoteRequest creates or updates a note. Tags are only attached to new
+// notes.
 type NoteRequest struct {
-	Name string `json:"name"`
-	Body string `json:"body"`
+	Name   string `json:"name"`
+	Body   string `json:"body"`
+	Parent string `json:"parent"`
+	Tags   []int  `json:"tags,omitempty"`
 }
 
 func (n *NoteRequest) Bind(r *http.Request) error {
@@ -51,8 +109,94 @@ func (n *NoteRequest) Validate() error {
 	if len(n.Name) > MaxNameLength {
 		return ErrNoteNameLengthExceedsLimit
 	}
-	if len(n.Name) > MaxBodyLength {
+	if len(n.Body) > MaxBodyLength {
 		return ErrNoteBodyLengthExceedsLimit
 	}
 	return nil
 }
+
+// NoteIcon is an emoji or the name of an uploaded image.
+type NoteIcon struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// NewNoteIcon returns nil for a note without an icon.
+func NewNoteIcon(iconType string, value string) *NoteIcon {
+	if iconType == "" {
+		return nil
+	}
+	return &NoteIcon{Type: iconType, Value: value}
+}
+
+type IconRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+func (i *IconRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&i); err != nil {
+		return err
+	}
+
+	return i.Validate()
+}
+
+func (i *IconRequest) Validate() error {
+	length := utf8.RuneCountInString(i.Emoji)
+	if length == 0 || length > MaxEmojiLength || !utf8.ValidString(i.Emoji) {
+		return ErrInvalidEmoji
+	}
+	if strings.IndexFunc(i.Emoji, notEmoji) != -1 {
+		return ErrInvalidEmoji
+	}
+	return nil
+}
+
+// notEmoji reports whether the character cannot be part of an emoji. Of
+// ASCII only the digits, '#' and '*' can, as the base of keycap emoji.
+func notEmoji(r rune) bool {
+	if r < utf8.RuneSelf {
+		return !unicode.IsDigit(r) && r != '#' && r != '*'
+	}
+	return unicode.IsSpace(r) || unicode.IsControl(r) || unicode.IsLetter(r)
+}
+
+type MoveNoteRequest struct {
+	Parent string `json:"parent"`
+}
+
+func (m *MoveNoteRequest) Bind(r *http.Request) error {
+	return json.NewDecoder(r.Body).Decode(&m)
+}
+
+// DuplicateRequest copies a note. The copy belongs to the member of the note
+// with the email, or to the user if it is empty. Subtree also copies the
+// subpages, Images also copies the image blocks.
+type DuplicateRequest struct {
+	Email   string `json:"email,omitempty"`
+	Subtree bool   `json:"subtree"`
+	Images  bool   `json:"images"`
+}
+
+func (d *DuplicateRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		return err
+	}
+
+	if d.Email != "" {
+		return email.ValidateEmail(d.Email)
+	}
+	return nil
+}
+
+type DuplicatedNote struct {
+	Token string `json:"token"`
+}
+
+type NotesOrderRequest struct {
+	Notes []string `json:"notes"`
+}
+
+func (o *NotesOrderRequest) Bind(r *http.Request) error {
+	return json.NewDecoder(r.Body).Decode(&o)
+}
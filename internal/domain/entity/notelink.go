@@ -0,0 +1,28 @@
+package entity
+
+// NoteLink is a [[link]] from the body of the note From to the note To.
+type NoteLink struct {
+	From string `json:"source"`
+	To   string `json:"target"`
+}
+
+type Backlink struct {
+	Token string `json:"token"`
+	Name  string `json:"name"`
+}
+
+type Backlinks struct {
+	Backlinks []Backlink `json:"backlinks"`
+}
+
+type GraphNode struct {
+	Token  string `json:"token"`
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+}
+
+// NotesGraph is the notes the user can open and the links between them.
+type NotesGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []NoteLink  `json:"edges"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// Types of notifications.
+const (
+	NotificationMention = "mention"
+)
+
+// Mention records that the user is mentioned in the body of the note or, if
+// CommentID is set, in the comment.
+type Mention struct {
+	NoteToken string
+	CommentID int
+	UserID    string
+}
+
+type Notification struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"-"`
+	Type      string    `json:"type"`
+	NoteToken string    `json:"token"`
+	CommentID int       `json:"commentID,omitempty"`
+	AuthorID  string    `json:"authorID"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Notifications struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int            `json:"unread"`
+}
@@ -1,8 +1,17 @@
 package entity
 
-import "mime/multipart"
+import (
+	"io"
+	"mime/multipart"
+)
 
 type ImageUnit struct {
 	Payload     multipart.File
 	PayloadSize int64
 }
+
+type FileUnit struct {
+	Payload     io.Reader
+	PayloadSize int64
+	ContentType string
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// PresenceTimeout is how long a user stays present without a heartbeat.
+const PresenceTimeout = 30 * time.Second
+
+// Types of presence events.
+const (
+	PresenceJoin   = "join"
+	PresenceLeave  = "leave"
+	PresenceCursor = "cursor"
+)
+
+// Cursor is a selection in the body of a note, anchor and head are equal
+// when nothing is selected.
+type Cursor struct {
+	Anchor int `json:"anchor"`
+	Head   int `json:"head"`
+}
+
+// PresenceUser is one connection of a user to a note, a user with several
+// tabs open is present several times.
+type PresenceUser struct {
+	SessionID string    `json:"sessionID"`
+	UserID    string    `json:"userID"`
+	Username  string    `json:"username"`
+	Cursor    *Cursor   `json:"cursor,omitempty"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type Presence struct {
+	Users []PresenceUser `json:"users"`
+}
+
+type PresenceEvent struct {
+	Type string       `json:"type"`
+	User PresenceUser `json:"user"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type Revision struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"userID"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body,omitempty"`
+	// Blocks is nil for revisions recorded before blocks were kept.
+	Blocks []Block `json:"blocks,omitempty"`
+}
+
+type Revisions struct {
+	Revisions []Revision `json:"revisions"`
+}
+
+type RevisionDiff struct {
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+	FromName string `json:"from_name"`
+	ToName   string `json:"to_name"`
+	Diff     string `json:"diff"`
+}
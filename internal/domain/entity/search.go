@@ -0,0 +1,14 @@
+package entity
+
+const MaxSearchResults = 50
+
+type FoundNote struct {
+	Name    string  `json:"name"`
+	Token   string  `json:"token"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+type FoundNotes struct {
+	Notes []FoundNote `json:"notes"`
+}
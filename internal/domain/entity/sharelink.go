@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// MaxLinkPasswordLength is the longest password bcrypt hashes in full.
+const MaxLinkPasswordLength = 72
+
+var ErrExpiresInPast = errors.New("expiry time of the link is in the past")
+var ErrLinkPasswordTooLong = errors.New("password of the link is too long")
+
+type ShareLink struct {
+	Token     string     `json:"token"`
+	NoteToken string     `json:"note"`
+	CreatedBy string     `json:"created_by"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Password  string     `json:"-"`
+	Protected bool       `json:"protected"`
+}
+
+type ShareLinks struct {
+	Links []ShareLink `json:"links"`
+}
+
+func (s *ShareLink) Expired() bool {
+	return s.ExpiresAt != nil && s.ExpiresAt.Before(time.Now())
+}
+
+type ShareLinkRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	Password  string     `json:"password"`
+}
+
+func (s *ShareLinkRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		return err
+	}
+
+	return s.Validate()
+}
+
+func (s *ShareLinkRequest) Validate() error {
+	if s.ExpiresAt != nil && s.ExpiresAt.Before(time.Now()) {
+		return ErrExpiresInPast
+	}
+	if len(s.Password) > MaxLinkPasswordLength {
+		return ErrLinkPasswordTooLong
+	}
+	return nil
+}
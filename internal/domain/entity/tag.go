@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+)
+
+const MaxTagNameLength = 30
+
+var tagColorRegexp = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+
+var ErrEmptyTagName = errors.New("tag name is empty")
+var ErrTagNameLengthExceedsLimit = errors.New("tag name length exceeds limit")
+var ErrInvalidTagColor = errors.New("tag color must be in #rrggbb format")
+
+type Tag struct {
+	ID     int    `json:"id"`
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+	Color  string `json:"color"`
+}
+
+type Tags struct {
+	Tags []Tag `json:"tags"`
+}
+
+type TagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func (t *TagRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		return err
+	}
+
+	return t.Validate()
+}
+
+func (t *TagRequest) Validate() error {
+	if t.Name == "" {
+		return ErrEmptyTagName
+	}
+	if len(t.Name) > MaxTagNameLength {
+		return ErrTagNameLengthExceedsLimit
+	}
+	if !tagColorRegexp.MatchString(t.Color) {
+		return ErrInvalidTagColor
+	}
+	return nil
+}
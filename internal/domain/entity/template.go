@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Variables expanded in the name and the body of a template when a note is
+// created from it.
+const (
+	TemplateDate     = "{{date}}"
+	TemplateTime     = "{{time}}"
+	TemplateUsername = "{{username}}"
+)
+
+var ErrEmptyTemplateName = errors.New("template name is empty")
+var ErrTemplateNameLengthExceedsLimit = errors.New("template name length exceeds limit")
+
+// Template is the name, body and tags a new note starts with. Built-in
+// templates belong to nobody and are shown to every user.
+type Template struct {
+	ID      int    `json:"id"`
+	UserID  string `json:"-"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	Tags    []int  `json:"tags"`
+	BuiltIn bool   `json:"built_in"`
+}
+
+type Templates struct {
+	Templates []Template `json:"templates"`
+}
+
+type TemplateRequest struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+	Tags []int  `json:"tags"`
+}
+
+func (t *TemplateRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		return err
+	}
+
+	return t.Validate()
+}
+
+func (t *TemplateRequest) Validate() error {
+	if t.Name == "" {
+		return ErrEmptyTemplateName
+	}
+	if len(t.Name) > MaxNameLength {
+		return ErrTemplateNameLengthExceedsLimit
+	}
+	return nil
+}
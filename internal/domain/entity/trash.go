@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+type TrashedNote struct {
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	Parent    string    `json:"parent"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type TrashedNotes struct {
+	Notes []TrashedNote `json:"notes"`
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// UploadOffsetHeader carries the offset a chunk starts at and, in the
+	// replies, how much of the file has been received.
+	UploadOffsetHeader = "Upload-Offset"
+	// UploadLengthHeader carries the size of the whole file.
+	UploadLengthHeader = "Upload-Length"
+	// UploadChunkContentType is the content type of the chunks.
+	UploadChunkContentType = "application/offset+octet-stream"
+
+	// MinChunkSize is the smallest chunk but the last one, the smallest
+	// part the object storage accepts.
+	MinChunkSize  = 5 << 20
+	MaxChunkSize  = 64 << 20
+	MaxUploadSize = 1 << 30
+	// UploadExpiration is how long an unfinished upload is kept.
+	UploadExpiration = 24 * time.Hour
+)
+
+var ErrUploadSize = errors.New("upload size is out of range")
+var ErrUploadOffsetConflict = errors.New("upload is not at this offset")
+
+// Upload is a file that is uploaded to a note in chunks. Once all of it is
+// received it becomes an attachment.
+type Upload struct {
+	ID          string `json:"id"`
+	NoteToken   string `json:"-"`
+	UserID      string `json:"-"`
+	FileName    string `json:"file_name"`
+	Size        int64  `json:"size"`
+	Offset      int64  `json:"offset"`
+	ContentType string `json:"-"`
+	ObjectName  string `json:"-"`
+	// StorageID is the id of the multipart upload in the object storage,
+	// it is started with the first chunk.
+	StorageID  string       `json:"-"`
+	Parts      []UploadPart `json:"-"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	Attachment *Attachment  `json:"attachment,omitempty"`
+}
+
+type UploadPart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+type UploadRequest struct {
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+}
+
+func (u *UploadRequest) Bind(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		return err
+	}
+
+	return u.Validate()
+}
+
+func (u *UploadRequest) Validate() error {
+	if u.Size <= 0 || u.Size > MaxUploadSize {
+		return ErrUploadSize
+	}
+	return nil
+}
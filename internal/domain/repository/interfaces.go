@@ -3,6 +3,8 @@ package repository
 import (
 	"cotion/internal/domain/entity"
 	"github.com/minio/minio-go/v7"
+	"io"
+	"time"
 )
 
 type SessionRepository interface {
@@ -14,25 +16,145 @@ type SessionRepository interface {
 type UserRepository interface {
 	Save(user entity.User) error
 	Get(userID string) (entity.User, error)
+	FindByUsername(username string) (entity.User, error)
 	Update(user entity.User) error
 	Delete(userID string) error
 }
 
 type UsersNotesRepository interface {
-	AddLink(userID string, noteToken string) error
+	AddLink(userID string, noteToken string, role string) error
 	DeleteLink(userID string, noteToken string) error
 	CheckLink(userID string, noteToken string) bool
-	AllNotesByUserID(hashedEmail string) (entity.ShortNotes, error)
+	Role(userID string, noteToken string) (string, error)
+	UpdateRole(userID string, noteToken string, role string) error
+	Members(noteToken string) ([]entity.Member, error)
+	AllNotesByUserID(hashedEmail string, filter entity.NotesFilter) (entity.ShortNotes, error)
+	SearchNotes(userID string, query string, limit int) ([]entity.FoundNote, error)
+	TrashByUserID(userID string) ([]entity.TrashedNote, error)
+	SetPinned(userID string, noteToken string, pinned bool) error
+	SetFavorite(userID string, noteToken string, favorite bool) error
+	Reorder(userID string, noteTokens []string) error
 }
 
 type NotesRepository interface {
 	Save(token string, note entity.Note) error
-	Update(token string, note entity.Note) error
+	Update(token string, note entity.Note) (int, error)
 	Delete(token string) error
 	Find(token string) (entity.Note, error)
+	Move(token string, parentToken string) error
+	SetIcon(token string, icon *entity.NoteIcon) error
+	SetCover(token string, cover string) error
+	Subtree(token string) ([]string, error)
+	Trash(token string, deletedAt time.Time) error
+	Restore(token string) error
+	ExpiredTrash(before time.Time) ([]string, error)
+}
+
+type TagsRepository interface {
+	Save(tag entity.Tag) (int, error)
+	Update(tag entity.Tag) error
+	Delete(tagID int) error
+	Find(tagID int) (entity.Tag, error)
+	AllByUserID(userID string) ([]entity.Tag, error)
+	Attach(tagID int, noteToken string) error
+	Detach(tagID int, noteToken string) error
+	NoteTokens(userID string, tagIDs []int, matchAll bool) ([]string, error)
+	NotesTags(userID string) (map[string][]entity.Tag, error)
+}
+
+type BlocksRepository interface {
+	Insert(noteToken string, block entity.Block) error
+	Update(noteToken string, block entity.Block) error
+	Move(noteToken string, blockID string, position int) error
+	Delete(noteToken string, blockID string) error
+	AllByNote(noteToken string) ([]entity.Block, error)
+}
+
+type RevisionsRepository interface {
+	Save(noteToken string, revision entity.Revision) error
+	Find(noteToken string, revisionID int) (entity.Revision, error)
+	AllByNote(noteToken string) ([]entity.Revision, error)
+}
+
+type ShareLinksRepository interface {
+	Save(link entity.ShareLink) error
+	Find(shareToken string) (entity.ShareLink, error)
+	Delete(shareToken string) error
+	AllByNote(noteToken string) ([]entity.ShareLink, error)
 }
 
 type ImageRepository interface {
 	UploadFile(image entity.ImageUnit) (string, error)
 	DownloadFile(imageID string) (*minio.Object, error)
+	DeleteFile(imageID string) error
+	// CopyFile stores a copy of the image and returns its name.
+	CopyFile(imageID string) (string, error)
+}
+
+type FileRepository interface {
+	UploadObject(file entity.FileUnit) (string, error)
+	DownloadFile(fileID string) (*minio.Object, error)
+	DeleteFile(fileID string) error
+	// CopyFile stores a copy of the file and returns its name.
+	CopyFile(fileID string) (string, error)
+	CreateUpload(contentType string) (string, string, error)
+	UploadPart(fileID string, uploadID string, partNumber int, part io.Reader, size int64) (string, error)
+	CompleteUpload(fileID string, uploadID string, parts []entity.UploadPart) error
+	AbortUpload(fileID string, uploadID string) error
+}
+
+type CommentsRepository interface {
+	Save(comment entity.Comment) (int, error)
+	Find(commentID int) (entity.Comment, error)
+	Update(comment entity.Comment) error
+	Delete(commentID int) error
+	SetResolved(commentID int, resolved bool) error
+	AllByNote(noteToken string) ([]entity.Comment, error)
+}
+
+type MentionsRepository interface {
+	Save(mention entity.Mention) error
+	Delete(mention entity.Mention) error
+	Mentioned(noteToken string, commentID int) ([]string, error)
+}
+
+type NotificationsRepository interface {
+	Save(notification entity.Notification) (int, error)
+	AllByUserID(userID string, limit int) ([]entity.Notification, error)
+	CountUnread(userID string) (int, error)
+	MarkRead(userID string, notificationID int) error
+	MarkAllRead(userID string) error
+}
+
+type NoteLinksRepository interface {
+	Replace(fromToken string, toTokens []string) error
+	Delete(noteToken string) error
+	Backlinks(noteToken string) ([]string, error)
+	AllByNotes(noteTokens []string) ([]entity.NoteLink, error)
+}
+
+type TemplatesRepository interface {
+	Save(template entity.Template) (int, error)
+	Update(template entity.Template) error
+	Delete(templateID int) error
+	Find(templateID int) (entity.Template, error)
+	AllByUserID(userID string) ([]entity.Template, error)
+}
+
+type AttachmentsRepository interface {
+	Save(attachment entity.Attachment) (int, error)
+	Find(attachmentID int) (entity.Attachment, error)
+	Delete(attachmentID int) error
+	AllByNote(noteToken string) ([]entity.Attachment, error)
+}
+
+type UploadsRepository interface {
+	Save(upload entity.Upload) error
+	Find(uploadID string) (entity.Upload, error)
+	// Update saves the progress of the upload if its offset is still the
+	// given one.
+	Update(upload entity.Upload, offset int64) error
+	Delete(uploadID string) error
+	Expired(before time.Time) ([]entity.Upload, error)
+	AllByNote(noteToken string) ([]entity.Upload, error)
 }
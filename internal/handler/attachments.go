@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/minio/minio-go/v7"
+	log "github.com/sirupsen/logrus"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+const (
+	attachmentID = "attachment-id"
+	// maxAttachmentSize limits the upload of an attachment.
+	maxAttachmentSize = 25 << 20
+	attachmentFile    = "file"
+)
+
+var NoAttachmentIDError = errors.New("No attachment id in request.")
+
+func (h *NotesHandler) Attachments(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Attachments",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	attachments, err := h.notesService.Attachments(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeAttachments(&attachments)
+
+	if err := json.NewEncoder(w).Encode(attachments); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+// UploadAttachment attaches the file in the "file" form field to the note.
+func (h *NotesHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UploadAttachment",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentSize)
+	if err := r.ParseMultipartForm(maxImportMemory); err != nil {
+		http.Error(w, "Wrong request!", http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	src, hdr, err := r.FormFile(attachmentFile)
+	if err != nil {
+		http.Error(w, "Wrong request!", http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	attachment, err := h.notesService.UploadAttachment(userID, token, src, hdr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeAttachment(&attachment)
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(attachment); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+// DownloadAttachment streams the file. It is always sent as a download, so
+// that an uploaded page cannot run in the context of the site. Range
+// requests let an interrupted download be resumed.
+func (h *NotesHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DownloadAttachment",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, err := strconv.Atoi(vars[attachmentID])
+	if err != nil {
+		http.Error(w, NoAttachmentIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoAttachmentIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	attachment, file, err := h.notesService.DownloadAttachment(userID, token, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		logger.Warning(err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Can`t download attachment!", http.StatusNotFound)
+		logger.Warning(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	serveObject(w, r, file, info)
+}
+
+func (h *NotesHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteAttachment",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, err := strconv.Atoi(vars[attachmentID])
+	if err != nil {
+		http.Error(w, NoAttachmentIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoAttachmentIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.DeleteAttachment(userID, token, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// serveObject sends the object and answers Range, If-Range and conditional
+// requests from its ETag and modification time. The content type has to be
+// set by the caller.
+func serveObject(w http.ResponseWriter, r *http.Request, object *minio.Object, info minio.ObjectInfo) {
+	if info.ETag != "" {
+		w.Header().Set("ETag", `"`+info.ETag+`"`)
+	}
+	http.ServeContent(w, r, "", info.LastModified, object)
+}
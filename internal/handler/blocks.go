@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const blockID = "block-id"
+
+var NoBlockIDError = errors.New("No block id in request.")
+
+func (h *NotesHandler) InsertBlock(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "InsertBlock",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	blockRequest := entity.BlockRequest{}
+	if err := blockRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	block, err := h.notesService.InsertBlock(userID, token, blockRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(block); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) UpdateBlock(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateBlock",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, id, err := blockVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	blockRequest := entity.BlockRequest{}
+	if err := blockRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.UpdateBlock(userID, token, id, blockRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *NotesHandler) MoveBlock(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "MoveBlock",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, id, err := blockVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	moveRequest := entity.MoveBlockRequest{}
+	if err := moveRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.MoveBlock(userID, token, id, moveRequest.Position); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *NotesHandler) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteBlock",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, id, err := blockVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.DeleteBlock(userID, token, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func blockVars(r *http.Request) (string, string, error) {
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		return "", "", NoTokenError
+	}
+	id, ok := vars[blockID]
+	if !ok {
+		return "", "", NoBlockIDError
+	}
+	return token, id, nil
+}
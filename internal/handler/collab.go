@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/application/collab"
+	"cotion/internal/domain/entity"
+	"cotion/internal/handler/middleware"
+	"cotion/internal/pkg/security"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+	"net/http"
+)
+
+var ErrUnauthorized = errors.New("User is not authorized.")
+var ErrForbiddenOrigin = errors.New("Origin is not allowed.")
+
+type CollabHandler struct {
+	collabService application.CollabAppManager
+	authService   application.AuthAppManager
+	secureService security.Manager
+}
+
+func NewCollabHandler(collabServ application.CollabAppManager, authServ application.AuthAppManager, secureServ security.Manager) *CollabHandler {
+	return &CollabHandler{
+		collabService: collabServ,
+		authService:   authServ,
+		secureService: secureServ,
+	}
+}
+
+// EditNote upgrades the request to a WebSocket and connects it to the
+// collaborative editing session of the note. Browsers cannot set headers on
+// WebSocket requests, so the user is authenticated by the session cookie.
+func (h *CollabHandler) EditNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "EditNote",
+	})
+
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	sCookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+	user, ok := h.authService.Auth(sCookie)
+	if !ok {
+		http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	session, err := h.collabService.Join(userID, user.Username, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+	defer h.collabService.Leave(session)
+
+	server := websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			h.serveSession(ws, session)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// serveSession writes the session messages to the connection and passes the
+// client messages to the session until either side is closed.
+func (h *CollabHandler) serveSession(ws *websocket.Conn, session *collab.Session) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "serveSession",
+	})
+
+	// The messages are closed when EditNote leaves the session.
+	go func() {
+		defer ws.Close()
+		for message := range session.Messages() {
+			if err := websocket.JSON.Send(ws, message); err != nil {
+				logger.Warning(err)
+				return
+			}
+		}
+	}()
+
+	for {
+		var message entity.CollabMessage
+		if err := websocket.JSON.Receive(ws, &message); err != nil {
+			break
+		}
+		if err := h.collabService.Receive(session, message); err != nil {
+			reply := entity.CollabMessage{Type: entity.CollabError, Error: err.Error()}
+			if err := websocket.JSON.Send(ws, reply); err != nil {
+				logger.Warning(err)
+				break
+			}
+		}
+	}
+}
+
+// checkOrigin only lets the frontend and the API host itself open a
+// connection, so that other sites cannot use the visitor's cookie.
+func checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil || origin == nil {
+		return ErrForbiddenOrigin
+	}
+	if origin.String() != middleware.AllowedOrigin && origin.Host != r.Host {
+		return ErrForbiddenOrigin
+	}
+	config.Origin = origin
+	return nil
+}
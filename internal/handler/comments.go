@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+const commentID = "comment-id"
+
+var NoCommentIDError = errors.New("No comment id in request.")
+
+type CommentsHandler struct {
+	commentsService application.CommentsAppManager
+	secureService   security.Manager
+}
+
+func NewCommentsHandler(commentsServ application.CommentsAppManager, secureServ security.Manager) *CommentsHandler {
+	return &CommentsHandler{
+		commentsService: commentsServ,
+		secureService:   secureServ,
+	}
+}
+
+func (h *CommentsHandler) Comments(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Comments",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	comments, err := h.commentsService.Comments(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeComments(&comments)
+
+	if err := json.NewEncoder(w).Encode(comments); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *CommentsHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateComment",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	commentRequest := entity.CommentRequest{}
+	if err := commentRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	comment, err := h.commentsService.CreateComment(userID, token, commentRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeComment(&comment)
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(comment); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *CommentsHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateComment",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, err := strconv.Atoi(vars[commentID])
+	if err != nil {
+		http.Error(w, NoCommentIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoCommentIDError)
+		return
+	}
+
+	commentRequest := entity.CommentRequest{}
+	if err := commentRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.commentsService.UpdateComment(userID, token, id, commentRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *CommentsHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteComment",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, err := strconv.Atoi(vars[commentID])
+	if err != nil {
+		http.Error(w, NoCommentIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoCommentIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.commentsService.DeleteComment(userID, token, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ResolveComment resolves the thread on PUT and reopens it on DELETE.
+func (h *CommentsHandler) ResolveComment(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ResolveComment",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, err := strconv.Atoi(vars[commentID])
+	if err != nil {
+		http.Error(w, NoCommentIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoCommentIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.commentsService.ResolveComment(userID, token, id, r.Method == http.MethodPut); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// eventsKeepAlive is how often a comment is sent on an idle stream so that
+// proxies do not close it.
+const eventsKeepAlive = 30 * time.Second
+
+var ErrStreamingUnsupported = errors.New("Streaming is not supported.")
+
+// NotesEvents streams the note events of the user as Server-Sent Events.
+func (h *NotesHandler) NotesEvents(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "NotesEvents",
+	})
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, ErrStreamingUnsupported.Error(), http.StatusInternalServerError)
+		logger.Error(ErrStreamingUnsupported)
+		return
+	}
+
+	user := r.Context().Value("user").(entity.User)
+	userID := h.secureService.Hash(user.Email)
+	events, stop := h.notesService.NotesEvents(userID)
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			xss.SanitizeNoteEvent(&event)
+			data, err := json.Marshal(event)
+			if err != nil {
+				logger.Error(err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				logger.Warning(err)
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				logger.Warning(err)
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	log "github.com/sirupsen/logrus"
+	"mime"
+	"net/http"
+	"time"
+)
+
+type ExportHandler struct {
+	exportService application.ExportAppManager
+	secureService security.Manager
+}
+
+func NewExportHandler(exportServ application.ExportAppManager, secureServ security.Manager) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportServ,
+		secureService: secureServ,
+	}
+}
+
+// ExportWorkspace streams the archive of the user's workspace. Once the
+// archive has started the status cannot change, so later errors only
+// break the download.
+func (h *ExportHandler) ExportWorkspace(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ExportWorkspace",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+
+	fileName := "cotion-" + time.Now().Format("2006-01-02") + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.exportService.ExportWorkspace(userID, w); err != nil {
+		logger.Error(err)
+		return
+	}
+}
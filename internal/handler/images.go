@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/minio/minio-go/v7"
+	log "github.com/sirupsen/logrus"
+	"mime/multipart"
+	"net/http"
+)
+
+const (
+	// maxImageSize limits the upload of an icon or a cover.
+	maxImageSize = 5 << 20
+	iconFile     = "icon"
+	coverFile    = "cover"
+)
+
+type uploadImageFunc func(userID string, noteToken string, src multipart.File, hdr *multipart.FileHeader) error
+type noteImageFunc func(userID string, noteToken string) (*minio.Object, error)
+type deleteImageFunc func(userID string, noteToken string) error
+
+// SetEmojiIcon makes an emoji the icon of the note.
+func (h *NotesHandler) SetEmojiIcon(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "SetEmojiIcon",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	iconRequest := entity.IconRequest{}
+	if err := iconRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.SetEmojiIcon(userID, token, iconRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// UploadIcon makes the image in the "icon" form field the icon of the note.
+func (h *NotesHandler) UploadIcon(w http.ResponseWriter, r *http.Request) {
+	h.uploadImage(w, r, "UploadIcon", iconFile, h.notesService.UploadIcon)
+}
+
+func (h *NotesHandler) DownloadIcon(w http.ResponseWriter, r *http.Request) {
+	h.downloadImage(w, r, "DownloadIcon", h.notesService.DownloadIcon)
+}
+
+func (h *NotesHandler) DeleteIcon(w http.ResponseWriter, r *http.Request) {
+	h.deleteImage(w, r, "DeleteIcon", h.notesService.DeleteIcon)
+}
+
+// UploadCover makes the image in the "cover" form field the cover of the
+// note.
+func (h *NotesHandler) UploadCover(w http.ResponseWriter, r *http.Request) {
+	h.uploadImage(w, r, "UploadCover", coverFile, h.notesService.UploadCover)
+}
+
+func (h *NotesHandler) DownloadCover(w http.ResponseWriter, r *http.Request) {
+	h.downloadImage(w, r, "DownloadCover", h.notesService.DownloadCover)
+}
+
+func (h *NotesHandler) DeleteCover(w http.ResponseWriter, r *http.Request) {
+	h.deleteImage(w, r, "DeleteCover", h.notesService.DeleteCover)
+}
+
+func (h *NotesHandler) uploadImage(w http.ResponseWriter, r *http.Request, function string, field string, upload uploadImageFunc) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": function,
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageSize)
+	src, hdr, err := r.FormFile(field)
+	if err != nil {
+		http.Error(w, "Wrong request!", http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := upload(userID, token, src, hdr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *NotesHandler) downloadImage(w http.ResponseWriter, r *http.Request, function string, download noteImageFunc) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": function,
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	img, err := download(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		logger.Debug(err)
+		return
+	}
+	defer img.Close()
+
+	// The object is only requested when it is first read, so a missing
+	// image shows up here.
+	info, err := img.Stat()
+	if err != nil {
+		http.Error(w, "Can`t download image!", http.StatusNotFound)
+		logger.Warning(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", info.ContentType)
+	serveObject(w, r, img, info)
+}
+
+func (h *NotesHandler) deleteImage(w http.ResponseWriter, r *http.Request, function string, remove deleteImageFunc) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": function,
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := remove(userID, token); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
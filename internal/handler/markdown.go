@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"mime"
+	"net/http"
+)
+
+const (
+	// maxImportSize limits the whole import request.
+	maxImportSize = 10 << 20
+	// maxImportMemory is how much of the files is kept in memory, the rest
+	// goes to temporary files.
+	maxImportMemory = 1 << 20
+	importFiles     = "files"
+	importParent    = "parent"
+)
+
+func (h *NotesHandler) ExportNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ExportNote",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = entity.ExportMarkdown
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	exported, err := h.notesService.ExportNote(userID, token, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", exported.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": exported.FileName}))
+	if _, err := w.Write(exported.Data); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) ImportNotes(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ImportNotes",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportMemory); err != nil {
+		http.Error(w, "Wrong request!", http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	userID := h.secureService.Hash(user.Email)
+	imported, err := h.notesService.ImportNotes(userID, r.FormValue(importParent), r.MultipartForm.File[importFiles])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeImportedNotes(&imported)
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(imported); err != nil {
+		logger.Error(err)
+		return
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const memberID = "member-id"
+
+var NoMemberIDError = errors.New("No member id in request.")
+
+type MembersHandler struct {
+	membersService application.MembersAppManager
+	secureService  security.Manager
+}
+
+func NewMembersHandler(membersServ application.MembersAppManager, secureServ security.Manager) *MembersHandler {
+	return &MembersHandler{
+		membersService: membersServ,
+		secureService:  secureServ,
+	}
+}
+
+func (h *MembersHandler) Members(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Members",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	members, err := h.membersService.Members(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(members); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *MembersHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AddMember",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	memberRequest := entity.MemberRequest{}
+	if err := memberRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.membersService.AddMember(userID, token, memberRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *MembersHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ChangeRole",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, member, err := memberVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	roleRequest := entity.RoleRequest{}
+	if err := roleRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.membersService.ChangeRole(userID, token, member, roleRequest.Role); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *MembersHandler) RevokeMember(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "RevokeMember",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, member, err := memberVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.membersService.RevokeMember(userID, token, member); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func memberVars(r *http.Request) (string, string, error) {
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		return "", "", NoTokenError
+	}
+	member, ok := vars[memberID]
+	if !ok {
+		return "", "", NoMemberIDError
+	}
+	return token, member, nil
+}
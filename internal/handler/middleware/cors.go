@@ -5,6 +5,9 @@ import (
 	"net/http"
 )
 
+// AllowedOrigin is the frontend that may call the API from the browser.
+const AllowedOrigin = "http://95.163.212.32:3000"
+
 func CorsMiddleware() mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return enableCORS(next)
@@ -13,8 +16,10 @@ func CorsMiddleware() mux.MiddlewareFunc {
 
 func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Access-Control-Allow-Origin", "http://95.163.212.32:3000")
+		w.Header().Add("Access-Control-Allow-Origin", AllowedOrigin)
 		w.Header().Add("Access-Control-Allow-Credentials", "true")
+		w.Header().Add("Access-Control-Allow-Headers", "Content-Type, If-Match, Range, If-Range, Upload-Offset, X-Share-Password")
+		w.Header().Add("Access-Control-Expose-Headers", "ETag, Location, Content-Range, Upload-Offset, Upload-Length")
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter lets every client make a limited number of requests in each
+// window of time. Clients are told apart by their address.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	requests    map[string]int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:    limit,
+		window:   window,
+		requests: map[string]int{},
+	}
+}
+
+func (rl *RateLimiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		retryAfter, ok := rl.allow(clientAddress(r), time.Now())
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			http.Error(w, "Too many requests!", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// allow counts the request of the client and returns false with the time
+// left in the window once the client is over the limit. The counts are
+// dropped with every new window, so they do not pile up.
+func (rl *RateLimiter) allow(client string, now time.Time) (time.Duration, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Sub(rl.windowStart) >= rl.window {
+		rl.windowStart = now
+		rl.requests = map[string]int{}
+	}
+
+	rl.requests[client]++
+	if rl.requests[client] > rl.limit {
+		return rl.windowStart.Add(rl.window).Sub(now), false
+	}
+	return 0, true
+}
+
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
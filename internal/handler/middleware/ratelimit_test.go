@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(2, time.Minute)
+	handler := limiter.Limit(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	request := func(address string) int {
+		r := httptest.NewRequest("GET", "/api/v1/public/abc", nil)
+		r.RemoteAddr = address
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, request("10.0.0.1:1000"))
+	require.Equal(t, http.StatusOK, request("10.0.0.1:1001"))
+	require.Equal(t, http.StatusTooManyRequests, request("10.0.0.1:1002"))
+	require.Equal(t, http.StatusOK, request("10.0.0.2:1000"))
+
+	_, ok := limiter.allow("10.0.0.1", time.Now().Add(time.Minute))
+	require.Equal(t, true, ok)
+}
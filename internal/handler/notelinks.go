@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+func (h *NotesHandler) Backlinks(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Backlinks",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	backlinks, err := h.notesService.Backlinks(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeBacklinks(&backlinks)
+
+	if err := json.NewEncoder(w).Encode(backlinks); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) NotesGraph(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "NotesGraph",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+
+	userID := h.secureService.Hash(user.Email)
+	graph, err := h.notesService.NotesGraph(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+
+	xss.SanitizeNotesGraph(&graph)
+
+	if err := json.NewEncoder(w).Encode(graph); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
@@ -10,11 +10,17 @@ import (
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
-const noteToken = "note-token"
+const (
+	noteToken   = "note-token"
+	searchQuery = "q"
+)
 
 var NoTokenError = errors.New("No token in request.")
+var InvalidIfMatchError = errors.New("Invalid If-Match header.")
 
 type NotesHandler struct {
 	notesService  application.NotesAppManager
@@ -55,6 +61,7 @@ func (h *NotesHandler) ReceiveSingleNote(w http.ResponseWriter, r *http.Request)
 
 	xss.SanitizeNote(&note)
 
+	w.Header().Set("ETag", noteETag(note.Version))
 	if err := json.NewEncoder(w).Encode(note); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		logger.Error(err)
@@ -70,8 +77,20 @@ func (h *NotesHandler) MainPage(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Add("Content-Type", "application/json")
 
+	filter := entity.NotesFilter{}
+	if err := filter.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
 	user := r.Context().Value("user").(entity.User)
-	notes, err := h.notesService.AllNotesByUserID(user.UserID)
+	notes, err := h.notesService.AllNotesByUserID(user.UserID, filter)
+	if err == entity.ErrInvalidCursor {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		logger.Error(err)
@@ -89,6 +108,31 @@ func (h *NotesHandler) MainPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *NotesHandler) SearchNotes(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "SearchNotes",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+
+	user := r.Context().Value("user").(entity.User)
+	notes, err := h.notesService.SearchNotes(user.UserID, r.URL.Query().Get(searchQuery))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeFoundNotes(&notes)
+
+	if err := json.NewEncoder(w).Encode(notes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
 func (h *NotesHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  packageName,
@@ -138,13 +182,31 @@ func (h *NotesHandler) UpdateNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
 	userID := string(h.secureService.Hash(user.Email))
-	if err := h.notesService.UpdateNote(userID, token, noteRequest); err != nil {
+	version, err = h.notesService.UpdateNote(userID, token, noteRequest, version)
+	if err == entity.ErrNoteVersionConflict {
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Set("ETag", noteETag(version))
+		w.WriteHeader(http.StatusPreconditionFailed)
+		if err := json.NewEncoder(w).Encode(entity.NoteVersion{Version: version}); err != nil {
+			logger.Error(err)
+		}
+		return
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		logger.Warning(err)
 		return
 	}
 
+	w.Header().Set("ETag", noteETag(version))
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -172,3 +234,126 @@ func (h *NotesHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *NotesHandler) NotesTree(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "NotesTree",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+
+	user := r.Context().Value("user").(entity.User)
+	tree, err := h.notesService.NotesTree(user.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+
+	xss.SanitizeTree(&tree)
+
+	if err := json.NewEncoder(w).Encode(tree); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) MoveNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "MoveNote",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	moveRequest := entity.MoveNoteRequest{}
+	if err := moveRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.MoveNote(userID, token, moveRequest.Parent); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DuplicateNote copies the note. The body of the request is optional, without
+// it only the note itself is copied for the user.
+func (h *NotesHandler) DuplicateNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DuplicateNote",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	duplicateRequest := entity.DuplicateRequest{}
+	if r.ContentLength != 0 {
+		if err := duplicateRequest.Bind(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			logger.Warning(err)
+			return
+		}
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	ownerID := userID
+	if duplicateRequest.Email != "" {
+		ownerID = h.secureService.Hash(duplicateRequest.Email)
+	}
+
+	duplicated, err := h.notesService.DuplicateNote(userID, token, ownerID, duplicateRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(duplicated); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func noteETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseIfMatch returns the note version from the If-Match header. A missing
+// header or "*" give zero, which means any version.
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil || version < 1 {
+		return 0, InvalidIfMatchError
+	}
+	return version, nil
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+const notificationID = "notification-id"
+
+var NoNotificationIDError = errors.New("No notification id in request.")
+
+type NotificationsHandler struct {
+	notificationsService application.NotificationsAppManager
+	secureService        security.Manager
+}
+
+func NewNotificationsHandler(notificationsServ application.NotificationsAppManager, secureServ security.Manager) *NotificationsHandler {
+	return &NotificationsHandler{
+		notificationsService: notificationsServ,
+		secureService:        secureServ,
+	}
+}
+
+func (h *NotificationsHandler) Notifications(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Notifications",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+
+	userID := h.secureService.Hash(user.Email)
+	notifications, err := h.notificationsService.Notifications(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(notifications); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotificationsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "MarkRead",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	id, err := strconv.Atoi(mux.Vars(r)[notificationID])
+	if err != nil {
+		http.Error(w, NoNotificationIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoNotificationIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notificationsService.MarkRead(userID, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *NotificationsHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "MarkAllRead",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notificationsService.MarkAllRead(userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// PinNote pins the note on PUT and unpins it on DELETE.
+func (h *NotesHandler) PinNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "PinNote",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, ok := mux.Vars(r)[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.PinNote(userID, token, r.Method == http.MethodPut); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// FavoriteNote stars the note on PUT and unstars it on DELETE.
+func (h *NotesHandler) FavoriteNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "FavoriteNote",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, ok := mux.Vars(r)[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.FavoriteNote(userID, token, r.Method == http.MethodPut); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *NotesHandler) ReorderNotes(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ReorderNotes",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+
+	orderRequest := entity.NotesOrderRequest{}
+	if err := orderRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.ReorderNotes(userID, orderRequest.Notes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
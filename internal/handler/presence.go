@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type PresenceHandler struct {
+	presenceService application.PresenceAppManager
+	secureService   security.Manager
+}
+
+func NewPresenceHandler(presenceServ application.PresenceAppManager, secureServ security.Manager) *PresenceHandler {
+	return &PresenceHandler{
+		presenceService: presenceServ,
+		secureService:   secureServ,
+	}
+}
+
+func (h *PresenceHandler) Presence(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Presence",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	presence, err := h.presenceService.Presence(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizePresence(&presence)
+
+	if err := json.NewEncoder(w).Encode(presence); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
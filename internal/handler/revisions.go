@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+const revisionID = "revision-id"
+
+var NoRevisionIDError = errors.New("No revision id in request.")
+var BadDiffRangeError = errors.New("Revisions to compare must be set by 'from' and 'to' query parameters.")
+
+func (h *NotesHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Revisions",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	revisions, err := h.notesService.Revisions(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	xss.SanitizeRevisions(&revisions)
+
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "GetRevision",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	token, id, err := revisionVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	revision, err := h.notesService.GetRevision(userID, token, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	xss.SanitizeRevision(&revision)
+
+	if err := json.NewEncoder(w).Encode(revision); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DiffRevisions",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	fromID, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	toID, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		http.Error(w, BadDiffRangeError.Error(), http.StatusBadRequest)
+		logger.Warning(BadDiffRangeError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	revisionDiff, err := h.notesService.DiffRevisions(userID, token, fromID, toID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	xss.SanitizeRevisionDiff(&revisionDiff)
+
+	if err := json.NewEncoder(w).Encode(revisionDiff); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "RestoreRevision",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, id, err := revisionVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.RestoreRevision(userID, token, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func revisionVars(r *http.Request) (string, int, error) {
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		return "", 0, NoTokenError
+	}
+	id, err := strconv.Atoi(vars[revisionID])
+	if err != nil {
+		return "", 0, NoRevisionIDError
+	}
+	return token, id, nil
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/application/share"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const (
+	shareToken          = "share-token"
+	sharePasswordHeader = "X-Share-Password"
+)
+
+var NoShareTokenError = errors.New("No share token in request.")
+
+type ShareHandler struct {
+	shareService  application.ShareAppManager
+	secureService security.Manager
+}
+
+func NewShareHandler(shareServ application.ShareAppManager, secureServ security.Manager) *ShareHandler {
+	return &ShareHandler{
+		shareService:  shareServ,
+		secureService: secureServ,
+	}
+}
+
+func (h *ShareHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateLink",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	linkRequest := entity.ShareLinkRequest{}
+	if err := linkRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	link, err := h.shareService.CreateLink(userID, token, linkRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(link); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *ShareHandler) Links(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Links",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	links, err := h.shareService.Links(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(links); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *ShareHandler) RevokeLink(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "RevokeLink",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	link, ok := vars[shareToken]
+	if !ok {
+		http.Error(w, NoShareTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoShareTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.shareService.RevokeLink(userID, token, link); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ShareHandler) PublicNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "PublicNote",
+	})
+
+	vars := mux.Vars(r)
+	link, ok := vars[shareToken]
+	if !ok {
+		http.Error(w, NoShareTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoShareTokenError)
+		return
+	}
+
+	note, err := h.shareService.PublicNote(link, r.Header.Get(sharePasswordHeader))
+	switch {
+	case errors.Is(err, share.ErrShareLinkPassword):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	xss.SanitizeNote(&note)
+
+	w.Header().Add("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(note); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
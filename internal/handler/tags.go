@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+const tagID = "tag-id"
+
+var NoTagIDError = errors.New("No tag id in request.")
+
+type TagsHandler struct {
+	tagsService   application.TagsAppManager
+	secureService security.Manager
+}
+
+func NewTagsHandler(tagsServ application.TagsAppManager, secureServ security.Manager) *TagsHandler {
+	return &TagsHandler{
+		tagsService:   tagsServ,
+		secureService: secureServ,
+	}
+}
+
+func (h *TagsHandler) Tags(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Tags",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+
+	userID := h.secureService.Hash(user.Email)
+	tags, err := h.tagsService.Tags(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+
+	xss.SanitizeTags(&tags)
+
+	if err := json.NewEncoder(w).Encode(tags); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *TagsHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateTag",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+
+	tagRequest := entity.TagRequest{}
+	if err := tagRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	tag, err := h.tagsService.CreateTag(userID, tagRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeTag(&tag)
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(tag); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *TagsHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateTag",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	id, err := strconv.Atoi(mux.Vars(r)[tagID])
+	if err != nil {
+		http.Error(w, NoTagIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTagIDError)
+		return
+	}
+
+	tagRequest := entity.TagRequest{}
+	if err := tagRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.tagsService.UpdateTag(userID, id, tagRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TagsHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteTag",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	id, err := strconv.Atoi(mux.Vars(r)[tagID])
+	if err != nil {
+		http.Error(w, NoTagIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTagIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.tagsService.DeleteTag(userID, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TagsHandler) AttachTag(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AttachTag",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, id, err := noteTagVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.tagsService.AttachTag(userID, token, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TagsHandler) DetachTag(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DetachTag",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	token, id, err := noteTagVars(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.tagsService.DetachTag(userID, token, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func noteTagVars(r *http.Request) (string, int, error) {
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		return "", 0, NoTokenError
+	}
+	id, err := strconv.Atoi(vars[tagID])
+	if err != nil {
+		return "", 0, NoTagIDError
+	}
+	return token, id, nil
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"cotion/internal/application"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/security"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+const templateID = "template-id"
+
+var NoTemplateIDError = errors.New("No template id in request.")
+
+type TemplatesHandler struct {
+	templatesService application.TemplatesAppManager
+	secureService    security.Manager
+}
+
+func NewTemplatesHandler(templatesServ application.TemplatesAppManager, secureServ security.Manager) *TemplatesHandler {
+	return &TemplatesHandler{
+		templatesService: templatesServ,
+		secureService:    secureServ,
+	}
+}
+
+func (h *TemplatesHandler) Templates(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Templates",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+
+	userID := h.secureService.Hash(user.Email)
+	templates, err := h.templatesService.Templates(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+
+	xss.SanitizeTemplates(&templates)
+
+	if err := json.NewEncoder(w).Encode(templates); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *TemplatesHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateTemplate",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+
+	templateRequest := entity.TemplateRequest{}
+	if err := templateRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	template, err := h.templatesService.CreateTemplate(userID, templateRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	h.writeTemplate(w, template)
+}
+
+func (h *TemplatesHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateTemplate",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	id, err := strconv.Atoi(mux.Vars(r)[templateID])
+	if err != nil {
+		http.Error(w, NoTemplateIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTemplateIDError)
+		return
+	}
+
+	templateRequest := entity.TemplateRequest{}
+	if err := templateRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.templatesService.UpdateTemplate(userID, id, templateRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TemplatesHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "DeleteTemplate",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	id, err := strconv.Atoi(mux.Vars(r)[templateID])
+	if err != nil {
+		http.Error(w, NoTemplateIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTemplateIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.templatesService.DeleteTemplate(userID, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TemplatesHandler) SaveNoteAsTemplate(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "SaveNoteAsTemplate",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	token, ok := mux.Vars(r)[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	template, err := h.templatesService.SaveNoteAsTemplate(userID, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	h.writeTemplate(w, template)
+}
+
+// CreateNote creates a note from the template given in the query. The body
+// of the request is optional and may set the name and the parent of the note.
+func (h *TemplatesHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateNote",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	id, err := strconv.Atoi(mux.Vars(r)[templateID])
+	if err != nil {
+		http.Error(w, NoTemplateIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTemplateIDError)
+		return
+	}
+
+	var noteRequest entity.NoteRequest
+	if r.ContentLength != 0 {
+		if err := noteRequest.Bind(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			logger.Warning(err)
+			return
+		}
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.templatesService.CreateNote(userID, user.Username, id, noteRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *TemplatesHandler) writeTemplate(w http.ResponseWriter, template entity.Template) {
+	xss.SanitizeTemplate(&template)
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(template); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "writeTemplate",
+		}).Error(err)
+	}
+}
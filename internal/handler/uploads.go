@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"cotion/internal/application/notes"
+	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/xss"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+)
+
+const uploadID = "upload-id"
+
+var NoUploadIDError = errors.New("No upload id in request.")
+var NoUploadOffsetError = errors.New("No upload offset in request.")
+
+// CreateUpload starts a resumable upload of an attachment. The Location
+// header of the reply is where the chunks are sent.
+func (h *NotesHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "CreateUpload",
+	})
+
+	w.Header().Add("Content-Type", "application/json")
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+
+	uploadRequest := entity.UploadRequest{}
+	if err := uploadRequest.Bind(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	upload, err := h.notesService.CreateUpload(userID, token, uploadRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	xss.SanitizeUpload(&upload)
+
+	w.Header().Set("Location", r.URL.Path+"/"+upload.ID)
+	w.Header().Set(entity.UploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(upload); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+// UploadStatus answers a HEAD request with the offset to resume the upload
+// from.
+func (h *NotesHandler) UploadStatus(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UploadStatus",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, ok := vars[uploadID]
+	if !ok {
+		http.Error(w, NoUploadIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoUploadIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	upload, err := h.notesService.UploadStatus(userID, token, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		logger.Warning(err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set(entity.UploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set(entity.UploadLengthHeader, strconv.FormatInt(upload.Size, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// UploadChunk appends the body to the upload. The Upload-Offset header has
+// to be the offset of the upload, a stale one is a conflict and the client
+// should ask for the offset again. The reply to the last chunk is the
+// attachment the file became.
+func (h *NotesHandler) UploadChunk(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UploadChunk",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, ok := vars[uploadID]
+	if !ok {
+		http.Error(w, NoUploadIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoUploadIDError)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != entity.UploadChunkContentType {
+		http.Error(w, "Wrong request!", http.StatusUnsupportedMediaType)
+		logger.Warning("wrong content type of the chunk")
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get(entity.UploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, NoUploadOffsetError.Error(), http.StatusBadRequest)
+		logger.Warning(NoUploadOffsetError)
+		return
+	}
+	// The part is streamed to the object storage, which needs its size
+	// up front.
+	if r.ContentLength <= 0 {
+		http.Error(w, notes.ErrChunkSize.Error(), http.StatusLengthRequired)
+		logger.Warning(notes.ErrChunkSize)
+		return
+	}
+	if r.ContentLength > entity.MaxChunkSize {
+		http.Error(w, notes.ErrChunkSize.Error(), http.StatusRequestEntityTooLarge)
+		logger.Warning(notes.ErrChunkSize)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	r.Body = http.MaxBytesReader(w, r.Body, r.ContentLength)
+	upload, err := h.notesService.UploadChunk(userID, token, id, offset, r.Body, r.ContentLength)
+	switch {
+	case errors.Is(err, notes.ErrUploadNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		logger.Warning(err)
+		return
+	case errors.Is(err, notes.ErrUploadOffset):
+		http.Error(w, err.Error(), http.StatusConflict)
+		logger.Warning(err)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Warning(err)
+		return
+	}
+
+	w.Header().Set(entity.UploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	if upload.Attachment == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	xss.SanitizeAttachment(upload.Attachment)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(upload.Attachment); err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func (h *NotesHandler) AbortUpload(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AbortUpload",
+	})
+
+	user := r.Context().Value("user").(entity.User)
+	vars := mux.Vars(r)
+	token, ok := vars[noteToken]
+	if !ok {
+		http.Error(w, NoTokenError.Error(), http.StatusBadRequest)
+		logger.Warning(NoTokenError)
+		return
+	}
+	id, ok := vars[uploadID]
+	if !ok {
+		http.Error(w, NoUploadIDError.Error(), http.StatusBadRequest)
+		logger.Warning(NoUploadIDError)
+		return
+	}
+
+	userID := h.secureService.Hash(user.Email)
+	if err := h.notesService.AbortUpload(userID, token, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		logger.Warning(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoAttachmentInDB = errors.New("no attachment in DB with this id")
+
+type AttachmentsStorage struct {
+	DB *sql.DB
+}
+
+func NewAttachmentsStorage(db *sql.DB) *AttachmentsStorage {
+	return &AttachmentsStorage{
+		DB: db,
+	}
+}
+
+const querySaveAttachment = `INSERT INTO attachment(noteid, objectname, filename, contenttype, size, uploadedby, createdat)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7) RETURNING attachmentid`
+
+func (store *AttachmentsStorage) Save(attachment entity.Attachment) (int, error) {
+	var attachmentID int
+	err := store.DB.QueryRow(querySaveAttachment, attachment.NoteToken, attachment.ObjectName, attachment.FileName,
+		attachment.ContentType, attachment.Size, attachment.UploadedBy, attachment.CreatedAt).Scan(&attachmentID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Save",
+			"noteToken": attachment.NoteToken,
+		}).Error(err)
+		return 0, err
+	}
+	return attachmentID, nil
+}
+
+const querySelectAttachment = `SELECT attachmentid, noteid, objectname, filename, contenttype, size,
+	COALESCE(uploadedby, ''), createdat
+FROM attachment`
+
+func scanAttachment(row scanner) (entity.Attachment, error) {
+	var attachment entity.Attachment
+	err := row.Scan(&attachment.ID, &attachment.NoteToken, &attachment.ObjectName, &attachment.FileName,
+		&attachment.ContentType, &attachment.Size, &attachment.UploadedBy, &attachment.CreatedAt)
+	return attachment, err
+}
+
+const queryFindAttachment = querySelectAttachment + " WHERE attachmentid = $1"
+
+func (store *AttachmentsStorage) Find(attachmentID int) (entity.Attachment, error) {
+	attachment, err := scanAttachment(store.DB.QueryRow(queryFindAttachment, attachmentID))
+	if err == sql.ErrNoRows {
+		return entity.Attachment{}, ErrNoAttachmentInDB
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":      packageName,
+			"function":     "Find",
+			"attachmentID": attachmentID,
+		}).Error(err)
+		return entity.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+const queryDeleteAttachment = "DELETE FROM attachment WHERE attachmentid = $1"
+
+func (store *AttachmentsStorage) Delete(attachmentID int) error {
+	if _, err := store.DB.Exec(queryDeleteAttachment, attachmentID); err != nil {
+		log.WithFields(log.Fields{
+			"package":      packageName,
+			"function":     "Delete",
+			"attachmentID": attachmentID,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryAllAttachments = querySelectAttachment + " WHERE noteid = $1 ORDER BY createdat, attachmentid"
+
+func (store *AttachmentsStorage) AllByNote(noteToken string) ([]entity.Attachment, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "AllByNote",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryAllAttachments, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []entity.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,124 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestSaveAttachment(t *testing.T) {
+	var mockAttachment = entity.Attachment{
+		NoteToken:   "1",
+		ObjectName:  "object",
+		FileName:    "report.pdf",
+		ContentType: "application/pdf",
+		Size:        15,
+		UploadedBy:  "101",
+		CreatedAt:   time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(int, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO attachment").
+					WithArgs(mockAttachment.NoteToken, mockAttachment.ObjectName, mockAttachment.FileName,
+						mockAttachment.ContentType, mockAttachment.Size, mockAttachment.UploadedBy, mockAttachment.CreatedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"attachmentid"}).AddRow(4))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 4, actualID)
+			},
+		},
+		"No note": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO attachment").
+					WillReturnError(fmt.Errorf("insert or update violates foreign key constraint"))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, fmt.Errorf("insert or update violates foreign key constraint"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewAttachmentsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			id, err := repo.Save(mockAttachment)
+			tc.expected(id, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestFindAttachment(t *testing.T) {
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"attachmentid", "noteid", "objectname", "filename", "contenttype", "size", "uploadedby", "createdat"}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(entity.Attachment, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT attachmentid").
+					WithArgs(4).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(4, "1", "object", "report.pdf", "application/pdf", 15, "101", createdAt))
+			},
+			expected: func(actualAttachment entity.Attachment, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, entity.Attachment{
+					ID:          4,
+					NoteToken:   "1",
+					ObjectName:  "object",
+					FileName:    "report.pdf",
+					ContentType: "application/pdf",
+					Size:        15,
+					UploadedBy:  "101",
+					CreatedAt:   createdAt,
+				}, actualAttachment)
+			},
+		},
+		"No attachment": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT attachmentid").
+					WithArgs(4).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expected: func(actualAttachment entity.Attachment, actualErr error) {
+				require.Equal(t, ErrNoAttachmentInDB, actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewAttachmentsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			attachment, err := repo.Find(4)
+			tc.expected(attachment, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
@@ -0,0 +1,207 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoBlockInDB = errors.New("no block in DB with this id")
+
+type BlocksStorage struct {
+	DB *sql.DB
+}
+
+func NewBlocksStorage(db *sql.DB) *BlocksStorage {
+	return &BlocksStorage{
+		DB: db,
+	}
+}
+
+// queryLockNote serializes position changes of the blocks of one note.
+const queryLockNote = "SELECT noteid FROM note WHERE noteid = $1 FOR UPDATE"
+
+const (
+	queryOpenGap     = "UPDATE block SET position = position + 1 WHERE noteid = $1 AND position >= $2"
+	queryInsertBlock = "INSERT INTO block(blockid, noteid, position, type, content) VALUES ($1, $2, $3, $4, $5)"
+)
+
+func (store *BlocksStorage) Insert(noteToken string, block entity.Block) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Insert",
+		"noteToken": noteToken,
+	})
+
+	tx, err := store.DB.Begin()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryLockNote, noteToken); err != nil {
+		logger.Error(err)
+		return err
+	}
+	if _, err := tx.Exec(queryOpenGap, noteToken, block.Position); err != nil {
+		logger.Error(err)
+		return err
+	}
+	if _, err := tx.Exec(queryInsertBlock, block.ID, noteToken, block.Position, block.Type, []byte(block.Content)); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const queryUpdateBlock = "UPDATE block SET type = $1, content = $2 WHERE blockid = $3 AND noteid = $4"
+
+func (store *BlocksStorage) Update(noteToken string, block entity.Block) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Update",
+		"blockID":  block.ID,
+	})
+
+	result, err := store.DB.Exec(queryUpdateBlock, block.Type, []byte(block.Content), block.ID, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoBlockInDB)
+		return ErrNoBlockInDB
+	}
+	return nil
+}
+
+const (
+	queryBlockPosition    = "SELECT position FROM block WHERE blockid = $1 AND noteid = $2"
+	queryShiftBlocksUp    = "UPDATE block SET position = position - 1 WHERE noteid = $1 AND position > $2 AND position <= $3"
+	queryShiftBlocksDown  = "UPDATE block SET position = position + 1 WHERE noteid = $1 AND position >= $2 AND position < $3"
+	querySetBlockPosition = "UPDATE block SET position = $1 WHERE blockid = $2"
+)
+
+func (store *BlocksStorage) Move(noteToken string, blockID string, position int) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Move",
+		"blockID":  blockID,
+	})
+
+	tx, err := store.DB.Begin()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryLockNote, noteToken); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	var oldPosition int
+	if err := tx.QueryRow(queryBlockPosition, blockID, noteToken).Scan(&oldPosition); err != nil {
+		logger.Warning(err)
+		return ErrNoBlockInDB
+	}
+
+	switch {
+	case position > oldPosition:
+		_, err = tx.Exec(queryShiftBlocksUp, noteToken, oldPosition, position)
+	case position < oldPosition:
+		_, err = tx.Exec(queryShiftBlocksDown, noteToken, position, oldPosition)
+	default:
+		return nil
+	}
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if _, err := tx.Exec(querySetBlockPosition, position, blockID); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const (
+	queryDeleteBlock = "DELETE FROM block WHERE blockid = $1 AND noteid = $2 RETURNING position"
+	queryCloseGap    = "UPDATE block SET position = position - 1 WHERE noteid = $1 AND position > $2"
+)
+
+func (store *BlocksStorage) Delete(noteToken string, blockID string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Delete",
+		"blockID":  blockID,
+	})
+
+	tx, err := store.DB.Begin()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryLockNote, noteToken); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	var position int
+	if err := tx.QueryRow(queryDeleteBlock, blockID, noteToken).Scan(&position); err != nil {
+		logger.Warning(err)
+		return ErrNoBlockInDB
+	}
+
+	if _, err := tx.Exec(queryCloseGap, noteToken, position); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const queryAllBlocks = "SELECT blockid, type, position, content FROM block WHERE noteid = $1 ORDER BY position"
+
+func (store *BlocksStorage) AllByNote(noteToken string) ([]entity.Block, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "AllByNote",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryAllBlocks, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []entity.Block
+	for rows.Next() {
+		var block entity.Block
+		var content []byte
+		if err := rows.Scan(&block.ID, &block.Type, &block.Position, &content); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		block.Content = json.RawMessage(content)
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,126 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+)
+
+func TestInsertBlock(t *testing.T) {
+	const noteToken = "1"
+	var mockBlock = entity.Block{
+		ID:       "abcdef",
+		Type:     entity.BlockText,
+		Position: 1,
+		Content:  json.RawMessage(`{"text":"hello"}`),
+	}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT noteid FROM note").
+					WithArgs(noteToken).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE block SET position = position \\+ 1").
+					WithArgs(noteToken, mockBlock.Position).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("INSERT INTO block").
+					WithArgs(mockBlock.ID, noteToken, mockBlock.Position, mockBlock.Type, []byte(mockBlock.Content)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, nil, actualErr)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT noteid FROM note").
+					WithArgs(noteToken).
+					WillReturnError(fmt.Errorf("internal error"))
+				mock.ExpectRollback()
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewBlocksStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			err := repo.Insert(noteToken, mockBlock)
+			tc.expected(err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestAllBlocks(t *testing.T) {
+	const noteToken = "1"
+	var mockBlock = entity.Block{
+		ID:       "abcdef",
+		Type:     entity.BlockText,
+		Position: 0,
+		Content:  json.RawMessage(`{"text":"hello"}`),
+	}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func([]entity.Block, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"blockid", "type", "position", "content"})
+				rows = rows.AddRow(mockBlock.ID, mockBlock.Type, mockBlock.Position, []byte(mockBlock.Content))
+				mock.
+					ExpectQuery("SELECT blockid, type, position, content FROM block").
+					WithArgs(noteToken).
+					WillReturnRows(rows)
+			},
+			expected: func(actualBlocks []entity.Block, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, []entity.Block{mockBlock}, actualBlocks)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT blockid, type, position, content FROM block").
+					WithArgs(noteToken).
+					WillReturnError(fmt.Errorf("internal error"))
+			},
+			expected: func(actualBlocks []entity.Block, actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewBlocksStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			blocks, err := repo.AllByNote(noteToken)
+			tc.expected(blocks, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
@@ -0,0 +1,175 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoCommentInDB = errors.New("no comment in DB with this id")
+
+type CommentsStorage struct {
+	DB *sql.DB
+}
+
+func NewCommentsStorage(db *sql.DB) *CommentsStorage {
+	return &CommentsStorage{
+		DB: db,
+	}
+}
+
+const querySaveComment = `INSERT INTO comment(noteid, parentid, userid, body, blockid, anchorfrom, anchorto, createdat, updatedat)
+VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $8)
+RETURNING commentid`
+
+func (store *CommentsStorage) Save(comment entity.Comment) (int, error) {
+	var blockID sql.NullString
+	var from, to sql.NullInt64
+	if comment.Anchor != nil {
+		blockID = sql.NullString{String: comment.Anchor.BlockID, Valid: comment.Anchor.BlockID != ""}
+		from = sql.NullInt64{Int64: int64(comment.Anchor.From), Valid: true}
+		to = sql.NullInt64{Int64: int64(comment.Anchor.To), Valid: true}
+	}
+
+	var commentID int
+	err := store.DB.QueryRow(querySaveComment, comment.NoteToken, comment.ParentID, comment.UserID, comment.Body,
+		blockID, from, to, comment.CreatedAt).Scan(&commentID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Save",
+			"noteToken": comment.NoteToken,
+		}).Error(err)
+		return 0, err
+	}
+	return commentID, nil
+}
+
+const querySelectComment = `SELECT commentid, noteid, COALESCE(parentid, 0), COALESCE(userid, ''), body,
+	blockid, anchorfrom, anchorto, resolved, createdat, updatedat
+FROM comment`
+
+func scanComment(row scanner) (entity.Comment, error) {
+	comment := entity.Comment{}
+	var blockID sql.NullString
+	var from, to sql.NullInt64
+	err := row.Scan(&comment.ID, &comment.NoteToken, &comment.ParentID, &comment.UserID, &comment.Body,
+		&blockID, &from, &to, &comment.Resolved, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+	if from.Valid && to.Valid {
+		comment.Anchor = &entity.CommentAnchor{
+			BlockID: blockID.String,
+			From:    int(from.Int64),
+			To:      int(to.Int64),
+		}
+	}
+	return comment, nil
+}
+
+const queryFindComment = querySelectComment + " WHERE commentid = $1"
+
+func (store *CommentsStorage) Find(commentID int) (entity.Comment, error) {
+	comment, err := scanComment(store.DB.QueryRow(queryFindComment, commentID))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Find",
+			"commentID": commentID,
+		}).Warning(err)
+		return entity.Comment{}, ErrNoCommentInDB
+	}
+	return comment, nil
+}
+
+const queryUpdateComment = "UPDATE comment SET body = $1, updatedat = $2 WHERE commentid = $3"
+
+func (store *CommentsStorage) Update(comment entity.Comment) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Update",
+		"commentID": comment.ID,
+	})
+
+	result, err := store.DB.Exec(queryUpdateComment, comment.Body, comment.UpdatedAt, comment.ID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoCommentInDB)
+		return ErrNoCommentInDB
+	}
+	return nil
+}
+
+const queryDeleteComment = "DELETE FROM comment WHERE commentid = $1"
+
+func (store *CommentsStorage) Delete(commentID int) error {
+	if _, err := store.DB.Exec(queryDeleteComment, commentID); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Delete",
+			"commentID": commentID,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const querySetResolved = "UPDATE comment SET resolved = $1 WHERE commentid = $2"
+
+func (store *CommentsStorage) SetResolved(commentID int, resolved bool) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "SetResolved",
+		"commentID": commentID,
+	})
+
+	result, err := store.DB.Exec(querySetResolved, resolved, commentID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoCommentInDB)
+		return ErrNoCommentInDB
+	}
+	return nil
+}
+
+const queryAllComments = querySelectComment + " WHERE noteid = $1 ORDER BY createdat, commentid"
+
+func (store *CommentsStorage) AllByNote(noteToken string) ([]entity.Comment, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "AllByNote",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryAllComments, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []entity.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,121 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestSaveComment(t *testing.T) {
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	var mockComment = entity.Comment{
+		NoteToken: "1",
+		UserID:    "101",
+		Body:      "Typo here",
+		Anchor:    &entity.CommentAnchor{From: 2, To: 8},
+		CreatedAt: createdAt,
+	}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(int, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO comment").
+					WithArgs("1", 0, "101", "Typo here", nil, 2, 8, createdAt).
+					WillReturnRows(sqlmock.NewRows([]string{"commentid"}).AddRow(5))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 5, actualID)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO comment").
+					WithArgs("1", 0, "101", "Typo here", nil, 2, 8, createdAt).
+					WillReturnError(fmt.Errorf("insert or update violates foreign key constraint"))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, fmt.Errorf("insert or update violates foreign key constraint"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewCommentsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			id, err := repo.Save(mockComment)
+			tc.expected(id, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestAllCommentsByNote(t *testing.T) {
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"commentid", "noteid", "parentid", "userid", "body", "blockid", "anchorfrom", "anchorto",
+		"resolved", "createdat", "updatedat"}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func([]entity.Comment, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "1", 0, "101", "Typo here", "abc", 2, 8, true, createdAt, createdAt).
+					AddRow(2, "1", 1, "102", "Fixed", nil, nil, nil, false, createdAt, createdAt)
+				mock.
+					ExpectQuery("SELECT commentid").
+					WithArgs("1").
+					WillReturnRows(rows)
+			},
+			expected: func(actualComments []entity.Comment, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, []entity.Comment{
+					{ID: 1, NoteToken: "1", UserID: "101", Body: "Typo here", Resolved: true,
+						Anchor: &entity.CommentAnchor{BlockID: "abc", From: 2, To: 8}, CreatedAt: createdAt, UpdatedAt: createdAt},
+					{ID: 2, NoteToken: "1", ParentID: 1, UserID: "102", Body: "Fixed", CreatedAt: createdAt, UpdatedAt: createdAt},
+				}, actualComments)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT commentid").
+					WithArgs("1").
+					WillReturnError(fmt.Errorf("connection refused"))
+			},
+			expected: func(actualComments []entity.Comment, actualErr error) {
+				require.Equal(t, fmt.Errorf("connection refused"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewCommentsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			comments, err := repo.AllByNote("1")
+			tc.expected(comments, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
@@ -0,0 +1,137 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+type NoteLinksStorage struct {
+	DB *sql.DB
+}
+
+func NewNoteLinksStorage(db *sql.DB) *NoteLinksStorage {
+	return &NoteLinksStorage{
+		DB: db,
+	}
+}
+
+const (
+	queryDeleteLinksFrom = "DELETE FROM notelink WHERE fromnoteid = $1"
+	queryInsertLinks     = "INSERT INTO notelink(fromnoteid, tonoteid) SELECT $1, unnest($2::varchar[]) ON CONFLICT DO NOTHING"
+)
+
+// Replace sets the notes the note links to.
+func (store *NoteLinksStorage) Replace(fromToken string, toTokens []string) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Replace",
+		"noteToken": fromToken,
+	})
+
+	tx, err := store.DB.Begin()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryDeleteLinksFrom, fromToken); err != nil {
+		logger.Error(err)
+		return err
+	}
+	if len(toTokens) != 0 {
+		if _, err := tx.Exec(queryInsertLinks, fromToken, pq.Array(toTokens)); err != nil {
+			logger.Error(err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const queryDeleteNoteLinks = "DELETE FROM notelink WHERE fromnoteid = $1 OR tonoteid = $1"
+
+func (store *NoteLinksStorage) Delete(noteToken string) error {
+	if _, err := store.DB.Exec(queryDeleteNoteLinks, noteToken); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Delete",
+			"noteToken": noteToken,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryBacklinks = `SELECT fromnoteid FROM notelink JOIN note ON notelink.fromnoteid = note.noteid
+WHERE tonoteid = $1 AND deletedat IS NULL ORDER BY note.name, fromnoteid`
+
+// Backlinks returns the notes outside the trash that link to the note.
+func (store *NoteLinksStorage) Backlinks(noteToken string) ([]string, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Backlinks",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryBacklinks, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+const queryLinksByNotes = `SELECT fromnoteid, tonoteid FROM notelink
+WHERE fromnoteid = ANY($1) AND tonoteid = ANY($1) ORDER BY fromnoteid, tonoteid`
+
+// AllByNotes returns the links between the given notes.
+func (store *NoteLinksStorage) AllByNotes(noteTokens []string) ([]entity.NoteLink, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AllByNotes",
+	})
+
+	rows, err := store.DB.Query(queryLinksByNotes, pq.Array(noteTokens))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []entity.NoteLink
+	for rows.Next() {
+		var link entity.NoteLink
+		if err := rows.Scan(&link.From, &link.To); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return links, nil
+}
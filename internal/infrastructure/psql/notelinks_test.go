@@ -0,0 +1,67 @@
+package psql
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+)
+
+func TestReplaceNoteLinks(t *testing.T) {
+	cases := map[string]struct {
+		inTokens []string
+		prepare  func(sqlmock.Sqlmock)
+		expected error
+	}{
+		"Success": {
+			inTokens: []string{"2", "3"},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM notelink").
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO notelink").
+					WithArgs("1", pq.Array([]string{"2", "3"})).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			expected: nil,
+		},
+		"No links": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM notelink").
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: nil,
+		},
+		"Error": {
+			inTokens: []string{"2"},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM notelink").
+					WithArgs("1").
+					WillReturnError(fmt.Errorf("internal error"))
+				mock.ExpectRollback()
+			},
+			expected: fmt.Errorf("internal error"),
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNoteLinksStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			require.Equal(t, tc.expected, repo.Replace("1", tc.inTokens))
+		})
+		log.Println("SUCCESS")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	log "github.com/sirupsen/logrus"
+	"time"
 )
 
 const packageName = "psql"
@@ -21,12 +22,17 @@ func NewNotesStorage(db *sql.DB) *NotesStorage {
 	}
 }
 
-const queryFindNote = "SELECT name, body FROM note WHERE NoteID = $1"
+const queryFindNote = `SELECT name, body, COALESCE(parent, ''), icontype, icon, cover, version, createdat, updatedat,
+	COALESCE(createdby, ''), COALESCE(lasteditedby, ''), deletedat
+FROM note WHERE NoteID = $1`
 
 func (store *NotesStorage) Find(token string) (entity.Note, error) {
 	row := store.DB.QueryRow(queryFindNote, token)
 	note := entity.Note{}
-	if err := row.Scan(&note.Name, &note.Body); err != nil {
+	var iconType, icon string
+	var deletedAt sql.NullTime
+	if err := row.Scan(&note.Name, &note.Body, &note.Parent, &iconType, &icon, &note.Cover, &note.Version,
+		&note.CreatedAt, &note.UpdatedAt, &note.CreatedBy, &note.LastEditedBy, &deletedAt); err != nil {
 		log.WithFields(log.Fields{
 			"package":   packageName,
 			"function":  "Find",
@@ -34,13 +40,19 @@ func (store *NotesStorage) Find(token string) (entity.Note, error) {
 		}).Warning(err)
 		return entity.Note{}, ErrNoNoteInDB
 	}
+	note.Icon = entity.NewNoteIcon(iconType, icon)
+	if deletedAt.Valid {
+		note.DeletedAt = &deletedAt.Time
+	}
 	return note, nil
 }
 
-const querySaveNote = "INSERT INTO note(noteID, name, body) VALUES ($1, $2, $3)"
+const querySaveNote = `INSERT INTO note(noteID, name, body, parent, createdat, updatedat, createdby, lasteditedby)
+VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), NULLIF($8, ''))`
 
 func (store *NotesStorage) Save(token string, note entity.Note) error {
-	if _, err := store.DB.Exec(querySaveNote, token, note.Name, note.Body); err != nil {
+	if _, err := store.DB.Exec(querySaveNote, token, note.Name, note.Body, note.Parent,
+		note.CreatedAt, note.UpdatedAt, note.CreatedBy, note.LastEditedBy); err != nil {
 		log.WithFields(log.Fields{
 			"package":   packageName,
 			"function":  "Save",
@@ -52,17 +64,33 @@ func (store *NotesStorage) Save(token string, note entity.Note) error {
 	return nil
 }
 
-const queryUpdateNote = "UPDATE note SET name = $1, body = $2 WHERE noteID = $3"
+// queryUpdateNote only updates the note if it still has the expected
+// version, a zero version skips the check.
+const queryUpdateNote = `UPDATE note SET name = $1, body = $2, updatedat = $3, lasteditedby = NULLIF($4, ''), version = version + 1
+WHERE noteID = $5 AND ($6 = 0 OR version = $6)
+RETURNING version`
 
-func (store *NotesStorage) Update(token string, note entity.Note) error {
-	if _, err := store.DB.Exec(queryUpdateNote, note.Name, note.Body, token); err != nil {
-		log.WithFields(log.Fields{
-			"package":  packageName,
-			"function": "Update",
-		}).Error(err)
-		return err
+// Update saves the note if note.Version matches the stored version and
+// returns the new version. ErrNoteVersionConflict is returned otherwise.
+func (store *NotesStorage) Update(token string, note entity.Note) (int, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Update",
+		"noteToken": token,
+	})
+
+	var version int
+	err := store.DB.QueryRow(queryUpdateNote, note.Name, note.Body, note.UpdatedAt, note.LastEditedBy, token, note.Version).
+		Scan(&version)
+	if err == sql.ErrNoRows {
+		logger.Warning(entity.ErrNoteVersionConflict)
+		return 0, entity.ErrNoteVersionConflict
 	}
-	return nil
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+	return version, nil
 }
 
 const queryDeleteNote = "DELETE FROM note where noteid = $1"
@@ -77,3 +105,202 @@ func (store *NotesStorage) Delete(token string) error {
 	}
 	return nil
 }
+
+// queryLockMoves serializes moves, so that two concurrent moves cannot each
+// pass the cycle check of queryMoveNote and together make a cycle.
+const queryLockMoves = "SELECT pg_advisory_xact_lock(hashtext('note.parent'))"
+
+// queryMoveNote does not move the note if it is the new parent or one of
+// its ancestors.
+const queryMoveNote = `WITH RECURSIVE ancestors AS (
+	SELECT noteid, parent FROM note WHERE noteid = $1
+	UNION
+	SELECT note.noteid, note.parent FROM note JOIN ancestors ON note.noteid = ancestors.parent
+)
+UPDATE note SET parent = NULLIF($1, '') WHERE noteid = $2
+AND NOT EXISTS (SELECT 1 FROM ancestors WHERE noteid = $2)`
+
+// Move returns entity.ErrNoteCycle if the parent is in the subtree of the note.
+func (store *NotesStorage) Move(token string, parentToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":     packageName,
+		"function":    "Move",
+		"noteToken":   token,
+		"parentToken": parentToken,
+	})
+
+	tx, err := store.DB.Begin()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryLockMoves); err != nil {
+		logger.Error(err)
+		return err
+	}
+	result, err := tx.Exec(queryMoveNote, parentToken, token)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(entity.ErrNoteCycle)
+		return entity.ErrNoteCycle
+	}
+
+	return tx.Commit()
+}
+
+const querySetNoteIcon = "UPDATE note SET icontype = $1, icon = $2 WHERE noteid = $3"
+
+// SetIcon replaces the icon of the note, a nil icon removes it.
+func (store *NotesStorage) SetIcon(token string, icon *entity.NoteIcon) error {
+	var iconType, value string
+	if icon != nil {
+		iconType, value = icon.Type, icon.Value
+	}
+	if _, err := store.DB.Exec(querySetNoteIcon, iconType, value, token); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "SetIcon",
+			"noteToken": token,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const querySetNoteCover = "UPDATE note SET cover = $1 WHERE noteid = $2"
+
+func (store *NotesStorage) SetCover(token string, cover string) error {
+	if _, err := store.DB.Exec(querySetNoteCover, cover, token); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "SetCover",
+			"noteToken": token,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const querySubtree = `WITH RECURSIVE subtree AS (
+	SELECT noteid FROM note WHERE noteid = $1
+	UNION
+	SELECT note.noteid FROM note JOIN subtree ON note.parent = subtree.noteid
+)
+SELECT noteid FROM subtree`
+
+func (store *NotesStorage) Subtree(token string) ([]string, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Subtree",
+		"noteToken": token,
+	})
+
+	rows, err := store.DB.Query(querySubtree, token)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var noteToken string
+		if err := rows.Scan(&noteToken); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		tokens = append(tokens, noteToken)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, ErrNoNoteInDB
+	}
+
+	return tokens, nil
+}
+
+// queryTrashNote marks the whole subtree as deleted. Subpages that are
+// already in the trash keep their own deletion time.
+const queryTrashNote = `WITH RECURSIVE subtree AS (
+	SELECT noteid FROM note WHERE noteid = $1
+	UNION
+	SELECT note.noteid FROM note JOIN subtree ON note.parent = subtree.noteid
+)
+UPDATE note SET deletedat = $2 WHERE noteid IN (SELECT noteid FROM subtree) AND deletedat IS NULL`
+
+func (store *NotesStorage) Trash(token string, deletedAt time.Time) error {
+	if _, err := store.DB.Exec(queryTrashNote, token, deletedAt); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Trash",
+			"noteToken": token,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+// queryRestoreNote brings back the subpages that were trashed together with
+// the note, i.e. the ones that share its deletion time.
+const queryRestoreNote = `WITH RECURSIVE subtree AS (
+	SELECT noteid, deletedat FROM note WHERE noteid = $1
+	UNION
+	SELECT note.noteid, note.deletedat FROM note JOIN subtree ON note.parent = subtree.noteid
+	WHERE note.deletedat = subtree.deletedat
+)
+UPDATE note SET deletedat = NULL WHERE noteid IN (SELECT noteid FROM subtree)`
+
+func (store *NotesStorage) Restore(token string) error {
+	if _, err := store.DB.Exec(queryRestoreNote, token); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Restore",
+			"noteToken": token,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryExpiredTrash = "SELECT noteid FROM note WHERE deletedat < $1"
+
+func (store *NotesStorage) ExpiredTrash(before time.Time) ([]string, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "ExpiredTrash",
+	})
+
+	rows, err := store.DB.Query(queryExpiredTrash, before)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var noteToken string
+		if err := rows.Scan(&noteToken); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		tokens = append(tokens, noteToken)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
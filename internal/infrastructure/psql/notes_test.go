@@ -7,13 +7,16 @@ import (
 	"github.com/stretchr/testify/require"
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 	"testing"
+	"time"
 )
 
 func TestFindNotes(t *testing.T) {
 	const (
 		noteName = "name"
 		noteBody = "body of the note"
+		userID   = "1"
 	)
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
 
 	cases := map[string]struct {
 		inNoteToken string
@@ -23,18 +26,25 @@ func TestFindNotes(t *testing.T) {
 		"Success": {
 			inNoteToken: "1",
 			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
-				rows := sqlmock.NewRows([]string{"name", "body"})
-				rows = rows.AddRow(noteName, noteBody)
+				rows := sqlmock.NewRows([]string{"name", "body", "parent", "icontype", "icon", "cover", "version", "createdat", "updatedat", "createdby", "lasteditedby", "deletedat"})
+				rows = rows.AddRow(noteName, noteBody, "", entity.IconImage, "icon.png", "cover.png", 1, createdAt, createdAt, userID, userID, nil)
 				mock.
-					ExpectQuery("SELECT name, body FROM note WHERE").
+					ExpectQuery("SELECT name, body, COALESCE").
 					WithArgs(noteToken).
 					WillReturnRows(rows)
 			},
 			expected: func(actualNote entity.Note, actualErr error) {
 				require.Equal(t, nil, actualErr)
 				require.Equal(t, entity.Note{
-					Name: noteName,
-					Body: noteBody,
+					Name:         noteName,
+					Body:         noteBody,
+					Icon:         &entity.NoteIcon{Type: entity.IconImage, Value: "icon.png"},
+					Cover:        "cover.png",
+					Version:      1,
+					CreatedAt:    createdAt,
+					UpdatedAt:    createdAt,
+					CreatedBy:    userID,
+					LastEditedBy: userID,
 				}, actualNote)
 			},
 		},
@@ -68,7 +78,9 @@ func TestSaveNote(t *testing.T) {
 	const (
 		noteName = "name"
 		noteBody = "body of the note"
+		userID   = "1"
 	)
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
 
 	cases := map[string]struct {
 		inNoteToken string
@@ -80,7 +92,7 @@ func TestSaveNote(t *testing.T) {
 			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
 				mock.
 					ExpectExec("INSERT INTO note").
-					WithArgs(noteToken, noteName, noteBody).
+					WithArgs(noteToken, noteName, noteBody, "", createdAt, createdAt, userID, userID).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expected: func(actualErr error) {
@@ -92,7 +104,7 @@ func TestSaveNote(t *testing.T) {
 			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
 				mock.
 					ExpectExec("INSERT INTO note").
-					WithArgs(noteToken, noteName, noteBody).
+					WithArgs(noteToken, noteName, noteBody, "", createdAt, createdAt, userID, userID).
 					WillReturnError(fmt.Errorf("already has note with this token"))
 			},
 			expected: func(actualErr error) {
@@ -110,8 +122,12 @@ func TestSaveNote(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			tc.prepare(mock, tc.inNoteToken)
 			err := repo.Save(tc.inNoteToken, entity.Note{
-				Name: noteName,
-				Body: noteBody,
+				Name:         noteName,
+				Body:         noteBody,
+				CreatedAt:    createdAt,
+				UpdatedAt:    createdAt,
+				CreatedBy:    userID,
+				LastEditedBy: userID,
 			})
 			tc.expected(err)
 		})
@@ -123,8 +139,79 @@ func TestUpdateNote(t *testing.T) {
 	const (
 		noteName = "updated name"
 		noteBody = "updated body of the note"
+		userID   = "2"
 	)
+	updatedAt := time.Date(2021, 11, 2, 12, 0, 0, 0, time.UTC)
 
+	cases := map[string]struct {
+		inNoteToken string
+		inVersion   int
+		prepare     func(sqlmock.Sqlmock, string, int)
+		expected    func(int, error)
+	}{
+		"Success": {
+			inNoteToken: "1",
+			inVersion:   2,
+			prepare: func(mock sqlmock.Sqlmock, noteToken string, version int) {
+				mock.
+					ExpectQuery("UPDATE note").
+					WithArgs(noteName, noteBody, updatedAt, userID, noteToken, version).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+			},
+			expected: func(actualVersion int, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 3, actualVersion)
+			},
+		},
+		"Version conflict": {
+			inNoteToken: "1",
+			inVersion:   1,
+			prepare: func(mock sqlmock.Sqlmock, noteToken string, version int) {
+				mock.
+					ExpectQuery("UPDATE note").
+					WithArgs(noteName, noteBody, updatedAt, userID, noteToken, version).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}))
+			},
+			expected: func(actualVersion int, actualErr error) {
+				require.Equal(t, entity.ErrNoteVersionConflict, actualErr)
+			},
+		},
+		"Error": {
+			inNoteToken: "1",
+			prepare: func(mock sqlmock.Sqlmock, noteToken string, version int) {
+				mock.
+					ExpectQuery("UPDATE note").
+					WithArgs(noteName, noteBody, updatedAt, userID, noteToken, version).
+					WillReturnError(fmt.Errorf("connection refused"))
+			},
+			expected: func(actualVersion int, actualErr error) {
+				require.Equal(t, fmt.Errorf("connection refused"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock, tc.inNoteToken, tc.inVersion)
+			version, err := repo.Update(tc.inNoteToken, entity.Note{
+				Name:         noteName,
+				Body:         noteBody,
+				Version:      tc.inVersion,
+				UpdatedAt:    updatedAt,
+				LastEditedBy: userID,
+			})
+			tc.expected(version, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestDeleteNote(t *testing.T) {
 	cases := map[string]struct {
 		inNoteToken string
 		prepare     func(sqlmock.Sqlmock, string)
@@ -134,8 +221,8 @@ func TestUpdateNote(t *testing.T) {
 			inNoteToken: "1",
 			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
 				mock.
-					ExpectExec("UPDATE note").
-					WithArgs(noteName, noteBody, noteToken).
+					ExpectExec("DELETE FROM note").
+					WithArgs(noteToken).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expected: func(actualErr error) {
@@ -146,8 +233,8 @@ func TestUpdateNote(t *testing.T) {
 			inNoteToken: "1",
 			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
 				mock.
-					ExpectExec("UPDATE note").
-					WithArgs(noteName, noteBody, noteToken).
+					ExpectExec("DELETE FROM note").
+					WithArgs(noteToken).
 					WillReturnError(fmt.Errorf("no note with this token"))
 			},
 			expected: func(actualErr error) {
@@ -164,17 +251,16 @@ func TestUpdateNote(t *testing.T) {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			tc.prepare(mock, tc.inNoteToken)
-			err := repo.Update(tc.inNoteToken, entity.Note{
-				Name: noteName,
-				Body: noteBody,
-			})
+			err := repo.Delete(tc.inNoteToken)
 			tc.expected(err)
 		})
 		log.Println("SUCCESS")
 	}
 }
 
-func TestDeleteNote(t *testing.T) {
+func TestMoveNote(t *testing.T) {
+	const parentToken = "2"
+
 	cases := map[string]struct {
 		inNoteToken string
 		prepare     func(sqlmock.Sqlmock, string)
@@ -183,25 +269,197 @@ func TestDeleteNote(t *testing.T) {
 		"Success": {
 			inNoteToken: "1",
 			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
+				mock.ExpectBegin()
 				mock.
-					ExpectExec("DELETE FROM note").
-					WithArgs(noteToken).
+					ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.
+					ExpectExec("UPDATE note SET parent").
+					WithArgs(parentToken, noteToken).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 			expected: func(actualErr error) {
 				require.Equal(t, nil, actualErr)
 			},
 		},
+		"Cycle": {
+			inNoteToken: "1",
+			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
+				mock.ExpectBegin()
+				mock.
+					ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.
+					ExpectExec("UPDATE note SET parent").
+					WithArgs(parentToken, noteToken).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, entity.ErrNoteCycle, actualErr)
+			},
+		},
+		"Error": {
+			inNoteToken: "1",
+			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
+				mock.ExpectBegin()
+				mock.
+					ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.
+					ExpectExec("UPDATE note SET parent").
+					WithArgs(parentToken, noteToken).
+					WillReturnError(fmt.Errorf("internal error"))
+				mock.ExpectRollback()
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock, tc.inNoteToken)
+			err := repo.Move(tc.inNoteToken, parentToken)
+			tc.expected(err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestSetNoteIcon(t *testing.T) {
+	cases := map[string]struct {
+		inIcon   *entity.NoteIcon
+		prepare  func(sqlmock.Sqlmock)
+		expected func(error)
+	}{
+		"Emoji": {
+			inIcon: &entity.NoteIcon{Type: entity.IconEmoji, Value: "🔥"},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE note SET icontype").
+					WithArgs(entity.IconEmoji, "🔥", "1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, nil, actualErr)
+			},
+		},
+		"Remove": {
+			inIcon: nil,
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE note SET icontype").
+					WithArgs("", "", "1").
+					WillReturnError(fmt.Errorf("internal error"))
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			tc.expected(repo.SetIcon("1", tc.inIcon))
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	cases := map[string]struct {
+		inNoteToken string
+		prepare     func(sqlmock.Sqlmock, string)
+		expected    func([]string, error)
+	}{
+		"Success": {
+			inNoteToken: "1",
+			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
+				rows := sqlmock.NewRows([]string{"noteid"})
+				rows = rows.AddRow("1").AddRow("2").AddRow("3")
+				mock.
+					ExpectQuery("WITH RECURSIVE subtree").
+					WithArgs(noteToken).
+					WillReturnRows(rows)
+			},
+			expected: func(actualTokens []string, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, []string{"1", "2", "3"}, actualTokens)
+			},
+		},
 		"No note in DB": {
 			inNoteToken: "1",
 			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
 				mock.
-					ExpectExec("DELETE FROM note").
+					ExpectQuery("WITH RECURSIVE subtree").
 					WithArgs(noteToken).
-					WillReturnError(fmt.Errorf("no note with this token"))
+					WillReturnRows(sqlmock.NewRows([]string{"noteid"}))
+			},
+			expected: func(actualTokens []string, actualErr error) {
+				require.Equal(t, ErrNoNoteInDB, actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock, tc.inNoteToken)
+			tokens, err := repo.Subtree(tc.inNoteToken)
+			tc.expected(tokens, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestTrashNote(t *testing.T) {
+	deletedAt := time.Now()
+
+	cases := map[string]struct {
+		inNoteToken string
+		prepare     func(sqlmock.Sqlmock, string)
+		expected    func(error)
+	}{
+		"Success": {
+			inNoteToken: "1",
+			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
+				mock.
+					ExpectExec("UPDATE note SET deletedat").
+					WithArgs(noteToken, deletedAt).
+					WillReturnResult(sqlmock.NewResult(1, 2))
 			},
 			expected: func(actualErr error) {
-				require.Equal(t, fmt.Errorf("no note with this token"), actualErr)
+				require.Equal(t, nil, actualErr)
+			},
+		},
+		"Error": {
+			inNoteToken: "1",
+			prepare: func(mock sqlmock.Sqlmock, noteToken string) {
+				mock.
+					ExpectExec("UPDATE note SET deletedat").
+					WithArgs(noteToken, deletedAt).
+					WillReturnError(fmt.Errorf("internal error"))
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
 			},
 		},
 	}
@@ -214,9 +472,58 @@ func TestDeleteNote(t *testing.T) {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			tc.prepare(mock, tc.inNoteToken)
-			err := repo.Delete(tc.inNoteToken)
+			err := repo.Trash(tc.inNoteToken, deletedAt)
 			tc.expected(err)
 		})
 		log.Println("SUCCESS")
 	}
 }
+
+func TestExpiredTrash(t *testing.T) {
+	before := time.Now()
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func([]string, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"noteid"})
+				rows = rows.AddRow("1").AddRow("2")
+				mock.
+					ExpectQuery("SELECT noteid FROM note WHERE deletedat").
+					WithArgs(before).
+					WillReturnRows(rows)
+			},
+			expected: func(actualTokens []string, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, []string{"1", "2"}, actualTokens)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT noteid FROM note WHERE deletedat").
+					WithArgs(before).
+					WillReturnError(fmt.Errorf("internal error"))
+			},
+			expected: func(actualTokens []string, actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			tokens, err := repo.ExpiredTrash(before)
+			tc.expected(tokens, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
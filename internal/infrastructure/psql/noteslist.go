@@ -0,0 +1,107 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
+)
+
+type sortKey struct {
+	column string
+	cast   string
+}
+
+// noteSortKeys are the columns the notes list is ordered by. Pinned notes
+// go first in the manual order.
+var noteSortKeys = map[string][]sortKey{
+	entity.SortPosition: {{"NOT pinned", "boolean"}, {"position", "integer"}},
+	entity.SortName:     {{"name", "varchar"}},
+	entity.SortCreated:  {{"note.createdat", "timestamptz"}},
+	entity.SortUpdated:  {{"note.updatedat", "timestamptz"}},
+}
+
+// sortKeys makes the order total by adding the note token to the keys.
+func sortKeys(sort string) []sortKey {
+	keys, ok := noteSortKeys[sort]
+	if !ok {
+		keys = noteSortKeys[entity.SortPosition]
+	}
+	result := make([]sortKey, 0, len(keys)+1)
+	result = append(result, keys...)
+	return append(result, sortKey{"note.noteid", "varchar"})
+}
+
+// notesListQuery builds a keyset-paginated query: the cursor holds the sort
+// keys of the last returned note, and the page starts right after it.
+func notesListQuery(userID string, filter entity.NotesFilter) (string, []interface{}, error) {
+	keys := sortKeys(filter.Sort)
+	args := []interface{}{userID, pq.Array(filter.Tokens), filter.Favorites, filter.Body, entity.BodyPreviewLength}
+
+	columns := make([]string, len(keys))
+	order := make([]string, len(keys))
+	direction, comparison := "", ">"
+	if filter.Desc {
+		direction, comparison = " DESC", "<"
+	}
+	for i, key := range keys {
+		columns[i] = key.column
+		order[i] = key.column + direction
+	}
+
+	var after string
+	if filter.Cursor != "" {
+		values, err := decodeCursor(filter.Sort, filter.Cursor)
+		if err != nil || len(values) != len(keys) {
+			return "", nil, entity.ErrInvalidCursor
+		}
+		placeholders := make([]string, len(keys))
+		for i, key := range keys {
+			args = append(args, values[i])
+			placeholders[i] = fmt.Sprintf("$%d::%s", len(args), key.cast)
+		}
+		after = fmt.Sprintf("\n\tAND (%s) %s (%s)", strings.Join(columns, ", "), comparison, strings.Join(placeholders, ", "))
+	}
+
+	var limit string
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit+1)
+		limit = fmt.Sprintf("\nLIMIT $%d", len(args))
+	}
+
+	selected := make([]string, len(keys))
+	for i, column := range columns {
+		selected[i] = "(" + column + ")::text"
+	}
+
+	query := fmt.Sprintf(queryFindNotes, strings.Join(selected, ", "), after, strings.Join(order, ", "), limit)
+	return query, args, nil
+}
+
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func encodeCursor(sort string, values []string) string {
+	data, _ := json.Marshal(cursor{Sort: sort, Values: values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor rejects cursors issued for another sort order.
+func decodeCursor(sort string, encoded string) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.Sort != sort {
+		return nil, entity.ErrInvalidCursor
+	}
+	return c.Values, nil
+}
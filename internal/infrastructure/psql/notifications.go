@@ -0,0 +1,192 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoNotificationInDB = errors.New("no notification in DB with this id")
+
+type MentionsStorage struct {
+	DB *sql.DB
+}
+
+func NewMentionsStorage(db *sql.DB) *MentionsStorage {
+	return &MentionsStorage{
+		DB: db,
+	}
+}
+
+const querySaveMention = "INSERT INTO mention(noteid, commentid, userid) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING"
+
+func (store *MentionsStorage) Save(mention entity.Mention) error {
+	if _, err := store.DB.Exec(querySaveMention, mention.NoteToken, mention.CommentID, mention.UserID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Save",
+			"mention":  mention,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryDeleteMention = "DELETE FROM mention WHERE noteid = $1 AND commentid = $2 AND userid = $3"
+
+func (store *MentionsStorage) Delete(mention entity.Mention) error {
+	if _, err := store.DB.Exec(queryDeleteMention, mention.NoteToken, mention.CommentID, mention.UserID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Delete",
+			"mention":  mention,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryMentioned = "SELECT userid FROM mention WHERE noteid = $1 AND commentid = $2"
+
+func (store *MentionsStorage) Mentioned(noteToken string, commentID int) ([]string, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Mentioned",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryMentioned, noteToken, commentID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+type NotificationsStorage struct {
+	DB *sql.DB
+}
+
+func NewNotificationsStorage(db *sql.DB) *NotificationsStorage {
+	return &NotificationsStorage{
+		DB: db,
+	}
+}
+
+const querySaveNotification = `INSERT INTO notification(userid, type, noteid, commentid, authorid, createdat)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6) RETURNING notificationid`
+
+func (store *NotificationsStorage) Save(notification entity.Notification) (int, error) {
+	var notificationID int
+	err := store.DB.QueryRow(querySaveNotification, notification.UserID, notification.Type, notification.NoteToken,
+		notification.CommentID, notification.AuthorID, notification.CreatedAt).Scan(&notificationID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Save",
+		}).Error(err)
+		return 0, err
+	}
+	return notificationID, nil
+}
+
+const queryNotificationsByUserID = `SELECT notificationid, userid, type, noteid, commentid, COALESCE(authorid, ''), isread, createdat
+FROM notification WHERE userid = $1 ORDER BY createdat DESC, notificationid DESC LIMIT $2`
+
+func (store *NotificationsStorage) AllByUserID(userID string, limit int) ([]entity.Notification, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AllByUserID",
+	})
+
+	rows, err := store.DB.Query(queryNotificationsByUserID, userID, limit)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []entity.Notification
+	for rows.Next() {
+		var notification entity.Notification
+		if err := rows.Scan(&notification.ID, &notification.UserID, &notification.Type, &notification.NoteToken,
+			&notification.CommentID, &notification.AuthorID, &notification.Read, &notification.CreatedAt); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+const queryCountUnread = "SELECT COUNT(*) FROM notification WHERE userid = $1 AND NOT isread"
+
+func (store *NotificationsStorage) CountUnread(userID string) (int, error) {
+	var count int
+	if err := store.DB.QueryRow(queryCountUnread, userID).Scan(&count); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "CountUnread",
+		}).Error(err)
+		return 0, err
+	}
+	return count, nil
+}
+
+const queryMarkRead = "UPDATE notification SET isread = true WHERE userid = $1 AND notificationid = $2"
+
+func (store *NotificationsStorage) MarkRead(userID string, notificationID int) error {
+	logger := log.WithFields(log.Fields{
+		"package":        packageName,
+		"function":       "MarkRead",
+		"notificationID": notificationID,
+	})
+
+	result, err := store.DB.Exec(queryMarkRead, userID, notificationID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoNotificationInDB)
+		return ErrNoNotificationInDB
+	}
+	return nil
+}
+
+const queryMarkAllRead = "UPDATE notification SET isread = true WHERE userid = $1 AND NOT isread"
+
+func (store *NotificationsStorage) MarkAllRead(userID string) error {
+	if _, err := store.DB.Exec(queryMarkAllRead, userID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "MarkAllRead",
+		}).Error(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestSaveNotification(t *testing.T) {
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	var mockNotification = entity.Notification{
+		UserID:    "102",
+		Type:      entity.NotificationMention,
+		NoteToken: "1",
+		AuthorID:  "101",
+		CreatedAt: createdAt,
+	}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(int, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO notification").
+					WithArgs("102", entity.NotificationMention, "1", 0, "101", createdAt).
+					WillReturnRows(sqlmock.NewRows([]string{"notificationid"}).AddRow(3))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 3, actualID)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO notification").
+					WithArgs("102", entity.NotificationMention, "1", 0, "101", createdAt).
+					WillReturnError(fmt.Errorf("insert or update violates foreign key constraint"))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, fmt.Errorf("insert or update violates foreign key constraint"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNotificationsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			id, err := repo.Save(mockNotification)
+			tc.expected(id, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected error
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE notification SET isread").
+					WithArgs("102", 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expected: nil,
+		},
+		"Not the user's notification": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE notification SET isread").
+					WithArgs("102", 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expected: ErrNoNotificationInDB,
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewNotificationsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			require.Equal(t, tc.expected, repo.MarkRead("102", 3))
+		})
+		log.Println("SUCCESS")
+	}
+}
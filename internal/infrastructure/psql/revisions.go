@@ -0,0 +1,109 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoRevisionInDB = errors.New("no revision of this note in DB")
+
+type RevisionsStorage struct {
+	DB *sql.DB
+}
+
+func NewRevisionsStorage(db *sql.DB) *RevisionsStorage {
+	return &RevisionsStorage{
+		DB: db,
+	}
+}
+
+const querySaveRevision = "INSERT INTO revision(noteid, userid, createdat, name, body, blocks) VALUES ($1, $2, $3, $4, $5, $6)"
+
+func (store *RevisionsStorage) Save(noteToken string, revision entity.Revision) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Save",
+		"noteToken": noteToken,
+	})
+
+	// Revisions without blocks are stored with NULL blocks.
+	var blocks interface{}
+	if revision.Blocks != nil {
+		data, err := json.Marshal(revision.Blocks)
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+		blocks = data
+	}
+
+	_, err := store.DB.Exec(querySaveRevision, noteToken, revision.UserID, revision.CreatedAt, revision.Name, revision.Body, blocks)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryFindRevision = "SELECT revisionid, COALESCE(userid, ''), createdat, name, body, blocks FROM revision WHERE noteid = $1 AND revisionid = $2"
+
+func (store *RevisionsStorage) Find(noteToken string, revisionID int) (entity.Revision, error) {
+	logger := log.WithFields(log.Fields{
+		"package":    packageName,
+		"function":   "Find",
+		"noteToken":  noteToken,
+		"revisionID": revisionID,
+	})
+
+	row := store.DB.QueryRow(queryFindRevision, noteToken, revisionID)
+	revision := entity.Revision{}
+	var blocks []byte
+	if err := row.Scan(&revision.ID, &revision.UserID, &revision.CreatedAt, &revision.Name, &revision.Body, &blocks); err != nil {
+		logger.Warning(err)
+		return entity.Revision{}, ErrNoRevisionInDB
+	}
+	if blocks != nil {
+		if err := json.Unmarshal(blocks, &revision.Blocks); err != nil {
+			logger.Error(err)
+			return entity.Revision{}, err
+		}
+	}
+	return revision, nil
+}
+
+const queryAllRevisions = "SELECT revisionid, COALESCE(userid, ''), createdat, name FROM revision WHERE noteid = $1 ORDER BY revisionid DESC"
+
+func (store *RevisionsStorage) AllByNote(noteToken string) ([]entity.Revision, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "AllByNote",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryAllRevisions, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []entity.Revision
+	for rows.Next() {
+		var revision entity.Revision
+		if err := rows.Scan(&revision.ID, &revision.UserID, &revision.CreatedAt, &revision.Name); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,189 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestSaveRevision(t *testing.T) {
+	const noteToken = "1"
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	var mockBlock = entity.Block{ID: "abcdef", Type: entity.BlockText, Content: json.RawMessage(`{"text":"hello"}`)}
+
+	cases := map[string]struct {
+		inRevision entity.Revision
+		prepare    func(sqlmock.Sqlmock)
+		expected   error
+	}{
+		"Success": {
+			inRevision: entity.Revision{UserID: "101", CreatedAt: createdAt, Name: "Plan", Body: "first", Blocks: []entity.Block{mockBlock}},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("INSERT INTO revision").
+					WithArgs(noteToken, "101", createdAt, "Plan", "first",
+						[]byte(`[{"id":"abcdef","type":"text","position":0,"content":{"text":"hello"}}]`)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			expected: nil,
+		},
+		"Without blocks": {
+			inRevision: entity.Revision{UserID: "101", CreatedAt: createdAt, Name: "Plan", Body: "first"},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("INSERT INTO revision").
+					WithArgs(noteToken, "101", createdAt, "Plan", "first", nil).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			expected: nil,
+		},
+		"Error": {
+			inRevision: entity.Revision{UserID: "101", CreatedAt: createdAt, Name: "Plan", Body: "first", Blocks: []entity.Block{}},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("INSERT INTO revision").
+					WillReturnError(fmt.Errorf("insert or update violates foreign key constraint"))
+			},
+			expected: fmt.Errorf("insert or update violates foreign key constraint"),
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewRevisionsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			require.Equal(t, tc.expected, repo.Save(noteToken, tc.inRevision))
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestFindRevision(t *testing.T) {
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"revisionid", "userid", "createdat", "name", "body", "blocks"}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(entity.Revision, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT revisionid").
+					WithArgs("1", 4).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(4, "101", createdAt, "Plan", "first",
+						[]byte(`[{"id":"abcdef","type":"text","position":0,"content":{"text":"hello"}}]`)))
+			},
+			expected: func(actual entity.Revision, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, entity.Revision{
+					ID:        4,
+					UserID:    "101",
+					CreatedAt: createdAt,
+					Name:      "Plan",
+					Body:      "first",
+					Blocks:    []entity.Block{{ID: "abcdef", Type: entity.BlockText, Content: json.RawMessage(`{"text":"hello"}`)}},
+				}, actual)
+			},
+		},
+		"Without blocks": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT revisionid").
+					WithArgs("1", 4).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(4, "101", createdAt, "Plan", "first", nil))
+			},
+			expected: func(actual entity.Revision, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, []entity.Block(nil), actual.Blocks)
+			},
+		},
+		"No revision": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT revisionid").
+					WithArgs("1", 4).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expected: func(actual entity.Revision, actualErr error) {
+				require.Equal(t, ErrNoRevisionInDB, actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewRevisionsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			revision, err := repo.Find("1", 4)
+			tc.expected(revision, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestAllRevisionsByNote(t *testing.T) {
+	createdAt := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"revisionid", "userid", "createdat", "name"}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func([]entity.Revision, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT revisionid").
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(5, "101", createdAt, "Plan v2").
+						AddRow(4, "", createdAt, "Plan"))
+			},
+			expected: func(actual []entity.Revision, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, []entity.Revision{
+					{ID: 5, UserID: "101", CreatedAt: createdAt, Name: "Plan v2"},
+					{ID: 4, CreatedAt: createdAt, Name: "Plan"},
+				}, actual)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT revisionid").
+					WithArgs("1").
+					WillReturnError(fmt.Errorf("internal error"))
+			},
+			expected: func(actual []entity.Revision, actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewRevisionsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			revisions, err := repo.AllByNote("1")
+			tc.expected(revisions, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
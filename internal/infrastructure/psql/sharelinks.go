@@ -0,0 +1,112 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoShareLinkInDB = errors.New("no share link in DB with this token")
+
+type ShareLinksStorage struct {
+	DB *sql.DB
+}
+
+func NewShareLinksStorage(db *sql.DB) *ShareLinksStorage {
+	return &ShareLinksStorage{
+		DB: db,
+	}
+}
+
+const querySaveShareLink = "INSERT INTO sharelink(sharetoken, noteid, createdby, expiresat, password) VALUES ($1, $2, $3, $4, $5)"
+
+func (store *ShareLinksStorage) Save(link entity.ShareLink) error {
+	_, err := store.DB.Exec(querySaveShareLink, link.Token, link.NoteToken, link.CreatedBy, link.ExpiresAt, link.Password)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Save",
+			"noteToken": link.NoteToken,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryFindShareLink = "SELECT sharetoken, noteid, COALESCE(createdby, ''), expiresat, password FROM sharelink WHERE sharetoken = $1"
+
+func (store *ShareLinksStorage) Find(shareToken string) (entity.ShareLink, error) {
+	link, err := scanShareLink(store.DB.QueryRow(queryFindShareLink, shareToken))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Find",
+		}).Warning(err)
+		return entity.ShareLink{}, ErrNoShareLinkInDB
+	}
+	return link, nil
+}
+
+const queryDeleteShareLink = "DELETE FROM sharelink WHERE sharetoken = $1"
+
+func (store *ShareLinksStorage) Delete(shareToken string) error {
+	if _, err := store.DB.Exec(queryDeleteShareLink, shareToken); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Delete",
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryAllShareLinks = "SELECT sharetoken, noteid, COALESCE(createdby, ''), expiresat, password FROM sharelink WHERE noteid = $1"
+
+func (store *ShareLinksStorage) AllByNote(noteToken string) ([]entity.ShareLink, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "AllByNote",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryAllShareLinks, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []entity.ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return links, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanShareLink(row scanner) (entity.ShareLink, error) {
+	link := entity.ShareLink{}
+	var expiresAt sql.NullTime
+	if err := row.Scan(&link.Token, &link.NoteToken, &link.CreatedBy, &expiresAt, &link.Password); err != nil {
+		return entity.ShareLink{}, err
+	}
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+	return link, nil
+}
@@ -0,0 +1,225 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoTagInDB = errors.New("no tag in DB with this id")
+
+type TagsStorage struct {
+	DB *sql.DB
+}
+
+func NewTagsStorage(db *sql.DB) *TagsStorage {
+	return &TagsStorage{
+		DB: db,
+	}
+}
+
+const querySaveTag = "INSERT INTO tag(userid, name, color) VALUES ($1, $2, $3) RETURNING tagid"
+
+func (store *TagsStorage) Save(tag entity.Tag) (int, error) {
+	var tagID int
+	if err := store.DB.QueryRow(querySaveTag, tag.UserID, tag.Name, tag.Color).Scan(&tagID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Save",
+			"tag":      tag,
+		}).Error(err)
+		return 0, err
+	}
+	return tagID, nil
+}
+
+const queryUpdateTag = "UPDATE tag SET name = $1, color = $2 WHERE tagid = $3"
+
+func (store *TagsStorage) Update(tag entity.Tag) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Update",
+		"tagID":    tag.ID,
+	})
+
+	result, err := store.DB.Exec(queryUpdateTag, tag.Name, tag.Color, tag.ID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoTagInDB)
+		return ErrNoTagInDB
+	}
+	return nil
+}
+
+const queryDeleteTag = "DELETE FROM tag WHERE tagid = $1"
+
+func (store *TagsStorage) Delete(tagID int) error {
+	if _, err := store.DB.Exec(queryDeleteTag, tagID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Delete",
+			"tagID":    tagID,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryFindTag = "SELECT tagid, userid, name, color FROM tag WHERE tagid = $1"
+
+func (store *TagsStorage) Find(tagID int) (entity.Tag, error) {
+	tag := entity.Tag{}
+	if err := store.DB.QueryRow(queryFindTag, tagID).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Find",
+			"tagID":    tagID,
+		}).Warning(err)
+		return entity.Tag{}, ErrNoTagInDB
+	}
+	return tag, nil
+}
+
+const queryAllTags = "SELECT tagid, userid, name, color FROM tag WHERE userid = $1 ORDER BY name"
+
+func (store *TagsStorage) AllByUserID(userID string) ([]entity.Tag, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AllByUserID",
+	})
+
+	rows, err := store.DB.Query(queryAllTags, userID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []entity.Tag
+	for rows.Next() {
+		var tag entity.Tag
+		if err := rows.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+const queryAttachTag = "INSERT INTO notetag(tagid, noteid) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+
+func (store *TagsStorage) Attach(tagID int, noteToken string) error {
+	if _, err := store.DB.Exec(queryAttachTag, tagID, noteToken); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Attach",
+			"tagID":     tagID,
+			"noteToken": noteToken,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryDetachTag = "DELETE FROM notetag WHERE tagid = $1 AND noteid = $2"
+
+func (store *TagsStorage) Detach(tagID int, noteToken string) error {
+	if _, err := store.DB.Exec(queryDetachTag, tagID, noteToken); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Detach",
+			"tagID":     tagID,
+			"noteToken": noteToken,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+// queryNoteTokens returns notes that have any of the tags, or all of them
+// when $3 is true.
+const queryNoteTokens = `SELECT notetag.noteid FROM notetag
+JOIN tag ON notetag.tagid = tag.tagid
+WHERE tag.userid = $1 AND tag.tagid = ANY($2)
+GROUP BY notetag.noteid
+HAVING NOT $3 OR COUNT(*) = cardinality($2)`
+
+func (store *TagsStorage) NoteTokens(userID string, tagIDs []int, matchAll bool) ([]string, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "NoteTokens",
+	})
+
+	rows, err := store.DB.Query(queryNoteTokens, userID, pq.Array(tagIDs), matchAll)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var noteToken string
+		if err := rows.Scan(&noteToken); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		tokens = append(tokens, noteToken)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+const queryNotesTags = `SELECT notetag.noteid, tag.tagid, tag.name, tag.color FROM notetag
+JOIN tag ON notetag.tagid = tag.tagid
+WHERE tag.userid = $1
+ORDER BY tag.name`
+
+func (store *TagsStorage) NotesTags(userID string) (map[string][]entity.Tag, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "NotesTags",
+	})
+
+	rows, err := store.DB.Query(queryNotesTags, userID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]entity.Tag)
+	for rows.Next() {
+		var noteToken string
+		tag := entity.Tag{UserID: userID}
+		if err := rows.Scan(&noteToken, &tag.ID, &tag.Name, &tag.Color); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		tags[noteToken] = append(tags[noteToken], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return tags, nil
+}
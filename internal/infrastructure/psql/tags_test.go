@@ -0,0 +1,109 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+)
+
+func TestSaveTag(t *testing.T) {
+	var mockTag = entity.Tag{
+		UserID: "101",
+		Name:   "work",
+		Color:  "#ff0000",
+	}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(int, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO tag").
+					WithArgs(mockTag.UserID, mockTag.Name, mockTag.Color).
+					WillReturnRows(sqlmock.NewRows([]string{"tagid"}).AddRow(7))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 7, actualID)
+			},
+		},
+		"Duplicate name": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("INSERT INTO tag").
+					WithArgs(mockTag.UserID, mockTag.Name, mockTag.Color).
+					WillReturnError(fmt.Errorf("duplicate key value"))
+			},
+			expected: func(actualID int, actualErr error) {
+				require.Equal(t, fmt.Errorf("duplicate key value"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTagsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			id, err := repo.Save(mockTag)
+			tc.expected(id, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestNoteTokens(t *testing.T) {
+	const mockUserID = "101"
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func([]string, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"noteid"}).AddRow("1").AddRow("3")
+				mock.
+					ExpectQuery("SELECT notetag.noteid FROM notetag").
+					WithArgs(mockUserID, sqlmock.AnyArg(), true).
+					WillReturnRows(rows)
+			},
+			expected: func(actualTokens []string, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, []string{"1", "3"}, actualTokens)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT notetag.noteid FROM notetag").
+					WithArgs(mockUserID, sqlmock.AnyArg(), true).
+					WillReturnError(fmt.Errorf("internal error"))
+			},
+			expected: func(actualTokens []string, actualErr error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTagsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			tokens, err := repo.NoteTokens(mockUserID, []int{1, 2}, true)
+			tc.expected(tokens, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
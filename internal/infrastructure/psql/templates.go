@@ -0,0 +1,139 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNoTemplateInDB = errors.New("no template in DB with this id")
+
+type TemplatesStorage struct {
+	DB *sql.DB
+}
+
+func NewTemplatesStorage(db *sql.DB) *TemplatesStorage {
+	return &TemplatesStorage{
+		DB: db,
+	}
+}
+
+const querySaveTemplate = "INSERT INTO template(userid, name, body, tags) VALUES ($1, $2, $3, $4) RETURNING templateid"
+
+func (store *TemplatesStorage) Save(template entity.Template) (int, error) {
+	var templateID int
+	err := store.DB.QueryRow(querySaveTemplate, template.UserID, template.Name, template.Body, pq.Array(template.Tags)).
+		Scan(&templateID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Save",
+		}).Error(err)
+		return 0, err
+	}
+	return templateID, nil
+}
+
+const queryUpdateTemplate = "UPDATE template SET name = $1, body = $2, tags = $3 WHERE templateid = $4"
+
+func (store *TemplatesStorage) Update(template entity.Template) error {
+	logger := log.WithFields(log.Fields{
+		"package":    packageName,
+		"function":   "Update",
+		"templateID": template.ID,
+	})
+
+	result, err := store.DB.Exec(queryUpdateTemplate, template.Name, template.Body, pq.Array(template.Tags), template.ID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoTemplateInDB)
+		return ErrNoTemplateInDB
+	}
+	return nil
+}
+
+const queryDeleteTemplate = "DELETE FROM template WHERE templateid = $1"
+
+func (store *TemplatesStorage) Delete(templateID int) error {
+	if _, err := store.DB.Exec(queryDeleteTemplate, templateID); err != nil {
+		log.WithFields(log.Fields{
+			"package":    packageName,
+			"function":   "Delete",
+			"templateID": templateID,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const querySelectTemplate = "SELECT templateid, COALESCE(userid, ''), name, body, tags FROM template"
+
+func scanTemplate(row scanner) (entity.Template, error) {
+	var template entity.Template
+	var tags pq.Int64Array
+	if err := row.Scan(&template.ID, &template.UserID, &template.Name, &template.Body, &tags); err != nil {
+		return entity.Template{}, err
+	}
+	template.Tags = []int{}
+	for _, tagID := range tags {
+		template.Tags = append(template.Tags, int(tagID))
+	}
+	return template, nil
+}
+
+const queryFindTemplate = querySelectTemplate + " WHERE templateid = $1"
+
+func (store *TemplatesStorage) Find(templateID int) (entity.Template, error) {
+	template, err := scanTemplate(store.DB.QueryRow(queryFindTemplate, templateID))
+	if err == sql.ErrNoRows {
+		return entity.Template{}, ErrNoTemplateInDB
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":    packageName,
+			"function":   "Find",
+			"templateID": templateID,
+		}).Error(err)
+		return entity.Template{}, err
+	}
+	return template, nil
+}
+
+// queryTemplatesByUserID lists the built-in templates first.
+const queryTemplatesByUserID = querySelectTemplate + " WHERE userid = $1 OR userid IS NULL ORDER BY userid NULLS FIRST, name, templateid"
+
+func (store *TemplatesStorage) AllByUserID(userID string) ([]entity.Template, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "AllByUserID",
+	})
+
+	rows, err := store.DB.Query(queryTemplatesByUserID, userID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []entity.Template
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,115 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+)
+
+func TestFindTemplate(t *testing.T) {
+	const mockTemplateID = 3
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(entity.Template, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"templateid", "userid", "name", "body", "tags"}).
+					AddRow(mockTemplateID, "101", "Daily", "Today", []byte("{5,7}"))
+				mock.
+					ExpectQuery("SELECT templateid, COALESCE").
+					WithArgs(mockTemplateID).
+					WillReturnRows(rows)
+			},
+			expected: func(actualTemplate entity.Template, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, entity.Template{
+					ID:     mockTemplateID,
+					UserID: "101",
+					Name:   "Daily",
+					Body:   "Today",
+					Tags:   []int{5, 7},
+				}, actualTemplate)
+			},
+		},
+		"No template": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT templateid, COALESCE").
+					WithArgs(mockTemplateID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expected: func(actualTemplate entity.Template, actualErr error) {
+				require.Equal(t, ErrNoTemplateInDB, actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTemplatesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			template, err := repo.Find(mockTemplateID)
+			tc.expected(template, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	var mockTemplate = entity.Template{
+		ID:   3,
+		Name: "Daily",
+		Body: "Today",
+		Tags: []int{},
+	}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE template").
+					WithArgs(mockTemplate.Name, mockTemplate.Body, sqlmock.AnyArg(), mockTemplate.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, nil, actualErr)
+			},
+		},
+		"No template": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE template").
+					WithArgs(mockTemplate.Name, mockTemplate.Body, sqlmock.AnyArg(), mockTemplate.ID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expected: func(actualErr error) {
+				require.Equal(t, ErrNoTemplateInDB, actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTemplatesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			tc.expected(repo.Update(mockTemplate))
+		})
+		log.Println("SUCCESS")
+	}
+}
@@ -0,0 +1,185 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+var ErrNoUploadInDB = errors.New("no upload in DB with this id")
+
+type UploadsStorage struct {
+	DB *sql.DB
+}
+
+func NewUploadsStorage(db *sql.DB) *UploadsStorage {
+	return &UploadsStorage{
+		DB: db,
+	}
+}
+
+const querySaveUpload = `INSERT INTO upload(uploadid, noteid, userid, filename, size, expiresat)
+VALUES ($1, $2, $3, $4, $5, $6)`
+
+func (store *UploadsStorage) Save(upload entity.Upload) error {
+	if _, err := store.DB.Exec(querySaveUpload, upload.ID, upload.NoteToken, upload.UserID, upload.FileName,
+		upload.Size, upload.ExpiresAt); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Save",
+			"noteToken": upload.NoteToken,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const querySelectUpload = `SELECT uploadid, noteid, userid, filename, size, uploadoffset, contenttype, objectname,
+	storageid, parts, expiresat
+FROM upload`
+
+func scanUpload(row scanner) (entity.Upload, error) {
+	var upload entity.Upload
+	var parts []byte
+	if err := row.Scan(&upload.ID, &upload.NoteToken, &upload.UserID, &upload.FileName, &upload.Size, &upload.Offset,
+		&upload.ContentType, &upload.ObjectName, &upload.StorageID, &parts, &upload.ExpiresAt); err != nil {
+		return entity.Upload{}, err
+	}
+	if err := json.Unmarshal(parts, &upload.Parts); err != nil {
+		return entity.Upload{}, err
+	}
+	return upload, nil
+}
+
+const queryFindUpload = querySelectUpload + " WHERE uploadid = $1"
+
+func (store *UploadsStorage) Find(uploadID string) (entity.Upload, error) {
+	upload, err := scanUpload(store.DB.QueryRow(queryFindUpload, uploadID))
+	if err == sql.ErrNoRows {
+		return entity.Upload{}, ErrNoUploadInDB
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Find",
+			"uploadID": uploadID,
+		}).Error(err)
+		return entity.Upload{}, err
+	}
+	return upload, nil
+}
+
+// queryUpdateUpload only moves the upload forward from the offset the chunk
+// was written at, so that one of two concurrent chunks fails.
+const queryUpdateUpload = `UPDATE upload SET uploadoffset = $1, contenttype = $2, objectname = $3, storageid = $4, parts = $5
+WHERE uploadid = $6 AND uploadoffset = $7`
+
+func (store *UploadsStorage) Update(upload entity.Upload, offset int64) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Update",
+		"uploadID": upload.ID,
+	})
+
+	parts, err := json.Marshal(upload.Parts)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	result, err := store.DB.Exec(queryUpdateUpload, upload.Offset, upload.ContentType, upload.ObjectName, upload.StorageID,
+		parts, upload.ID, offset)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(entity.ErrUploadOffsetConflict)
+		return entity.ErrUploadOffsetConflict
+	}
+	return nil
+}
+
+const queryDeleteUpload = "DELETE FROM upload WHERE uploadid = $1"
+
+func (store *UploadsStorage) Delete(uploadID string) error {
+	if _, err := store.DB.Exec(queryDeleteUpload, uploadID); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Delete",
+			"uploadID": uploadID,
+		}).Error(err)
+		return err
+	}
+	return nil
+}
+
+const queryExpiredUploads = querySelectUpload + " WHERE expiresat < $1"
+
+func (store *UploadsStorage) Expired(before time.Time) ([]entity.Upload, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "Expired",
+	})
+
+	rows, err := store.DB.Query(queryExpiredUploads, before)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uploads []entity.Upload
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+const queryUploadsByNote = querySelectUpload + " WHERE noteid = $1"
+
+func (store *UploadsStorage) AllByNote(noteToken string) ([]entity.Upload, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "AllByNote",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryUploadsByNote, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uploads []entity.Upload
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return uploads, nil
+}
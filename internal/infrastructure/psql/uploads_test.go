@@ -0,0 +1,176 @@
+package psql
+
+import (
+	"cotion/internal/domain/entity"
+	"database/sql"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestFindUpload(t *testing.T) {
+	expiresAt := time.Date(2021, 11, 2, 12, 0, 0, 0, time.UTC)
+	columns := []string{"uploadid", "noteid", "userid", "filename", "size", "uploadoffset", "contenttype", "objectname",
+		"storageid", "parts", "expiresat"}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(entity.Upload, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT uploadid").
+					WithArgs("abc").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("abc", "1", "101", "video.mp4", 100, 50,
+						"video/mp4", "object", "storage", []byte(`[{"number":1,"etag":"e1"}]`), expiresAt))
+			},
+			expected: func(actual entity.Upload, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, entity.Upload{
+					ID:          "abc",
+					NoteToken:   "1",
+					UserID:      "101",
+					FileName:    "video.mp4",
+					Size:        100,
+					Offset:      50,
+					ContentType: "video/mp4",
+					ObjectName:  "object",
+					StorageID:   "storage",
+					Parts:       []entity.UploadPart{{Number: 1, ETag: "e1"}},
+					ExpiresAt:   expiresAt,
+				}, actual)
+			},
+		},
+		"No upload": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT uploadid").
+					WithArgs("abc").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expected: func(actual entity.Upload, actualErr error) {
+				require.Equal(t, ErrNoUploadInDB, actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewUploadsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			upload, err := repo.Find("abc")
+			tc.expected(upload, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestUpdateUpload(t *testing.T) {
+	var mockUpload = entity.Upload{
+		ID:          "abc",
+		Offset:      100,
+		ContentType: "video/mp4",
+		ObjectName:  "object",
+		StorageID:   "storage",
+		Parts:       []entity.UploadPart{{Number: 1, ETag: "e1"}, {Number: 2, ETag: "e2"}},
+	}
+	parts := []byte(`[{"number":1,"etag":"e1"},{"number":2,"etag":"e2"}]`)
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected error
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE upload").
+					WithArgs(mockUpload.Offset, mockUpload.ContentType, mockUpload.ObjectName, mockUpload.StorageID,
+						parts, mockUpload.ID, 50).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expected: nil,
+		},
+		"Offset moved": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE upload").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expected: entity.ErrUploadOffsetConflict,
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewUploadsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			require.Equal(t, tc.expected, repo.Update(mockUpload, 50))
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestUploadsByNote(t *testing.T) {
+	expiresAt := time.Date(2021, 11, 2, 12, 0, 0, 0, time.UTC)
+	columns := []string{"uploadid", "noteid", "userid", "filename", "size", "uploadoffset", "contenttype", "objectname",
+		"storageid", "parts", "expiresat"}
+
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func([]entity.Upload, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT uploadid").
+					WithArgs("1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("abc", "1", "101", "video.mp4", 100, 50, "video/mp4", "object", "storage",
+							[]byte(`[{"number":1,"etag":"e1"}]`), expiresAt).
+						AddRow("def", "1", "101", "a.txt", 3, 0, "", "", "", []byte(`[]`), expiresAt))
+			},
+			expected: func(actual []entity.Upload, actualErr error) {
+				require.Equal(t, nil, actualErr)
+				require.Equal(t, 2, len(actual))
+				require.Equal(t, "storage", actual[0].StorageID)
+				require.Equal(t, "", actual[1].StorageID)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT uploadid").
+					WithArgs("1").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expected: func(actual []entity.Upload, actualErr error) {
+				require.Equal(t, sql.ErrConnDone, actualErr)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewUploadsStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			uploads, err := repo.AllByNote("1")
+			tc.expected(uploads, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
@@ -40,6 +40,18 @@ func (store *UserStorage) Get(userID string) (entity.User, error) {
 	return user, nil
 }
 
+// Usernames are not unique, the user registered with the lowest id wins.
+const queryFindUserByUsername = "SELECT userid, username, email, password, avatar FROM cotionuser WHERE username = $1 ORDER BY userid LIMIT 1"
+
+func (store *UserStorage) FindByUsername(username string) (entity.User, error) {
+	row := store.DB.QueryRow(queryFindUserByUsername, username)
+	user := entity.User{}
+	if err := row.Scan(&user.UserID, &user.Username, &user.Email, &user.Password, &user.Avatar); err != nil {
+		return entity.User{}, err
+	}
+	return user, nil
+}
+
 const queryUpdateUser = "UPDATE cotionuser SET username = $1, password = $2, avatar = $3 where userid = $4"
 
 func (store *UserStorage) Update(user entity.User) error {
@@ -4,6 +4,7 @@ import (
 	"cotion/internal/domain/entity"
 	"database/sql"
 	"errors"
+	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -19,10 +20,12 @@ func NewUsersNotesStorage(db *sql.DB) *UsersNotesStorage {
 	}
 }
 
-const queryAddLink = "INSERT INTO usersnotes(userid, noteid) VALUES ($1, $2)"
+// queryAddLink puts the note at the end of the user's manual order.
+const queryAddLink = `INSERT INTO usersnotes(userid, noteid, role, position)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position), 0) + 1 FROM usersnotes WHERE userid = $1))`
 
-func (store *UsersNotesStorage) AddLink(userID string, noteToken string) error {
-	_, err := store.DB.Exec(queryAddLink, userID, noteToken)
+func (store *UsersNotesStorage) AddLink(userID string, noteToken string, role string) error {
+	_, err := store.DB.Exec(queryAddLink, userID, noteToken, role)
 	log.WithFields(log.Fields{
 		"package":  packageName,
 		"function": "AddLink",
@@ -59,29 +62,132 @@ func (store *UsersNotesStorage) CheckLink(userID string, noteToken string) bool
 	return true
 }
 
-const queryFindNotes = "SELECT name, body, note.noteid FROM usersnotes JOIN note ON usersnotes.noteid = note.noteid WHERE userid = $1"
+const queryRole = "SELECT role FROM usersnotes WHERE userid = $1 AND noteid = $2"
 
-func (store *UsersNotesStorage) AllNotesByUserID(userID string) (entity.ShortNotes, error) {
+func (store *UsersNotesStorage) Role(userID string, noteToken string) (string, error) {
+	var role string
+	if err := store.DB.QueryRow(queryRole, userID, noteToken).Scan(&role); err != nil {
+		log.WithFields(log.Fields{
+			"package":   packageName,
+			"function":  "Role",
+			"userID":    userID,
+			"noteToken": noteToken,
+		}).Warning(err)
+		return "", ErrNoteAccess
+	}
+	return role, nil
+}
+
+const queryUpdateRole = "UPDATE usersnotes SET role = $1 WHERE userid = $2 AND noteid = $3"
+
+func (store *UsersNotesStorage) UpdateRole(userID string, noteToken string, role string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "UpdateRole",
+	})
+
+	result, err := store.DB.Exec(queryUpdateRole, role, userID, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoteAccess)
+		return ErrNoteAccess
+	}
+	return nil
+}
+
+const queryMembers = "SELECT cotionuser.userid, username, email, role FROM usersnotes JOIN cotionuser ON usersnotes.userid = cotionuser.userid WHERE noteid = $1 ORDER BY username"
+
+func (store *UsersNotesStorage) Members(noteToken string) ([]entity.Member, error) {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  "Members",
+		"noteToken": noteToken,
+	})
+
+	rows, err := store.DB.Query(queryMembers, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []entity.Member
+	for rows.Next() {
+		var member entity.Member
+		if err := rows.Scan(&member.UserID, &member.Username, &member.Email, &member.Role); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// queryNotesFrom does not restrict the notes when no tokens are given.
+const queryNotesFrom = `FROM usersnotes JOIN note ON usersnotes.noteid = note.noteid
+WHERE userid = $1 AND deletedat IS NULL AND (cardinality($2::varchar[]) = 0 OR note.noteid = ANY($2))
+	AND (NOT $3 OR favorite)`
+
+const queryCountNotes = "SELECT COUNT(*) " + queryNotesFrom
+
+// queryFindNotes is completed by notesListQuery with the sort key columns,
+// the cursor condition, the order and the limit.
+const queryFindNotes = `SELECT name, CASE $4 WHEN 'none' THEN '' WHEN 'preview' THEN left(body, $5) ELSE body END,
+	note.noteid, COALESCE(parent, ''), pinned, favorite, note.createdat, note.updatedat,
+	COALESCE(note.createdby, ''), COALESCE(note.lasteditedby, ''), icontype, icon, %s
+` + queryNotesFrom + "%s\nORDER BY %s%s"
+
+func (store *UsersNotesStorage) AllNotesByUserID(userID string, filter entity.NotesFilter) (entity.ShortNotes, error) {
 	logger := log.WithFields(log.Fields{
 		"package":  packageName,
 		"function": "AllNotesByUserID",
 	})
 
-	rows, err := store.DB.Query(queryFindNotes, userID)
+	query, args, err := notesListQuery(userID, filter)
+	if err != nil {
+		logger.Warning(err)
+		return entity.ShortNotes{}, err
+	}
+
+	notes := entity.ShortNotes{}
+	if err := store.DB.QueryRow(queryCountNotes, args[:3]...).Scan(&notes.Total); err != nil {
+		logger.Error(err)
+		return entity.ShortNotes{}, err
+	}
+
+	rows, err := store.DB.Query(query, args...)
 	if err != nil {
 		logger.Error(err)
 		return entity.ShortNotes{}, err
 	}
 	defer rows.Close()
 
-	notes := entity.ShortNotes{}
+	var rowsKeys [][]string
 	for rows.Next() {
 		var note entity.ShortNote
-		if err := rows.Scan(&note.Name, &note.Body, &note.Token); err != nil {
+		var iconType, icon string
+		keys := make([]string, len(sortKeys(filter.Sort)))
+		dest := []interface{}{&note.Name, &note.Body, &note.Token, &note.Parent, &note.Pinned, &note.Favorite,
+			&note.CreatedAt, &note.UpdatedAt, &note.CreatedBy, &note.LastEditedBy, &iconType, &icon}
+		for i := range keys {
+			dest = append(dest, &keys[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
 			logger.Error(err)
 			return entity.ShortNotes{}, err
 		}
+		note.Icon = entity.NewNoteIcon(iconType, icon)
 		notes.ShortNote = append(notes.ShortNote, note)
+		rowsKeys = append(rowsKeys, keys)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -89,5 +195,140 @@ func (store *UsersNotesStorage) AllNotesByUserID(userID string) (entity.ShortNot
 		return entity.ShortNotes{}, err
 	}
 
+	// One extra row is requested to find out whether there is a next page.
+	if filter.Limit > 0 && len(notes.ShortNote) > filter.Limit {
+		notes.ShortNote = notes.ShortNote[:filter.Limit]
+		notes.NextCursor = encodeCursor(filter.Sort, rowsKeys[filter.Limit-1])
+	}
+
+	return notes, nil
+}
+
+// querySearchNotes matches the query against both the Russian and the
+// English configuration, so that stemming works for either language.
+const querySearchNotes = `SELECT note.noteid, name,
+	ts_headline('russian', body, query, 'StartSel=<b>, StopSel=</b>, MaxWords=30, MinWords=10'),
+	ts_rank(search, query) AS rank
+FROM usersnotes
+JOIN note ON usersnotes.noteid = note.noteid,
+	websearch_to_tsquery('russian', $2) || websearch_to_tsquery('english', $2) AS query
+WHERE usersnotes.userid = $1 AND note.deletedat IS NULL AND search @@ query
+ORDER BY rank DESC
+LIMIT $3`
+
+func (store *UsersNotesStorage) SearchNotes(userID string, query string, limit int) ([]entity.FoundNote, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "SearchNotes",
+	})
+
+	rows, err := store.DB.Query(querySearchNotes, userID, query, limit)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []entity.FoundNote
+	for rows.Next() {
+		var note entity.FoundNote
+		if err := rows.Scan(&note.Token, &note.Name, &note.Snippet, &note.Rank); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
 	return notes, nil
 }
+
+const queryTrash = `SELECT name, note.noteid, COALESCE(parent, ''), deletedat
+FROM usersnotes JOIN note ON usersnotes.noteid = note.noteid
+WHERE userid = $1 AND role = $2 AND deletedat IS NOT NULL
+ORDER BY deletedat DESC`
+
+func (store *UsersNotesStorage) TrashByUserID(userID string) ([]entity.TrashedNote, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  packageName,
+		"function": "TrashByUserID",
+	})
+
+	rows, err := store.DB.Query(queryTrash, userID, entity.RoleOwner)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []entity.TrashedNote
+	for rows.Next() {
+		var note entity.TrashedNote
+		if err := rows.Scan(&note.Name, &note.Token, &note.Parent, &note.DeletedAt); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+const querySetPinned = "UPDATE usersnotes SET pinned = $1 WHERE userid = $2 AND noteid = $3"
+
+func (store *UsersNotesStorage) SetPinned(userID string, noteToken string, pinned bool) error {
+	return store.updateLink("SetPinned", querySetPinned, pinned, userID, noteToken)
+}
+
+const querySetFavorite = "UPDATE usersnotes SET favorite = $1 WHERE userid = $2 AND noteid = $3"
+
+func (store *UsersNotesStorage) SetFavorite(userID string, noteToken string, favorite bool) error {
+	return store.updateLink("SetFavorite", querySetFavorite, favorite, userID, noteToken)
+}
+
+func (store *UsersNotesStorage) updateLink(function string, query string, value bool, userID string, noteToken string) error {
+	logger := log.WithFields(log.Fields{
+		"package":   packageName,
+		"function":  function,
+		"noteToken": noteToken,
+	})
+
+	result, err := store.DB.Exec(query, value, userID, noteToken)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		logger.Warning(ErrNoteAccess)
+		return ErrNoteAccess
+	}
+	return nil
+}
+
+// queryReorder numbers the given notes first, in the given order, and
+// keeps the relative order of the rest of the user's notes after them.
+const queryReorder = `UPDATE usersnotes SET position = ranked.position FROM (
+	SELECT noteid, ROW_NUMBER() OVER (ORDER BY array_position($2::varchar[], noteid) NULLS LAST, position, noteid) AS position
+	FROM usersnotes WHERE userid = $1
+) AS ranked
+WHERE usersnotes.userid = $1 AND usersnotes.noteid = ranked.noteid`
+
+func (store *UsersNotesStorage) Reorder(userID string, noteTokens []string) error {
+	if _, err := store.DB.Exec(queryReorder, userID, pq.Array(noteTokens)); err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "Reorder",
+		}).Error(err)
+		return err
+	}
+	return nil
+}
@@ -81,7 +81,7 @@ func TestAddLink(t *testing.T) {
 			prepare: func(mock sqlmock.Sqlmock) {
 				mock.
 					ExpectExec("INSERT INTO usersnotes").
-					WithArgs(mockUser.UserID, mockNoteToken).
+					WithArgs(mockUser.UserID, mockNoteToken, entity.RoleOwner).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expected: func(actualError error) {
@@ -92,7 +92,7 @@ func TestAddLink(t *testing.T) {
 			prepare: func(mock sqlmock.Sqlmock) {
 				mock.
 					ExpectExec("INSERT INTO usersnotes").
-					WithArgs(mockUser.UserID, mockNoteToken).
+					WithArgs(mockUser.UserID, mockNoteToken, entity.RoleOwner).
 					WillReturnError(fmt.Errorf("internal error"))
 			},
 			expected: func(actualError error) {
@@ -109,7 +109,7 @@ func TestAddLink(t *testing.T) {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			tc.prepare(mock)
-			err := repo.AddLink(mockUser.UserID, mockNoteToken)
+			err := repo.AddLink(mockUser.UserID, mockNoteToken, entity.RoleOwner)
 			tc.expected(err)
 		})
 		log.Println("SUCCESS")
@@ -170,9 +170,12 @@ func TestDeleteLink(t *testing.T) {
 
 func TestAllNotesByUserID(t *testing.T) {
 	var mockNote = entity.ShortNote{
-		Name:  "testNoteName",
-		Body:  "testNoteBody",
-		Token: "2938284012",
+		Name:         "testNoteName",
+		Body:         "testNoteBody",
+		Token:        "2938284012",
+		Icon:         &entity.NoteIcon{Type: entity.IconEmoji, Value: "🔥"},
+		CreatedBy:    "101",
+		LastEditedBy: "101",
 	}
 	var mockUser = entity.User{
 		UserID:   "101",
@@ -181,29 +184,86 @@ func TestAllNotesByUserID(t *testing.T) {
 		Password: "Test1234!@#",
 		Avatar:   "none",
 	}
+	columns := []string{"Name", "Body", "NoteID", "Parent", "Pinned", "Favorite", "CreatedAt", "UpdatedAt", "CreatedBy", "LastEditedBy", "IconType", "Icon", "Key", "Position", "Token"}
+	cursor := encodeCursor(entity.SortPosition, []string{"true", "1", "2938284012"})
+
 	cases := map[string]struct {
+		inFilter entity.NotesFilter
 		prepare  func(sqlmock.Sqlmock)
 		expected func(entity.ShortNotes, error)
 	}{
 		"Success": {
+			inFilter: entity.NotesFilter{Sort: entity.SortPosition, Body: entity.BodyFull},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT COUNT").
+					WithArgs(mockUser.UserID, sqlmock.AnyArg(), false).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				rows := sqlmock.NewRows(columns)
+				rows = rows.AddRow(mockNote.Name, mockNote.Body, mockNote.Token, mockNote.Parent, mockNote.Pinned, mockNote.Favorite,
+					mockNote.CreatedAt, mockNote.UpdatedAt, mockNote.CreatedBy, mockNote.LastEditedBy, mockNote.Icon.Type, mockNote.Icon.Value, "true", "1", mockNote.Token)
+				mock.
+					ExpectQuery("SELECT name, CASE").
+					WithArgs(mockUser.UserID, sqlmock.AnyArg(), false, entity.BodyFull, entity.BodyPreviewLength).
+					WillReturnRows(rows)
+			},
+			expected: func(actualResult entity.ShortNotes, actualError error) {
+				require.Equal(t, nil, actualError)
+				require.Equal(t, entity.ShortNotes{ShortNote: []entity.ShortNote{mockNote}, Total: 1}, actualResult)
+			},
+		},
+		"Next page": {
+			inFilter: entity.NotesFilter{Sort: entity.SortPosition, Body: entity.BodyNone, Limit: 1},
 			prepare: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"Name", "Body", "NoteID"})
-				rows = rows.AddRow(mockNote.Name, mockNote.Body, mockNote.Token)
 				mock.
-					ExpectQuery("SELECT name, body, note.noteid FROM usersnotes").
-					WithArgs(mockUser.UserID).
+					ExpectQuery("SELECT COUNT").
+					WithArgs(mockUser.UserID, sqlmock.AnyArg(), false).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				rows := sqlmock.NewRows(columns)
+				rows = rows.AddRow(mockNote.Name, mockNote.Body, mockNote.Token, mockNote.Parent, mockNote.Pinned, mockNote.Favorite,
+					mockNote.CreatedAt, mockNote.UpdatedAt, mockNote.CreatedBy, mockNote.LastEditedBy, mockNote.Icon.Type, mockNote.Icon.Value, "true", "1", mockNote.Token)
+				rows = rows.AddRow(mockNote.Name, mockNote.Body, "2938284013", mockNote.Parent, mockNote.Pinned, mockNote.Favorite,
+					mockNote.CreatedAt, mockNote.UpdatedAt, mockNote.CreatedBy, mockNote.LastEditedBy, mockNote.Icon.Type, mockNote.Icon.Value, "true", "2", "2938284013")
+				mock.
+					ExpectQuery("SELECT name, CASE .* LIMIT \\$6").
+					WithArgs(mockUser.UserID, sqlmock.AnyArg(), false, entity.BodyNone, entity.BodyPreviewLength, 2).
 					WillReturnRows(rows)
 			},
 			expected: func(actualResult entity.ShortNotes, actualError error) {
 				require.Equal(t, nil, actualError)
-				require.Equal(t, actualResult, entity.ShortNotes{ShortNote: []entity.ShortNote{mockNote}})
+				require.Equal(t, entity.ShortNotes{ShortNote: []entity.ShortNote{mockNote}, Total: 2, NextCursor: cursor}, actualResult)
+			},
+		},
+		"After cursor": {
+			inFilter: entity.NotesFilter{Sort: entity.SortPosition, Body: entity.BodyFull, Cursor: cursor},
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT COUNT").
+					WithArgs(mockUser.UserID, sqlmock.AnyArg(), false).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.
+					ExpectQuery("AND \\(NOT pinned, position, note.noteid\\) > \\(\\$6::boolean, \\$7::integer, \\$8::varchar\\)").
+					WithArgs(mockUser.UserID, sqlmock.AnyArg(), false, entity.BodyFull, entity.BodyPreviewLength, "true", "1", mockNote.Token).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expected: func(actualResult entity.ShortNotes, actualError error) {
+				require.Equal(t, nil, actualError)
+				require.Equal(t, entity.ShortNotes{Total: 2}, actualResult)
+			},
+		},
+		"Cursor of another sort": {
+			inFilter: entity.NotesFilter{Sort: entity.SortName, Body: entity.BodyFull, Cursor: cursor},
+			prepare:  func(mock sqlmock.Sqlmock) {},
+			expected: func(actualResult entity.ShortNotes, actualError error) {
+				require.Equal(t, entity.ErrInvalidCursor, actualError)
 			},
 		},
 		"Error": {
+			inFilter: entity.NotesFilter{Sort: entity.SortPosition, Body: entity.BodyFull},
 			prepare: func(mock sqlmock.Sqlmock) {
 				mock.
-					ExpectQuery("SELECT name, body, note.noteid FROM usersnotes").
-					WithArgs(mockUser.UserID).
+					ExpectQuery("SELECT COUNT").
+					WithArgs(mockUser.UserID, sqlmock.AnyArg(), false).
 					WillReturnError(fmt.Errorf("internal error"))
 			},
 			expected: func(actualResult entity.ShortNotes, actualError error) {
@@ -220,9 +280,165 @@ func TestAllNotesByUserID(t *testing.T) {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			tc.prepare(mock)
-			result, err := repo.AllNotesByUserID(mockUser.UserID)
+			result, err := repo.AllNotesByUserID(mockUser.UserID, tc.inFilter)
+			tc.expected(result, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestRole(t *testing.T) {
+	const (
+		mockUserID    = "101"
+		mockNoteToken = "adjfkjanfkakdfjjk"
+	)
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(string, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"role"}).AddRow(entity.RoleEditor)
+				mock.
+					ExpectQuery("SELECT role FROM usersnotes WHERE").
+					WithArgs(mockUserID, mockNoteToken).
+					WillReturnRows(rows)
+			},
+			expected: func(actualRole string, actualError error) {
+				require.Equal(t, nil, actualError)
+				require.Equal(t, entity.RoleEditor, actualRole)
+			},
+		},
+		"No link": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT role FROM usersnotes WHERE").
+					WithArgs(mockUserID, mockNoteToken).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expected: func(actualRole string, actualError error) {
+				require.Equal(t, ErrNoteAccess, actualError)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewUsersNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			role, err := repo.Role(mockUserID, mockNoteToken)
+			tc.expected(role, err)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestSearchNotes(t *testing.T) {
+	const (
+		mockUserID = "101"
+		mockQuery  = "note"
+		mockLimit  = 50
+	)
+	var mockNote = entity.FoundNote{
+		Name:    "testNoteName",
+		Token:   "2938284012",
+		Snippet: "test <b>note</b> body",
+		Rank:    0.5,
+	}
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func([]entity.FoundNote, error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"noteid", "name", "ts_headline", "rank"})
+				rows = rows.AddRow(mockNote.Token, mockNote.Name, mockNote.Snippet, mockNote.Rank)
+				mock.
+					ExpectQuery("SELECT note.noteid, name").
+					WithArgs(mockUserID, mockQuery, mockLimit).
+					WillReturnRows(rows)
+			},
+			expected: func(actualResult []entity.FoundNote, actualError error) {
+				require.Equal(t, nil, actualError)
+				require.Equal(t, []entity.FoundNote{mockNote}, actualResult)
+			},
+		},
+		"Error": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectQuery("SELECT note.noteid, name").
+					WithArgs(mockUserID, mockQuery, mockLimit).
+					WillReturnError(fmt.Errorf("internal error"))
+			},
+			expected: func(actualResult []entity.FoundNote, actualError error) {
+				require.Equal(t, fmt.Errorf("internal error"), actualError)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewUsersNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			result, err := repo.SearchNotes(mockUserID, mockQuery, mockLimit)
 			tc.expected(result, err)
 		})
 		log.Println("SUCCESS")
 	}
 }
+
+func TestSetPinned(t *testing.T) {
+	const (
+		mockUserID    = "101"
+		mockNoteToken = "adjfkjanfkakdfjjk"
+	)
+	cases := map[string]struct {
+		prepare  func(sqlmock.Sqlmock)
+		expected func(error)
+	}{
+		"Success": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE usersnotes SET pinned").
+					WithArgs(true, mockUserID, mockNoteToken).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expected: func(actualError error) {
+				require.Equal(t, nil, actualError)
+			},
+		},
+		"No link": {
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.
+					ExpectExec("UPDATE usersnotes SET pinned").
+					WithArgs(true, mockUserID, mockNoteToken).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expected: func(actualError error) {
+				require.Equal(t, ErrNoteAccess, actualError)
+			},
+		},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewUsersNotesStorage(db)
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.prepare(mock)
+			err := repo.SetPinned(mockUserID, mockNoteToken, true)
+			tc.expected(err)
+		})
+		log.Println("SUCCESS")
+	}
+}
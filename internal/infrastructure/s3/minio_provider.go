@@ -8,26 +8,37 @@ import (
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
+	"io"
 	"os"
+	"path"
 )
 
 const (
 	ENV_MINIO_URL      = "minio_url"
 	ENV_MINIO_USER     = "minio_user"
 	ENV_MINIO_PASSWORD = "minio_pass"
-	BucketName         = "avatars"
 	packageName        = "s3"
+
+	// AvatarsBucket keeps the avatars of the users.
+	AvatarsBucket = "avatars"
+	// NotesBucket keeps the icons and the covers of the notes.
+	NotesBucket = "notes"
+	// AttachmentsBucket keeps the files attached to the notes.
+	AttachmentsBucket = "attachments"
 )
 
 var ErrNoUrl = errors.New("There isn't minio url in *.env file")
 var ErrNoUser = errors.New("There isn't minio user in *.env file")
 var ErrNoPass = errors.New("There isn't minio password in *.env file")
 
+// MinioProvider stores the images in one bucket, which is created if it does
+// not exist.
 type MinioProvider struct {
-	client *minio.Client
+	client     *minio.Client
+	bucketName string
 }
 
-func NewMinioProvider() (*MinioProvider, error) {
+func NewMinioProvider(bucketName string) (*MinioProvider, error) {
 	var urlEnv, userEnv, passwordEnv string
 	if urlEnv = os.Getenv(ENV_MINIO_URL); urlEnv == "" {
 		return &MinioProvider{}, ErrNoUrl
@@ -49,20 +60,21 @@ func NewMinioProvider() (*MinioProvider, error) {
 
 	ctx := context.Background()
 
-	err = client.MakeBucket(ctx, BucketName, minio.MakeBucketOptions{})
+	err = client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{})
 	if err != nil {
-		exists, errBucketExists := client.BucketExists(ctx, BucketName)
+		exists, errBucketExists := client.BucketExists(ctx, bucketName)
 		if errBucketExists == nil && exists {
-			log.Info("We already own ", BucketName)
+			log.Info("We already own ", bucketName)
 		} else {
 			return &MinioProvider{}, err
 		}
 	} else {
-		log.Info("Successfully created ", BucketName)
+		log.Info("Successfully created ", bucketName)
 	}
 
 	return &MinioProvider{
-		client,
+		client:     client,
+		bucketName: bucketName,
 	}, nil
 }
 
@@ -78,7 +90,7 @@ func (m *MinioProvider) UploadFile(unit entity.ImageUnit) (string, error) {
 
 	_, err := m.client.PutObject(
 		context.Background(),
-		BucketName,
+		m.bucketName,
 		imageName,
 		unit.Payload,
 		unit.PayloadSize,
@@ -94,10 +106,32 @@ func (m *MinioProvider) UploadFile(unit entity.ImageUnit) (string, error) {
 	return imageName, err
 }
 
+// UploadObject stores a file of any type under a generated name.
+func (m *MinioProvider) UploadObject(unit entity.FileUnit) (string, error) {
+	objectName := generator.RandSID(32)
+
+	_, err := m.client.PutObject(
+		context.Background(),
+		m.bucketName,
+		objectName,
+		unit.Payload,
+		unit.PayloadSize,
+		minio.PutObjectOptions{ContentType: unit.ContentType},
+	)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "UploadObject",
+		}).Error(err)
+	}
+
+	return objectName, err
+}
+
 func (m *MinioProvider) DownloadFile(imageName string) (*minio.Object, error) {
 	reader, err := m.client.GetObject(
 		context.Background(),
-		BucketName,
+		m.bucketName,
 		imageName,
 		minio.GetObjectOptions{},
 	)
@@ -111,3 +145,135 @@ func (m *MinioProvider) DownloadFile(imageName string) (*minio.Object, error) {
 
 	return reader, nil
 }
+
+func (m *MinioProvider) DeleteFile(imageName string) error {
+	err := m.client.RemoveObject(
+		context.Background(),
+		m.bucketName,
+		imageName,
+		minio.RemoveObjectOptions{},
+	)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "DeleteFile",
+		}).Error(err)
+	}
+
+	return err
+}
+
+// CopyFile copies the object within the bucket. The copy keeps the type and
+// the extension of the object.
+func (m *MinioProvider) CopyFile(objectName string) (string, error) {
+	copyName := generator.RandSID(32) + path.Ext(objectName)
+
+	_, err := m.client.CopyObject(
+		context.Background(),
+		minio.CopyDestOptions{Bucket: m.bucketName, Object: copyName},
+		minio.CopySrcOptions{Bucket: m.bucketName, Object: objectName},
+	)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "CopyFile",
+		}).Error(err)
+		return "", err
+	}
+
+	return copyName, nil
+}
+
+// CreateUpload starts a multipart upload of a new object and returns the
+// name of the object and the id of the upload.
+func (m *MinioProvider) CreateUpload(contentType string) (string, string, error) {
+	objectName := generator.RandSID(32)
+
+	core := minio.Core{Client: m.client}
+	uploadID, err := core.NewMultipartUpload(
+		context.Background(),
+		m.bucketName,
+		objectName,
+		minio.PutObjectOptions{ContentType: contentType},
+	)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "CreateUpload",
+		}).Error(err)
+		return "", "", err
+	}
+
+	return objectName, uploadID, nil
+}
+
+// UploadPart stores a part of the object and returns its ETag.
+func (m *MinioProvider) UploadPart(objectName string, uploadID string, partNumber int, part io.Reader, size int64) (string, error) {
+	core := minio.Core{Client: m.client}
+	objectPart, err := core.PutObjectPart(
+		context.Background(),
+		m.bucketName,
+		objectName,
+		uploadID,
+		partNumber,
+		part,
+		size,
+		"",
+		"",
+		nil,
+	)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "UploadPart",
+		}).Error(err)
+		return "", err
+	}
+
+	return objectPart.ETag, nil
+}
+
+// CompleteUpload joins the parts into the object.
+func (m *MinioProvider) CompleteUpload(objectName string, uploadID string, parts []entity.UploadPart) error {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.Number, ETag: part.ETag})
+	}
+
+	core := minio.Core{Client: m.client}
+	_, err := core.CompleteMultipartUpload(
+		context.Background(),
+		m.bucketName,
+		objectName,
+		uploadID,
+		completeParts,
+		minio.PutObjectOptions{},
+	)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "CompleteUpload",
+		}).Error(err)
+	}
+
+	return err
+}
+
+// AbortUpload removes the parts of an unfinished upload.
+func (m *MinioProvider) AbortUpload(objectName string, uploadID string) error {
+	core := minio.Core{Client: m.client}
+	err := core.AbortMultipartUpload(
+		context.Background(),
+		m.bucketName,
+		objectName,
+		uploadID,
+	)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  packageName,
+			"function": "AbortUpload",
+		}).Error(err)
+	}
+
+	return err
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoAttachmentInDB = errors.New("no attachment in DB with this id")
+
+type AttachmentsStorage struct {
+	mu          sync.Mutex
+	lastID      int
+	attachments map[int]entity.Attachment
+}
+
+func NewAttachmentsStorage() *AttachmentsStorage {
+	return &AttachmentsStorage{
+		attachments: make(map[int]entity.Attachment),
+	}
+}
+
+func (store *AttachmentsStorage) Save(attachment entity.Attachment) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastID++
+	attachment.ID = store.lastID
+	store.attachments[attachment.ID] = attachment
+	return attachment.ID, nil
+}
+
+func (store *AttachmentsStorage) Find(attachmentID int) (entity.Attachment, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	attachment, ok := store.attachments[attachmentID]
+	if !ok {
+		return entity.Attachment{}, ErrNoAttachmentInDB
+	}
+	return attachment, nil
+}
+
+func (store *AttachmentsStorage) Delete(attachmentID int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.attachments, attachmentID)
+	return nil
+}
+
+func (store *AttachmentsStorage) AllByNote(noteToken string) ([]entity.Attachment, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var attachments []entity.Attachment
+	for _, attachment := range store.attachments {
+		if attachment.NoteToken == noteToken {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].ID < attachments[j].ID
+	})
+	return attachments, nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sync"
+)
+
+var ErrNoBlockInDB = errors.New("no block in DB with this id")
+
+type BlocksStorage struct {
+	mu   sync.Mutex
+	data map[string][]entity.Block
+}
+
+func NewBlocksStorage() *BlocksStorage {
+	return &BlocksStorage{
+		data: make(map[string][]entity.Block),
+	}
+}
+
+func findBlock(blocks []entity.Block, blockID string) (int, bool) {
+	for index, block := range blocks {
+		if block.ID == blockID {
+			return index, true
+		}
+	}
+	return -1, false
+}
+
+func renumber(blocks []entity.Block) {
+	for i := range blocks {
+		blocks[i].Position = i
+	}
+}
+
+func (store *BlocksStorage) Insert(noteToken string, block entity.Block) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	blocks := store.data[noteToken]
+	blocks = append(blocks, entity.Block{})
+	copy(blocks[block.Position+1:], blocks[block.Position:])
+	blocks[block.Position] = block
+	renumber(blocks)
+	store.data[noteToken] = blocks
+	return nil
+}
+
+func (store *BlocksStorage) Update(noteToken string, block entity.Block) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	blocks := store.data[noteToken]
+	index, ok := findBlock(blocks, block.ID)
+	if !ok {
+		return ErrNoBlockInDB
+	}
+	blocks[index].Type = block.Type
+	blocks[index].Content = block.Content
+	return nil
+}
+
+func (store *BlocksStorage) Move(noteToken string, blockID string, position int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	blocks := store.data[noteToken]
+	index, ok := findBlock(blocks, blockID)
+	if !ok {
+		return ErrNoBlockInDB
+	}
+
+	block := blocks[index]
+	blocks = append(blocks[:index], blocks[index+1:]...)
+	blocks = append(blocks, entity.Block{})
+	copy(blocks[position+1:], blocks[position:])
+	blocks[position] = block
+	renumber(blocks)
+	store.data[noteToken] = blocks
+	return nil
+}
+
+func (store *BlocksStorage) Delete(noteToken string, blockID string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	blocks := store.data[noteToken]
+	index, ok := findBlock(blocks, blockID)
+	if !ok {
+		return ErrNoBlockInDB
+	}
+
+	blocks = append(blocks[:index], blocks[index+1:]...)
+	renumber(blocks)
+	store.data[noteToken] = blocks
+	return nil
+}
+
+func (store *BlocksStorage) AllByNote(noteToken string) ([]entity.Block, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	blocks := store.data[noteToken]
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	result := make([]entity.Block, len(blocks))
+	copy(result, blocks)
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoCommentInDB = errors.New("no comment in DB with this id")
+
+type CommentsStorage struct {
+	mu       sync.Mutex
+	lastID   int
+	comments map[int]entity.Comment
+}
+
+func NewCommentsStorage() *CommentsStorage {
+	return &CommentsStorage{
+		comments: make(map[int]entity.Comment),
+	}
+}
+
+func (store *CommentsStorage) Save(comment entity.Comment) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastID++
+	comment.ID = store.lastID
+	comment.UpdatedAt = comment.CreatedAt
+	store.comments[comment.ID] = comment
+	return comment.ID, nil
+}
+
+func (store *CommentsStorage) Find(commentID int) (entity.Comment, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	comment, ok := store.comments[commentID]
+	if !ok {
+		return entity.Comment{}, ErrNoCommentInDB
+	}
+	return comment, nil
+}
+
+func (store *CommentsStorage) Update(comment entity.Comment) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	stored, ok := store.comments[comment.ID]
+	if !ok {
+		return ErrNoCommentInDB
+	}
+	stored.Body = comment.Body
+	stored.UpdatedAt = comment.UpdatedAt
+	store.comments[comment.ID] = stored
+	return nil
+}
+
+func (store *CommentsStorage) Delete(commentID int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.comments, commentID)
+	for id, comment := range store.comments {
+		if comment.ParentID == commentID {
+			delete(store.comments, id)
+		}
+	}
+	return nil
+}
+
+func (store *CommentsStorage) SetResolved(commentID int, resolved bool) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	comment, ok := store.comments[commentID]
+	if !ok {
+		return ErrNoCommentInDB
+	}
+	comment.Resolved = resolved
+	store.comments[commentID] = comment
+	return nil
+}
+
+func (store *CommentsStorage) AllByNote(noteToken string) ([]entity.Comment, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var comments []entity.Comment
+	for _, comment := range store.comments {
+		if comment.NoteToken == noteToken {
+			comments = append(comments, comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
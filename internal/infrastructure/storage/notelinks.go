@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/contains"
+	"sort"
+	"sync"
+)
+
+type NoteLinksStorage struct {
+	mu    sync.Mutex
+	links map[string][]string
+	notes repository.NotesRepository
+}
+
+func NewNoteLinksStorage(notesStorage repository.NotesRepository) *NoteLinksStorage {
+	return &NoteLinksStorage{
+		links: make(map[string][]string),
+		notes: notesStorage,
+	}
+}
+
+func (store *NoteLinksStorage) Replace(fromToken string, toTokens []string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if len(toTokens) == 0 {
+		delete(store.links, fromToken)
+		return nil
+	}
+	store.links[fromToken] = append([]string(nil), toTokens...)
+	return nil
+}
+
+func (store *NoteLinksStorage) Delete(noteToken string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.links, noteToken)
+	for from, to := range store.links {
+		var kept []string
+		for _, token := range to {
+			if token != noteToken {
+				kept = append(kept, token)
+			}
+		}
+		store.links[from] = kept
+	}
+	return nil
+}
+
+func (store *NoteLinksStorage) Backlinks(noteToken string) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var tokens []string
+	for from, to := range store.links {
+		if !contains.Contains(to, noteToken) {
+			continue
+		}
+		if note, err := store.notes.Find(from); err != nil || note.DeletedAt != nil {
+			continue
+		}
+		tokens = append(tokens, from)
+	}
+	sort.Strings(tokens)
+	return tokens, nil
+}
+
+func (store *NoteLinksStorage) AllByNotes(noteTokens []string) ([]entity.NoteLink, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var links []entity.NoteLink
+	for _, from := range noteTokens {
+		for _, to := range store.links[from] {
+			if contains.Contains(noteTokens, to) {
+				links = append(links, entity.NoteLink{From: from, To: to})
+			}
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].From != links[j].From {
+			return links[i].From < links[j].From
+		}
+		return links[i].To < links[j].To
+	})
+	return links, nil
+}
@@ -4,21 +4,23 @@ import (
 	"cotion/internal/domain/entity"
 	"errors"
 	"sync"
+	"time"
 )
 
 var ErrNoNoteInDB = errors.New("no note in DB with this token")
 
 type NotesStorage struct {
 	data sync.Map
+	mu   sync.Mutex
 }
 
 func NewNotesStorage() *NotesStorage {
 	store := &NotesStorage{
 		data: sync.Map{},
 	}
-	store.data.Store("1", entity.Note{Name: "1st note", Body: "Hello everybody. This is Body of the 1st note)"})
-	store.data.Store("2", entity.Note{Name: "2st note", Body: "Hello everybody. This is Body of the 2st note)"})
-	store.data.Store("3", entity.Note{Name: "3st note", Body: "Hello everybody. This is Body of the 3st note)"})
+	store.data.Store("1", entity.Note{Name: "1st note", Body: "Hello everybody. This is Body of the 1st note)", Version: 1})
+	store.data.Store("2", entity.Note{Name: "2st note", Body: "Hello everybody. This is Body of the 2st note)", Version: 1})
+	store.data.Store("3", entity.Note{Name: "3st note", Body: "Hello everybody. This is Body of the 3st note)", Version: 1})
 
 	return store
 }
@@ -33,6 +35,7 @@ func (store *NotesStorage) Find(token string) (entity.Note, error) {
 }
 
 func (store *NotesStorage) Save(token string, note entity.Note) error {
+	note.Version = 1
 	_, ok := store.data.LoadOrStore(token, note)
 	if ok {
 		return errors.New("there is note in DB with this token")
@@ -40,12 +43,162 @@ func (store *NotesStorage) Save(token string, note entity.Note) error {
 	return nil
 }
 
-func (store *NotesStorage) Update(token string, note entity.Note) error {
+func (store *NotesStorage) Update(token string, note entity.Note) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	stored, err := store.Find(token)
+	if err != nil {
+		return 0, err
+	}
+	if note.Version != 0 && note.Version != stored.Version {
+		return 0, entity.ErrNoteVersionConflict
+	}
+	stored.Name = note.Name
+	stored.Body = note.Body
+	stored.UpdatedAt = note.UpdatedAt
+	stored.LastEditedBy = note.LastEditedBy
+	stored.Version++
+	store.data.Store(token, stored)
+	return stored.Version, nil
+}
+
+func (store *NotesStorage) Delete(token string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	tokens, _ := store.Subtree(token)
+	for _, noteToken := range tokens {
+		store.data.Delete(noteToken)
+	}
+	return nil
+}
+
+func (store *NotesStorage) Move(token string, parentToken string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	note, err := store.Find(token)
+	if err != nil {
+		return err
+	}
+	for ancestor := parentToken; ancestor != ""; {
+		if ancestor == token {
+			return entity.ErrNoteCycle
+		}
+		parent, err := store.Find(ancestor)
+		if err != nil {
+			break
+		}
+		ancestor = parent.Parent
+	}
+	note.Parent = parentToken
 	store.data.Store(token, note)
 	return nil
 }
 
-func (store *NotesStorage) Delete(token string) error {
-	store.data.Delete(token)
+func (store *NotesStorage) SetIcon(token string, icon *entity.NoteIcon) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	note, err := store.Find(token)
+	if err != nil {
+		return err
+	}
+	note.Icon = icon
+	store.data.Store(token, note)
+	return nil
+}
+
+func (store *NotesStorage) SetCover(token string, cover string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	note, err := store.Find(token)
+	if err != nil {
+		return err
+	}
+	note.Cover = cover
+	store.data.Store(token, note)
 	return nil
 }
+
+func (store *NotesStorage) Subtree(token string) ([]string, error) {
+	if _, ok := store.data.Load(token); !ok {
+		return nil, ErrNoNoteInDB
+	}
+
+	tokens := []string{token}
+	for i := 0; i < len(tokens); i++ {
+		store.data.Range(func(key, value interface{}) bool {
+			if value.(entity.Note).Parent == tokens[i] {
+				tokens = append(tokens, key.(string))
+			}
+			return true
+		})
+	}
+	return tokens, nil
+}
+
+func (store *NotesStorage) Trash(token string, deletedAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	tokens, err := store.Subtree(token)
+	if err != nil {
+		return err
+	}
+	for _, noteToken := range tokens {
+		note, _ := store.Find(noteToken)
+		if note.DeletedAt != nil {
+			continue
+		}
+		note.DeletedAt = &deletedAt
+		store.data.Store(noteToken, note)
+	}
+	return nil
+}
+
+func (store *NotesStorage) Restore(token string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	root, err := store.Find(token)
+	if err != nil {
+		return err
+	}
+	if root.DeletedAt == nil {
+		return nil
+	}
+	deletedAt := *root.DeletedAt
+
+	tokens := []string{token}
+	for i := 0; i < len(tokens); i++ {
+		store.data.Range(func(key, value interface{}) bool {
+			note := value.(entity.Note)
+			if note.Parent == tokens[i] && note.DeletedAt != nil && note.DeletedAt.Equal(deletedAt) {
+				tokens = append(tokens, key.(string))
+			}
+			return true
+		})
+	}
+
+	for _, noteToken := range tokens {
+		note, _ := store.Find(noteToken)
+		note.DeletedAt = nil
+		store.data.Store(noteToken, note)
+	}
+	return nil
+}
+
+func (store *NotesStorage) ExpiredTrash(before time.Time) ([]string, error) {
+	var tokens []string
+	store.data.Range(func(key, value interface{}) bool {
+		note := value.(entity.Note)
+		if note.DeletedAt != nil && note.DeletedAt.Before(before) {
+			tokens = append(tokens, key.(string))
+		}
+		return true
+	})
+	return tokens, nil
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"encoding/base64"
+	"sort"
+	"unicode/utf8"
+)
+
+var noteLess = map[string]func(a, b entity.ShortNote) bool{
+	entity.SortName: func(a, b entity.ShortNote) bool {
+		return a.Name < b.Name
+	},
+	entity.SortCreated: func(a, b entity.ShortNote) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	},
+	entity.SortUpdated: func(a, b entity.ShortNote) bool {
+		return a.UpdatedAt.Before(b.UpdatedAt)
+	},
+}
+
+// pageNotes mirrors the ordering and paging of the Postgres storage on
+// notes that are already in the user's manual order. The cursor is the
+// token of the last returned note.
+func pageNotes(notes []entity.ShortNote, filter entity.NotesFilter) (entity.ShortNotes, error) {
+	less, ok := noteLess[filter.Sort]
+	if !ok {
+		less = func(a, b entity.ShortNote) bool {
+			return a.Pinned && !b.Pinned
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		if filter.Desc {
+			return less(notes[j], notes[i])
+		}
+		return less(notes[i], notes[j])
+	})
+
+	result := entity.ShortNotes{Total: len(notes)}
+	if filter.Cursor != "" {
+		token, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+		if err != nil {
+			return entity.ShortNotes{}, entity.ErrInvalidCursor
+		}
+		index := -1
+		for i, note := range notes {
+			if note.Token == string(token) {
+				index = i
+			}
+		}
+		if index < 0 {
+			return entity.ShortNotes{}, entity.ErrInvalidCursor
+		}
+		notes = notes[index+1:]
+	}
+
+	if filter.Limit > 0 && len(notes) > filter.Limit {
+		notes = notes[:filter.Limit]
+		result.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(notes[len(notes)-1].Token))
+	}
+
+	for i := range notes {
+		switch filter.Body {
+		case entity.BodyNone:
+			notes[i].Body = ""
+		case entity.BodyPreview:
+			notes[i].Body = previewBody(notes[i].Body)
+		}
+	}
+
+	result.ShortNote = notes
+	return result, nil
+}
+
+func previewBody(body string) string {
+	if utf8.RuneCountInString(body) <= entity.BodyPreviewLength {
+		return body
+	}
+	return string([]rune(body)[:entity.BodyPreviewLength])
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoNotificationInDB = errors.New("no notification in DB with this id")
+
+type MentionsStorage struct {
+	mu       sync.Mutex
+	mentions map[entity.Mention]bool
+}
+
+func NewMentionsStorage() *MentionsStorage {
+	return &MentionsStorage{
+		mentions: make(map[entity.Mention]bool),
+	}
+}
+
+func (store *MentionsStorage) Save(mention entity.Mention) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.mentions[mention] = true
+	return nil
+}
+
+func (store *MentionsStorage) Delete(mention entity.Mention) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.mentions, mention)
+	return nil
+}
+
+func (store *MentionsStorage) Mentioned(noteToken string, commentID int) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var userIDs []string
+	for mention := range store.mentions {
+		if mention.NoteToken == noteToken && mention.CommentID == commentID {
+			userIDs = append(userIDs, mention.UserID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+type NotificationsStorage struct {
+	mu            sync.Mutex
+	lastID        int
+	notifications map[int]entity.Notification
+}
+
+func NewNotificationsStorage() *NotificationsStorage {
+	return &NotificationsStorage{
+		notifications: make(map[int]entity.Notification),
+	}
+}
+
+func (store *NotificationsStorage) Save(notification entity.Notification) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastID++
+	notification.ID = store.lastID
+	store.notifications[notification.ID] = notification
+	return notification.ID, nil
+}
+
+func (store *NotificationsStorage) AllByUserID(userID string, limit int) ([]entity.Notification, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var notifications []entity.Notification
+	for _, notification := range store.notifications {
+		if notification.UserID == userID {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].ID > notifications[j].ID
+	})
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (store *NotificationsStorage) CountUnread(userID string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	count := 0
+	for _, notification := range store.notifications {
+		if notification.UserID == userID && !notification.Read {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (store *NotificationsStorage) MarkRead(userID string, notificationID int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	notification, ok := store.notifications[notificationID]
+	if !ok || notification.UserID != userID {
+		return ErrNoNotificationInDB
+	}
+	notification.Read = true
+	store.notifications[notificationID] = notification
+	return nil
+}
+
+func (store *NotificationsStorage) MarkAllRead(userID string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for id, notification := range store.notifications {
+		if notification.UserID == userID {
+			notification.Read = true
+			store.notifications[id] = notification
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sync"
+)
+
+var ErrNoRevisionInDB = errors.New("no revision of this note in DB")
+
+type RevisionsStorage struct {
+	mu     sync.Mutex
+	lastID int
+	data   map[string][]entity.Revision
+}
+
+func NewRevisionsStorage() *RevisionsStorage {
+	return &RevisionsStorage{
+		data: make(map[string][]entity.Revision),
+	}
+}
+
+func (store *RevisionsStorage) Save(noteToken string, revision entity.Revision) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastID++
+	revision.ID = store.lastID
+	store.data[noteToken] = append(store.data[noteToken], revision)
+	return nil
+}
+
+func (store *RevisionsStorage) Find(noteToken string, revisionID int) (entity.Revision, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, revision := range store.data[noteToken] {
+		if revision.ID == revisionID {
+			return revision, nil
+		}
+	}
+	return entity.Revision{}, ErrNoRevisionInDB
+}
+
+func (store *RevisionsStorage) AllByNote(noteToken string) ([]entity.Revision, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	revisions := store.data[noteToken]
+	result := make([]entity.Revision, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := revisions[i]
+		revision.Body = ""
+		revision.Blocks = nil
+		result = append(result, revision)
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sync"
+)
+
+var ErrNoShareLinkInDB = errors.New("no share link in DB with this token")
+
+type ShareLinksStorage struct {
+	data sync.Map
+}
+
+func NewShareLinksStorage() *ShareLinksStorage {
+	return &ShareLinksStorage{}
+}
+
+func (store *ShareLinksStorage) Save(link entity.ShareLink) error {
+	if _, loaded := store.data.LoadOrStore(link.Token, link); loaded {
+		return errors.New("there is share link in DB with this token")
+	}
+	return nil
+}
+
+func (store *ShareLinksStorage) Find(shareToken string) (entity.ShareLink, error) {
+	link, ok := store.data.Load(shareToken)
+	if !ok {
+		return entity.ShareLink{}, ErrNoShareLinkInDB
+	}
+	return link.(entity.ShareLink), nil
+}
+
+func (store *ShareLinksStorage) Delete(shareToken string) error {
+	store.data.Delete(shareToken)
+	return nil
+}
+
+func (store *ShareLinksStorage) AllByNote(noteToken string) ([]entity.ShareLink, error) {
+	var links []entity.ShareLink
+	store.data.Range(func(key, value interface{}) bool {
+		if link := value.(entity.ShareLink); link.NoteToken == noteToken {
+			links = append(links, link)
+		}
+		return true
+	})
+	return links, nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoTagInDB = errors.New("no tag in DB with this id")
+
+type TagsStorage struct {
+	mu     sync.Mutex
+	lastID int
+	tags   map[int]entity.Tag
+	notes  map[int]map[string]bool
+}
+
+func NewTagsStorage() *TagsStorage {
+	return &TagsStorage{
+		tags:  make(map[int]entity.Tag),
+		notes: make(map[int]map[string]bool),
+	}
+}
+
+func (store *TagsStorage) Save(tag entity.Tag) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastID++
+	tag.ID = store.lastID
+	store.tags[tag.ID] = tag
+	store.notes[tag.ID] = make(map[string]bool)
+	return tag.ID, nil
+}
+
+func (store *TagsStorage) Update(tag entity.Tag) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	stored, ok := store.tags[tag.ID]
+	if !ok {
+		return ErrNoTagInDB
+	}
+	stored.Name = tag.Name
+	stored.Color = tag.Color
+	store.tags[tag.ID] = stored
+	return nil
+}
+
+func (store *TagsStorage) Delete(tagID int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.tags, tagID)
+	delete(store.notes, tagID)
+	return nil
+}
+
+func (store *TagsStorage) Find(tagID int) (entity.Tag, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	tag, ok := store.tags[tagID]
+	if !ok {
+		return entity.Tag{}, ErrNoTagInDB
+	}
+	return tag, nil
+}
+
+func (store *TagsStorage) AllByUserID(userID string) ([]entity.Tag, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var tags []entity.Tag
+	for _, tag := range store.tags {
+		if tag.UserID == userID {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (store *TagsStorage) Attach(tagID int, noteToken string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	notes, ok := store.notes[tagID]
+	if !ok {
+		return ErrNoTagInDB
+	}
+	notes[noteToken] = true
+	return nil
+}
+
+func (store *TagsStorage) Detach(tagID int, noteToken string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if notes, ok := store.notes[tagID]; ok {
+		delete(notes, noteToken)
+	}
+	return nil
+}
+
+func (store *TagsStorage) NoteTokens(userID string, tagIDs []int, matchAll bool) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, tagID := range tagIDs {
+		if tag, ok := store.tags[tagID]; !ok || tag.UserID != userID {
+			continue
+		}
+		for noteToken := range store.notes[tagID] {
+			counts[noteToken]++
+		}
+	}
+
+	var tokens []string
+	for noteToken, count := range counts {
+		if !matchAll || count == len(tagIDs) {
+			tokens = append(tokens, noteToken)
+		}
+	}
+	sort.Strings(tokens)
+	return tokens, nil
+}
+
+func (store *TagsStorage) NotesTags(userID string) (map[string][]entity.Tag, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	tags := make(map[string][]entity.Tag)
+	for tagID, notes := range store.notes {
+		tag := store.tags[tagID]
+		if tag.UserID != userID {
+			continue
+		}
+		for noteToken := range notes {
+			tags[noteToken] = append(tags[noteToken], tag)
+		}
+	}
+	for _, noteTags := range tags {
+		sort.Slice(noteTags, func(i, j int) bool {
+			return noteTags[i].Name < noteTags[j].Name
+		})
+	}
+	return tags, nil
+}
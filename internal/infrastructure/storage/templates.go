@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoTemplateInDB = errors.New("no template in DB with this id")
+
+type TemplatesStorage struct {
+	mu        sync.Mutex
+	lastID    int
+	templates map[int]entity.Template
+}
+
+func NewTemplatesStorage() *TemplatesStorage {
+	store := &TemplatesStorage{
+		templates: make(map[int]entity.Template),
+	}
+	store.Save(entity.Template{
+		Name: "Meeting {{date}}",
+		Body: "Date: {{date}}\nNotes by: {{username}}\n\nAttendees:\n- \n\nAgenda:\n- \n\nNotes:\n\nAction items:\n- [ ] ",
+	})
+	store.Save(entity.Template{
+		Name: "Retro {{date}}",
+		Body: "What went well:\n- \n\nWhat could be better:\n- \n\nAction items:\n- [ ] ",
+	})
+	return store
+}
+
+func (store *TemplatesStorage) Save(template entity.Template) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.lastID++
+	template.ID = store.lastID
+	template.Tags = append([]int{}, template.Tags...)
+	store.templates[template.ID] = template
+	return template.ID, nil
+}
+
+func (store *TemplatesStorage) Update(template entity.Template) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.templates[template.ID]; !ok {
+		return ErrNoTemplateInDB
+	}
+	template.Tags = append([]int{}, template.Tags...)
+	store.templates[template.ID] = template
+	return nil
+}
+
+func (store *TemplatesStorage) Delete(templateID int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.templates, templateID)
+	return nil
+}
+
+func (store *TemplatesStorage) Find(templateID int) (entity.Template, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	template, ok := store.templates[templateID]
+	if !ok {
+		return entity.Template{}, ErrNoTemplateInDB
+	}
+	return template, nil
+}
+
+func (store *TemplatesStorage) AllByUserID(userID string) ([]entity.Template, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var templates []entity.Template
+	for _, template := range store.templates {
+		if template.UserID == userID || template.UserID == "" {
+			templates = append(templates, template)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if (templates[i].UserID == "") != (templates[j].UserID == "") {
+			return templates[i].UserID == ""
+		}
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].ID < templates[j].ID
+	})
+	return templates, nil
+}
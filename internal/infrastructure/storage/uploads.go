@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"cotion/internal/domain/entity"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrNoUploadInDB = errors.New("no upload in DB with this id")
+
+type UploadsStorage struct {
+	mu      sync.Mutex
+	uploads map[string]entity.Upload
+}
+
+func NewUploadsStorage() *UploadsStorage {
+	return &UploadsStorage{
+		uploads: make(map[string]entity.Upload),
+	}
+}
+
+func (store *UploadsStorage) Save(upload entity.Upload) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.uploads[upload.ID]; ok {
+		return errors.New("there is upload in DB with this id")
+	}
+	upload.Offset = 0
+	upload.Parts = nil
+	store.uploads[upload.ID] = upload
+	return nil
+}
+
+func (store *UploadsStorage) Find(uploadID string) (entity.Upload, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	upload, ok := store.uploads[uploadID]
+	if !ok {
+		return entity.Upload{}, ErrNoUploadInDB
+	}
+	upload.Parts = append([]entity.UploadPart(nil), upload.Parts...)
+	return upload, nil
+}
+
+func (store *UploadsStorage) Update(upload entity.Upload, offset int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	stored, ok := store.uploads[upload.ID]
+	if !ok || stored.Offset != offset {
+		return entity.ErrUploadOffsetConflict
+	}
+	stored.Offset = upload.Offset
+	stored.ContentType = upload.ContentType
+	stored.ObjectName = upload.ObjectName
+	stored.StorageID = upload.StorageID
+	stored.Parts = append([]entity.UploadPart(nil), upload.Parts...)
+	store.uploads[upload.ID] = stored
+	return nil
+}
+
+func (store *UploadsStorage) Delete(uploadID string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.uploads, uploadID)
+	return nil
+}
+
+func (store *UploadsStorage) AllByNote(noteToken string) ([]entity.Upload, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var uploads []entity.Upload
+	for _, upload := range store.uploads {
+		if upload.NoteToken == noteToken {
+			uploads = append(uploads, upload)
+		}
+	}
+	return uploads, nil
+}
+
+func (store *UploadsStorage) Expired(before time.Time) ([]entity.Upload, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var uploads []entity.Upload
+	for _, upload := range store.uploads {
+		if upload.ExpiresAt.Before(before) {
+			uploads = append(uploads, upload)
+		}
+	}
+	return uploads, nil
+}
@@ -55,6 +55,27 @@ func (r *UserCacheStorage) Get(userID string) (entity.User, error) {
 	return *user, nil
 }
 
+func (r *UserCacheStorage) FindByUsername(username string) (entity.User, error) {
+	var found *entity.User
+	r.data.Range(func(key, value interface{}) bool {
+		user := *value.(*entity.User)
+		if user.Username != username {
+			return true
+		}
+		if user.UserID == "" {
+			user.UserID = key.(string)
+		}
+		if found == nil || user.UserID < found.UserID {
+			found = &user
+		}
+		return true
+	})
+	if found == nil {
+		return entity.User{}, ErrNoUserInDB
+	}
+	return *found, nil
+}
+
 func (r *UserCacheStorage) Update(user entity.User) error {
 	r.data.Store(user.UserID, &user)
 	return nil
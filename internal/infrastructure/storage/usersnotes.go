@@ -3,14 +3,27 @@ package storage
 import (
 	"cotion/internal/domain/entity"
 	"cotion/internal/domain/repository"
+	"cotion/internal/pkg/contains"
 	"cotion/internal/pkg/security"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
+	"unicode/utf8"
+)
+
+const (
+	nameMatchRank = 1.0
+	bodyMatchRank = 0.1
+	snippetRadius = 40
 )
 
 type UsersNotesStorage struct {
-	data  sync.Map
-	notes repository.NotesRepository
+	data      sync.Map
+	roles     sync.Map
+	pinned    sync.Map
+	favorites sync.Map
+	notes     repository.NotesRepository
 }
 
 var ErrFindNotesForUser = errors.New("cannot find notes")
@@ -23,13 +36,18 @@ func NewUsersNotesStorage(notesStorage repository.NotesRepository) *UsersNotesSt
 		data:  sync.Map{},
 		notes: notesStorage,
 	}
-	storage.data.Store(security.Hash("test@mail.ru"), []string{"1", "3"})
-	storage.data.Store(security.Hash("test3@mail.ru"), []string{"0"})
-	storage.data.Store(security.Hash("nikita@mail.ru"), []string{"2"})
+	storage.AddLink(security.Hash("test@mail.ru"), "1", entity.RoleOwner)
+	storage.AddLink(security.Hash("test@mail.ru"), "3", entity.RoleOwner)
+	storage.AddLink(security.Hash("test3@mail.ru"), "0", entity.RoleOwner)
+	storage.AddLink(security.Hash("nikita@mail.ru"), "2", entity.RoleOwner)
 	return storage
 }
 
-func (storage *UsersNotesStorage) AllNotesByUserID(hashedEmail string) (entity.ShortNotes, error) {
+func linkKey(userID string, noteToken string) string {
+	return userID + "/" + noteToken
+}
+
+func (storage *UsersNotesStorage) AllNotesByUserID(hashedEmail string, filter entity.NotesFilter) (entity.ShortNotes, error) {
 	rawNotesIDs, ok := storage.data.Load(hashedEmail)
 	if !ok {
 		return entity.ShortNotes{}, ErrFindNotesForUser
@@ -39,18 +57,38 @@ func (storage *UsersNotesStorage) AllNotesByUserID(hashedEmail string) (entity.S
 	notes := entity.ShortNotes{}
 
 	for _, id := range notesIDs {
+		if len(filter.Tokens) != 0 && !contains.Contains(filter.Tokens, id) {
+			continue
+		}
 		note, err := storage.notes.Find(id)
 		if err != nil {
 			return entity.ShortNotes{}, ErrFindNoteByToken
 		}
+		if note.DeletedAt != nil {
+			continue
+		}
+		_, pinned := storage.pinned.Load(linkKey(hashedEmail, id))
+		_, favorite := storage.favorites.Load(linkKey(hashedEmail, id))
+		if filter.Favorites && !favorite {
+			continue
+		}
 		shortNote := entity.ShortNote{
-			Name: note.Name,
-			Body: note.Body,
+			Name:         note.Name,
+			Icon:         note.Icon,
+			Body:         note.Body,
+			Token:        id,
+			Parent:       note.Parent,
+			Pinned:       pinned,
+			Favorite:     favorite,
+			CreatedAt:    note.CreatedAt,
+			UpdatedAt:    note.UpdatedAt,
+			CreatedBy:    note.CreatedBy,
+			LastEditedBy: note.LastEditedBy,
 		}
 		notes.ShortNote = append(notes.ShortNote, shortNote)
 	}
 
-	return notes, nil
+	return pageNotes(notes.ShortNote, filter)
 }
 
 func (storage *UsersNotesStorage) TokensByUserID(hashedEmail string) ([]string, error) {
@@ -61,7 +99,8 @@ func (storage *UsersNotesStorage) TokensByUserID(hashedEmail string) ([]string,
 	return rawNotesIDs.([]string), nil
 }
 
-func (storage *UsersNotesStorage) AddLink(userID string, noteToken string) error {
+func (storage *UsersNotesStorage) AddLink(userID string, noteToken string, role string) error {
+	storage.roles.Store(linkKey(userID, noteToken), role)
 	rawNotesIDs, ok := storage.data.Load(userID)
 	if !ok {
 		storage.data.Store(userID, []string{noteToken})
@@ -94,9 +133,13 @@ func (storage *UsersNotesStorage) DeleteLink(userID string, noteToken string) er
 		return ErrFindTokenInUsersNotes
 	}
 
-	NotesIDs[noteIndex] = NotesIDs[len(NotesIDs)-1]
-	NotesIDs = NotesIDs[:len(NotesIDs)-1]
-	storage.data.Store(userID, NotesIDs)
+	rest := make([]string, 0, len(NotesIDs)-1)
+	rest = append(rest, NotesIDs[:noteIndex]...)
+	rest = append(rest, NotesIDs[noteIndex+1:]...)
+	storage.data.Store(userID, rest)
+	storage.roles.Delete(linkKey(userID, noteToken))
+	storage.pinned.Delete(linkKey(userID, noteToken))
+	storage.favorites.Delete(linkKey(userID, noteToken))
 	return nil
 }
 
@@ -109,3 +152,186 @@ func (storage *UsersNotesStorage) CheckLink(userID string, noteToken string) boo
 	_, ok = findNote(NotesIDs, noteToken)
 	return ok
 }
+
+func (storage *UsersNotesStorage) Role(userID string, noteToken string) (string, error) {
+	role, ok := storage.roles.Load(linkKey(userID, noteToken))
+	if !ok {
+		return "", ErrFindTokenInUsersNotes
+	}
+	return role.(string), nil
+}
+
+func (storage *UsersNotesStorage) UpdateRole(userID string, noteToken string, role string) error {
+	if _, ok := storage.roles.Load(linkKey(userID, noteToken)); !ok {
+		return ErrFindTokenInUsersNotes
+	}
+	storage.roles.Store(linkKey(userID, noteToken), role)
+	return nil
+}
+
+func (storage *UsersNotesStorage) Members(noteToken string) ([]entity.Member, error) {
+	var members []entity.Member
+	storage.data.Range(func(key, value interface{}) bool {
+		userID := key.(string)
+		if _, ok := findNote(value.([]string), noteToken); ok {
+			role, _ := storage.Role(userID, noteToken)
+			members = append(members, entity.Member{
+				UserID: userID,
+				Role:   role,
+			})
+		}
+		return true
+	})
+	return members, nil
+}
+
+func (storage *UsersNotesStorage) TrashByUserID(userID string) ([]entity.TrashedNote, error) {
+	tokens, err := storage.TokensByUserID(userID)
+	if err != nil {
+		return nil, nil
+	}
+
+	var notes []entity.TrashedNote
+	for _, token := range tokens {
+		if role, _ := storage.Role(userID, token); role != entity.RoleOwner {
+			continue
+		}
+		note, err := storage.notes.Find(token)
+		if err != nil || note.DeletedAt == nil {
+			continue
+		}
+		notes = append(notes, entity.TrashedNote{
+			Name:      note.Name,
+			Token:     token,
+			Parent:    note.Parent,
+			DeletedAt: *note.DeletedAt,
+		})
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].DeletedAt.After(notes[j].DeletedAt)
+	})
+	return notes, nil
+}
+
+func (storage *UsersNotesStorage) SetPinned(userID string, noteToken string, pinned bool) error {
+	return storage.setFlag(&storage.pinned, userID, noteToken, pinned)
+}
+
+func (storage *UsersNotesStorage) SetFavorite(userID string, noteToken string, favorite bool) error {
+	return storage.setFlag(&storage.favorites, userID, noteToken, favorite)
+}
+
+func (storage *UsersNotesStorage) setFlag(flags *sync.Map, userID string, noteToken string, value bool) error {
+	if !storage.CheckLink(userID, noteToken) {
+		return ErrFindTokenInUsersNotes
+	}
+	if value {
+		flags.Store(linkKey(userID, noteToken), true)
+	} else {
+		flags.Delete(linkKey(userID, noteToken))
+	}
+	return nil
+}
+
+// Reorder puts the given notes first and keeps the rest after them.
+func (storage *UsersNotesStorage) Reorder(userID string, noteTokens []string) error {
+	rawNotesIDs, ok := storage.data.Load(userID)
+	if !ok {
+		return ErrFindUser
+	}
+	NotesIDs := rawNotesIDs.([]string)
+
+	ordered := make([]string, 0, len(NotesIDs))
+	for _, token := range noteTokens {
+		if contains.Contains(NotesIDs, token) && !contains.Contains(ordered, token) {
+			ordered = append(ordered, token)
+		}
+	}
+	for _, token := range NotesIDs {
+		if !contains.Contains(ordered, token) {
+			ordered = append(ordered, token)
+		}
+	}
+	storage.data.Store(userID, ordered)
+	return nil
+}
+
+// SearchNotes is a naive substring counterpart of the Postgres full-text search.
+func (storage *UsersNotesStorage) SearchNotes(userID string, query string, limit int) ([]entity.FoundNote, error) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	notes, err := storage.AllNotesByUserID(userID, entity.NotesFilter{})
+	if err == ErrFindNotesForUser {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var found []entity.FoundNote
+	for _, note := range notes.ShortNote {
+		name, body := strings.ToLower(note.Name), strings.ToLower(note.Body)
+		var rank float64
+		for _, word := range words {
+			rank += nameMatchRank*float64(strings.Count(name, word)) + bodyMatchRank*float64(strings.Count(body, word))
+		}
+		if rank == 0 {
+			continue
+		}
+		found = append(found, entity.FoundNote{
+			Name:    note.Name,
+			Token:   note.Token,
+			Snippet: searchSnippet(note.Body, words),
+			Rank:    rank,
+		})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Rank > found[j].Rank
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func searchSnippet(body string, words []string) string {
+	lower := strings.ToLower(body)
+	if len(lower) != len(body) {
+		lower = body
+	}
+
+	for _, word := range words {
+		index := strings.Index(lower, word)
+		if index < 0 {
+			continue
+		}
+		start, end := index-snippetRadius, index+len(word)+snippetRadius
+		if start < 0 {
+			start = 0
+		}
+		if end > len(body) {
+			end = len(body)
+		}
+		for start > 0 && !utf8.RuneStart(body[start]) {
+			start--
+		}
+		for end < len(body) && !utf8.RuneStart(body[end]) {
+			end++
+		}
+		return body[start:index] + "<b>" + body[index:index+len(word)] + "</b>" + body[index+len(word):end]
+	}
+
+	end := 2 * snippetRadius
+	if end > len(body) {
+		end = len(body)
+	}
+	for end < len(body) && !utf8.RuneStart(body[end]) {
+		end++
+	}
+	return body[:end]
+}
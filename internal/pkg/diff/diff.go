@@ -0,0 +1,98 @@
+package diff
+
+import "strings"
+
+const (
+	prefixEqual  = " "
+	prefixDelete = "-"
+	prefixInsert = "+"
+
+	// maxTableSize bounds the LCS table of the lines that differ. Larger
+	// changes are shown as a removal of the old lines and an insertion of
+	// the new ones.
+	maxTableSize = 1 << 20
+)
+
+// Lines returns a line-based diff of a and b. Every line of the result is
+// prefixed with " " (unchanged), "-" (only in a) or "+" (only in b).
+func Lines(a string, b string) string {
+	oldLines := strings.Split(a, "\n")
+	newLines := strings.Split(b, "\n")
+
+	// Only the lines between the common beginning and end can differ.
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	var result []string
+	for _, line := range oldLines[:prefix] {
+		result = append(result, prefixEqual+line)
+	}
+	result = append(result, changed(oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix])...)
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		result = append(result, prefixEqual+line)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// changed diffs the lines by their longest common subsequence.
+func changed(oldLines []string, newLines []string) []string {
+	var result []string
+	if (len(oldLines)+1)*(len(newLines)+1) > maxTableSize {
+		for _, line := range oldLines {
+			result = append(result, prefixDelete+line)
+		}
+		for _, line := range newLines {
+			result = append(result, prefixInsert+line)
+		}
+		return result
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// oldLines[i:] and newLines[j:].
+	lcs := make([][]int32, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			result = append(result, prefixEqual+oldLines[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, prefixDelete+oldLines[i])
+			i++
+		default:
+			result = append(result, prefixInsert+newLines[j])
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		result = append(result, prefixDelete+oldLines[i])
+	}
+	for ; j < len(newLines); j++ {
+		result = append(result, prefixInsert+newLines[j])
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	cases := map[string]struct {
+		a        string
+		b        string
+		expected string
+	}{
+		"equal": {
+			a:        "first\nsecond",
+			b:        "first\nsecond",
+			expected: " first\n second",
+		},
+		"changed line": {
+			a:        "first\nsecond\nthird",
+			b:        "first\n2nd\nthird",
+			expected: " first\n-second\n+2nd\n third",
+		},
+		"appended lines": {
+			a:        "first",
+			b:        "first\nsecond\nthird",
+			expected: " first\n+second\n+third",
+		},
+		"removed lines": {
+			a:        "first\nsecond\nthird",
+			b:        "third",
+			expected: "-first\n-second\n third",
+		},
+		"changes between common lines": {
+			a:        "head\na\nb\ntail",
+			b:        "head\nb\nc\ntail",
+			expected: " head\n-a\n b\n+c\n tail",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Lines(tc.a, tc.b))
+		})
+	}
+}
+
+func TestLinesLargeChange(t *testing.T) {
+	a := strings.Repeat("a\n", 2000) + "end"
+	b := strings.Repeat("b\n", 2000) + "end"
+
+	lines := strings.Split(Lines(a, b), "\n")
+	assert.Equal(t, 4001, len(lines))
+	assert.Equal(t, "-a", lines[0])
+	assert.Equal(t, "+b", lines[2000])
+	assert.Equal(t, " end", lines[4000])
+}
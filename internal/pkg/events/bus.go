@@ -0,0 +1,53 @@
+// Package events is an in-process publish/subscribe bus for note events.
+package events
+
+import (
+	"cotion/internal/domain/entity"
+	"sync"
+)
+
+// Handler is called synchronously by Publish, so it must not block.
+type Handler func(event entity.NoteEvent)
+
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[int]Handler
+	nextID   int
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: map[int]Handler{},
+	}
+}
+
+// Subscribe registers the handler for every published event. The returned
+// function removes it.
+func (b *Bus) Subscribe(handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+// Publish calls every handler with the event. Publishing on a nil bus does
+// nothing.
+func (b *Bus) Publish(event entity.NoteEvent) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"cotion/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+
+	var first, second []string
+	unsubscribe := bus.Subscribe(func(event entity.NoteEvent) {
+		first = append(first, event.NoteToken)
+	})
+	bus.Subscribe(func(event entity.NoteEvent) {
+		second = append(second, event.NoteToken)
+	})
+
+	bus.Publish(entity.NoteEvent{Type: entity.NoteCreated, NoteToken: "1"})
+	unsubscribe()
+	bus.Publish(entity.NoteEvent{Type: entity.NoteUpdated, NoteToken: "2"})
+
+	assert.Equal(t, []string{"1"}, first)
+	assert.Equal(t, []string{"1", "2"}, second)
+
+	var nilBus *Bus
+	nilBus.Publish(entity.NoteEvent{})
+}
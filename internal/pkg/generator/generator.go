@@ -1,6 +1,8 @@
 package generator
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"math/rand"
 	"strconv"
 	"time"
@@ -31,3 +33,12 @@ func RandToken() string {
 	}
 	return token
 }
+
+// RandSecureToken returns an unguessable hex token made of n random bytes.
+func RandSecureToken(n int) (string, error) {
+	token := make([]byte, n)
+	if _, err := cryptorand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,143 @@
+package markdown
+
+import (
+	"cotion/internal/domain/entity"
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"strings"
+	"unicode"
+)
+
+const frontMatterDelimiter = "---"
+
+// blockContent is the part of the block content that can be written as
+// Markdown, other fields are left out.
+type blockContent struct {
+	Text     string `json:"text"`
+	Checked  bool   `json:"checked"`
+	Level    int    `json:"level"`
+	Language string `json:"language"`
+	URL      string `json:"url"`
+}
+
+// Render writes the note as Markdown: the name as the top level heading,
+// then the body and the blocks.
+func Render(note entity.Note) string {
+	var parts []string
+	if name := strings.TrimSpace(note.Name); name != "" {
+		parts = append(parts, "# "+name)
+	}
+	if body := strings.Trim(note.Body, "\r\n"); body != "" {
+		parts = append(parts, body)
+	}
+	for _, block := range note.Blocks {
+		if part := renderBlock(block); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+func renderBlock(block entity.Block) string {
+	var content blockContent
+	if err := json.Unmarshal(block.Content, &content); err != nil {
+		return ""
+	}
+
+	switch block.Type {
+	case entity.BlockHeading:
+		level := content.Level
+		if level < 1 || level > 6 {
+			level = 2
+		}
+		return strings.Repeat("#", level) + " " + content.Text
+	case entity.BlockTodo:
+		if content.Checked {
+			return "- [x] " + content.Text
+		}
+		return "- [ ] " + content.Text
+	case entity.BlockCode:
+		fence := strings.Repeat("`", longestRun(content.Text, '`')+1)
+		if len(fence) < 3 {
+			fence = "```"
+		}
+		return fence + content.Language + "\n" + strings.TrimRight(content.Text, "\n") + "\n" + fence
+	case entity.BlockImage:
+		if content.URL == "" {
+			return ""
+		}
+		return "![" + content.Text + "](" + content.URL + ")"
+	default:
+		return content.Text
+	}
+}
+
+func longestRun(text string, char rune) int {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r != char {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// Parse splits a Markdown document into the name and the body of a note.
+// The name is the title of the YAML front matter or, without one, the top
+// level heading the document starts with; it is empty if there is neither.
+// The front matter and the heading are not part of the body.
+func Parse(document string) (string, string) {
+	document = strings.TrimPrefix(document, "\uFEFF")
+	document = strings.ReplaceAll(document, "\r\n", "\n")
+
+	title, body := parseFrontMatter(document)
+	body = strings.TrimLeft(body, "\n")
+
+	firstLine := body
+	rest := ""
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		firstLine, rest = body[:i], body[i+1:]
+	}
+	if heading, ok := topHeading(firstLine); ok && (title == "" || heading == title) {
+		title = heading
+		body = strings.TrimLeft(rest, "\n")
+	}
+
+	return title, strings.TrimRightFunc(body, unicode.IsSpace)
+}
+
+// parseFrontMatter returns the title from the front matter and the document
+// after it. A document without a valid front matter is returned as is.
+func parseFrontMatter(document string) (string, string) {
+	lines := strings.SplitAfter(document, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontMatterDelimiter {
+		return "", document
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != frontMatterDelimiter {
+			continue
+		}
+		var matter struct {
+			Title string `yaml:"title"`
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:i], "")), &matter); err != nil {
+			return "", document
+		}
+		return strings.TrimSpace(matter.Title), strings.Join(lines[i+1:], "")
+	}
+	return "", document
+}
+
+func topHeading(line string) (string, bool) {
+	if !strings.HasPrefix(line, "# ") {
+		return "", false
+	}
+	heading := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[2:]), "#"))
+	return heading, heading != ""
+}
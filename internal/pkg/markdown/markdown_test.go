@@ -0,0 +1,84 @@
+package markdown
+
+import (
+	"cotion/internal/domain/entity"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	note := entity.Note{
+		Name: "Plans",
+		Body: "Body of the note\n",
+		Blocks: []entity.Block{
+			{Type: entity.BlockHeading, Content: json.RawMessage(`{"text":"Week","level":3}`)},
+			{Type: entity.BlockTodo, Content: json.RawMessage(`{"text":"Write docs","checked":true}`)},
+			{Type: entity.BlockCode, Content: json.RawMessage(`{"text":"go test ./...","language":"sh"}`)},
+			{Type: entity.BlockImage, Content: json.RawMessage(`{}`)},
+			{Type: entity.BlockText, Content: json.RawMessage(`{"text":"The end"}`)},
+		},
+	}
+
+	expected := "# Plans\n\nBody of the note\n\n### Week\n\n- [x] Write docs\n\n```sh\ngo test ./...\n```\n\nThe end\n"
+	require.Equal(t, expected, Render(note))
+	log.Println("SUCCESS")
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		inDocument   string
+		expectedName string
+		expectedBody string
+	}{
+		"Front matter": {
+			inDocument:   "---\ntitle: \"Plans: 2022\"\ntags: [work]\n---\n\nBody\n",
+			expectedName: "Plans: 2022",
+			expectedBody: "Body",
+		},
+		"Front matter and the same heading": {
+			inDocument:   "---\r\ntitle: Plans\r\n---\r\n# Plans\r\n\r\nBody\r\n",
+			expectedName: "Plans",
+			expectedBody: "Body",
+		},
+		"Front matter and another heading": {
+			inDocument:   "---\ntitle: Plans\n---\n# Week\nBody",
+			expectedName: "Plans",
+			expectedBody: "# Week\nBody",
+		},
+		"Heading": {
+			inDocument:   "# Plans #\n\nBody\n## Week",
+			expectedName: "Plans",
+			expectedBody: "Body\n## Week",
+		},
+		"Neither": {
+			inDocument:   "Body\n# Plans",
+			expectedName: "",
+			expectedBody: "Body\n# Plans",
+		},
+		"Unclosed front matter": {
+			inDocument:   "---\ntitle: Plans\nBody",
+			expectedName: "",
+			expectedBody: "---\ntitle: Plans\nBody",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			name, body := Parse(tc.inDocument)
+			require.Equal(t, tc.expectedName, name)
+			require.Equal(t, tc.expectedBody, body)
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	note := entity.Note{Name: "Plans", Body: "Body\n\n- item"}
+	name, body := Parse(Render(note))
+	require.Equal(t, note.Name, name)
+	require.Equal(t, note.Body, body)
+	log.Println("SUCCESS")
+}
@@ -0,0 +1,30 @@
+package mention
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxUsernameLength is the length of the username column, longer words
+// after @ cannot be usernames.
+const MaxUsernameLength = 20
+
+// mentionRegexp matches @username at the start of the text or after a
+// character that cannot be part of a word, so that emails are not mentions.
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}_][\p{L}\p{N}_.\-]*)`)
+
+// Parse returns the usernames mentioned in the text in the order they first
+// appear, every username once.
+func Parse(text string) []string {
+	var usernames []string
+	seen := map[string]bool{}
+	for _, match := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if len([]rune(username)) > MaxUsernameLength || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
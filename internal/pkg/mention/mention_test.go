@@ -0,0 +1,51 @@
+package mention
+
+import (
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		inText   string
+		expected []string
+	}{
+		"Single": {
+			inText:   "@nikita please review",
+			expected: []string{"nikita"},
+		},
+		"Several in order": {
+			inText:   "Ask @test2, then @nikita and @test2 again.",
+			expected: []string{"test2", "nikita"},
+		},
+		"Trailing punctuation": {
+			inText:   "Thanks @nikita.",
+			expected: []string{"nikita"},
+		},
+		"Cyrillic": {
+			inText:   "(@никита)",
+			expected: []string{"никита"},
+		},
+		"Email": {
+			inText:   "write to test@mail.ru",
+			expected: nil,
+		},
+		"Too long": {
+			inText:   "@abcdefghijklmnopqrstuvwxyz",
+			expected: nil,
+		},
+		"No mentions": {
+			inText:   "just @ text",
+			expected: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Parse(tc.inText))
+		})
+		log.Println("SUCCESS")
+	}
+}
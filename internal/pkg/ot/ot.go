@@ -0,0 +1,273 @@
+// Package ot implements operational transformation of plain text.
+//
+// An Operation walks over the whole document and is made of retain, insert
+// and delete components. Lengths are counted in UTF-16 code units, the same
+// way browsers count string lengths, and the JSON form is the one used by
+// ot.js: a positive number retains, a negative number deletes and a string
+// inserts.
+package ot
+
+import (
+	"encoding/json"
+	"errors"
+	"unicode/utf16"
+)
+
+var ErrBaseLength = errors.New("operation does not match the document length")
+var ErrInvalidComponent = errors.New("operation component must be a non-zero number or a string")
+
+// Component is a single step of an operation. Exactly one of the fields
+// is set.
+type Component struct {
+	Retain int
+	Insert string
+	Delete int
+}
+
+type Operation []Component
+
+// Retain appends skipping n units of the document.
+func (o Operation) Retain(n int) Operation {
+	if n <= 0 {
+		return o
+	}
+	if last := len(o) - 1; last >= 0 && o[last].Retain > 0 {
+		o[last].Retain += n
+		return o
+	}
+	return append(o, Component{Retain: n})
+}
+
+// Insert appends inserting text. An insert right after a delete is put
+// before it, so that equal operations always have the same form.
+func (o Operation) Insert(text string) Operation {
+	if text == "" {
+		return o
+	}
+	last := len(o) - 1
+	if last >= 0 && o[last].Insert != "" {
+		o[last].Insert += text
+		return o
+	}
+	if last >= 0 && o[last].Delete > 0 {
+		if last > 0 && o[last-1].Insert != "" {
+			o[last-1].Insert += text
+			return o
+		}
+		o = append(o, o[last])
+		o[last] = Component{Insert: text}
+		return o
+	}
+	return append(o, Component{Insert: text})
+}
+
+// Delete appends removing n units of the document.
+func (o Operation) Delete(n int) Operation {
+	if n <= 0 {
+		return o
+	}
+	if last := len(o) - 1; last >= 0 && o[last].Delete > 0 {
+		o[last].Delete += n
+		return o
+	}
+	return append(o, Component{Delete: n})
+}
+
+// BaseLength is the length of the document the operation can be applied to.
+func (o Operation) BaseLength() int {
+	length := 0
+	for _, c := range o {
+		length += c.Retain + c.Delete
+	}
+	return length
+}
+
+// TargetLength is the length of the document after the operation.
+func (o Operation) TargetLength() int {
+	length := 0
+	for _, c := range o {
+		length += c.Retain + textLength(c.Insert)
+	}
+	return length
+}
+
+// Apply returns the document changed by the operation.
+func (o Operation) Apply(doc string) (string, error) {
+	units := utf16.Encode([]rune(doc))
+	if len(units) != o.BaseLength() {
+		return "", ErrBaseLength
+	}
+
+	result := make([]uint16, 0, o.TargetLength())
+	pos := 0
+	for _, c := range o {
+		switch {
+		case c.Retain > 0:
+			result = append(result, units[pos:pos+c.Retain]...)
+			pos += c.Retain
+		case c.Insert != "":
+			result = append(result, utf16.Encode([]rune(c.Insert))...)
+		default:
+			pos += c.Delete
+		}
+	}
+
+	return string(utf16.Decode(result)), nil
+}
+
+// Diff returns an operation that changes a into b. It keeps what the texts
+// have in common at their beginning and end and replaces the rest.
+func Diff(a string, b string) Operation {
+	oldText, newText := []rune(a), []rune(b)
+	prefix := 0
+	for prefix < len(oldText) && prefix < len(newText) && oldText[prefix] == newText[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldText)-prefix && suffix < len(newText)-prefix &&
+		oldText[len(oldText)-1-suffix] == newText[len(newText)-1-suffix] {
+		suffix++
+	}
+
+	return Operation{}.
+		Retain(textLength(string(oldText[:prefix]))).
+		Delete(textLength(string(oldText[prefix : len(oldText)-suffix]))).
+		Insert(string(newText[prefix : len(newText)-suffix])).
+		Retain(textLength(string(oldText[len(oldText)-suffix:])))
+}
+
+// Transform takes two operations made concurrently on the same document and
+// returns a' and b' such that applying a then b' gives the same document as
+// applying b then a'. When both insert at the same place the text of a goes
+// first.
+func Transform(a Operation, b Operation) (Operation, Operation, error) {
+	if a.BaseLength() != b.BaseLength() {
+		return nil, nil, ErrBaseLength
+	}
+
+	var aPrime, bPrime Operation
+	var opA, opB Component
+	i, j := 0, 0
+	hasA, hasB := false, false
+	next := func(op Operation, k *int, c *Component, has *bool) {
+		*has = *k < len(op)
+		if *has {
+			*c = op[*k]
+			*k++
+		}
+	}
+	next(a, &i, &opA, &hasA)
+	next(b, &j, &opB, &hasB)
+
+	for hasA || hasB {
+		if hasA && opA.Insert != "" {
+			aPrime = aPrime.Insert(opA.Insert)
+			bPrime = bPrime.Retain(textLength(opA.Insert))
+			next(a, &i, &opA, &hasA)
+			continue
+		}
+		if hasB && opB.Insert != "" {
+			aPrime = aPrime.Retain(textLength(opB.Insert))
+			bPrime = bPrime.Insert(opB.Insert)
+			next(b, &j, &opB, &hasB)
+			continue
+		}
+		if !hasA || !hasB {
+			return nil, nil, ErrBaseLength
+		}
+
+		n := min(length(opA), length(opB))
+		switch {
+		case opA.Retain > 0 && opB.Retain > 0:
+			aPrime = aPrime.Retain(n)
+			bPrime = bPrime.Retain(n)
+		case opA.Delete > 0 && opB.Retain > 0:
+			aPrime = aPrime.Delete(n)
+		case opA.Retain > 0 && opB.Delete > 0:
+			bPrime = bPrime.Delete(n)
+		}
+		// Deletes of the same text cancel each other out.
+
+		if opA = shorten(opA, n); length(opA) == 0 {
+			next(a, &i, &opA, &hasA)
+		}
+		if opB = shorten(opB, n); length(opB) == 0 {
+			next(b, &j, &opB, &hasB)
+		}
+	}
+
+	return aPrime, bPrime, nil
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	raw := make([]interface{}, 0, len(o))
+	for _, c := range o {
+		switch {
+		case c.Retain > 0:
+			raw = append(raw, c.Retain)
+		case c.Insert != "":
+			raw = append(raw, c.Insert)
+		default:
+			raw = append(raw, -c.Delete)
+		}
+	}
+	return json.Marshal(raw)
+}
+
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var raw []interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	op := Operation{}
+	for _, value := range raw {
+		switch v := value.(type) {
+		case float64:
+			n := int(v)
+			if float64(n) != v || n == 0 {
+				return ErrInvalidComponent
+			}
+			if n > 0 {
+				op = op.Retain(n)
+			} else {
+				op = op.Delete(-n)
+			}
+		case string:
+			if v == "" {
+				return ErrInvalidComponent
+			}
+			op = op.Insert(v)
+		default:
+			return ErrInvalidComponent
+		}
+	}
+
+	*o = op
+	return nil
+}
+
+func textLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// length is the number of document units a retain or delete covers.
+func length(c Component) int {
+	return c.Retain + c.Delete
+}
+
+func shorten(c Component, n int) Component {
+	if c.Retain > 0 {
+		c.Retain -= n
+	} else {
+		c.Delete -= n
+	}
+	return c
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,161 @@
+package ot
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	cases := map[string]struct {
+		doc      string
+		op       Operation
+		expected string
+		err      error
+	}{
+		"insert": {
+			doc:      "hello",
+			op:       Operation{}.Retain(5).Insert(" world"),
+			expected: "hello world",
+		},
+		"delete": {
+			doc:      "hello world",
+			op:       Operation{}.Retain(5).Delete(6),
+			expected: "hello",
+		},
+		"replace after emoji": {
+			doc:      "😀 cat",
+			op:       Operation{}.Retain(3).Delete(3).Insert("dog"),
+			expected: "😀 dog",
+		},
+		"wrong length": {
+			doc: "hello",
+			op:  Operation{}.Retain(3),
+			err: ErrBaseLength,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			result, err := tc.op.Apply(tc.doc)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	cases := map[string]struct {
+		doc      string
+		a        Operation
+		b        Operation
+		expected string
+	}{
+		"inserts at different places": {
+			doc:      "abc",
+			a:        Operation{}.Insert("1").Retain(3),
+			b:        Operation{}.Retain(3).Insert("2"),
+			expected: "1abc2",
+		},
+		"inserts at the same place": {
+			doc:      "abc",
+			a:        Operation{}.Retain(1).Insert("x").Retain(2),
+			b:        Operation{}.Retain(1).Insert("y").Retain(2),
+			expected: "axybc",
+		},
+		"overlapping deletes": {
+			doc:      "abcdef",
+			a:        Operation{}.Retain(1).Delete(3).Retain(2),
+			b:        Operation{}.Retain(2).Delete(3).Retain(1),
+			expected: "af",
+		},
+		"insert inside deleted text": {
+			doc:      "abcdef",
+			a:        Operation{}.Retain(1).Delete(4).Retain(1),
+			b:        Operation{}.Retain(3).Insert("XY").Retain(3),
+			expected: "aXYf",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			aPrime, bPrime, err := Transform(tc.a, tc.b)
+			require.Equal(t, nil, err)
+
+			afterA, err := tc.a.Apply(tc.doc)
+			require.Equal(t, nil, err)
+			left, err := bPrime.Apply(afterA)
+			require.Equal(t, nil, err)
+
+			afterB, err := tc.b.Apply(tc.doc)
+			require.Equal(t, nil, err)
+			right, err := aPrime.Apply(afterB)
+			require.Equal(t, nil, err)
+
+			assert.Equal(t, tc.expected, left)
+			assert.Equal(t, tc.expected, right)
+		})
+	}
+
+	_, _, err := Transform(Operation{}.Retain(1), Operation{}.Retain(2))
+	assert.Equal(t, ErrBaseLength, err)
+}
+
+func TestDiff(t *testing.T) {
+	cases := map[string]struct {
+		a        string
+		b        string
+		expected Operation
+	}{
+		"insert in the middle": {
+			a:        "abc",
+			b:        "abxc",
+			expected: Operation{}.Retain(2).Insert("x").Retain(1),
+		},
+		"replace": {
+			a:        "hello world",
+			b:        "hello there",
+			expected: Operation{}.Retain(6).Insert("there").Delete(5),
+		},
+		"after emoji": {
+			a:        "😀 cat",
+			b:        "😀 cats",
+			expected: Operation{}.Retain(6).Insert("s"),
+		},
+		"same text": {
+			a:        "abc",
+			b:        "abc",
+			expected: Operation{}.Retain(3),
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			op := Diff(tc.a, tc.b)
+			assert.Equal(t, tc.expected, op)
+
+			result, err := op.Apply(tc.a)
+			require.Equal(t, nil, err)
+			assert.Equal(t, tc.b, result)
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	op := Operation{}.Retain(2).Delete(1).Insert("x")
+
+	data, err := json.Marshal(op)
+	require.Equal(t, nil, err)
+	assert.Equal(t, `[2,"x",-1]`, string(data))
+
+	var decoded Operation
+	require.Equal(t, nil, json.Unmarshal(data, &decoded))
+	assert.Equal(t, op, decoded)
+
+	assert.Equal(t, ErrInvalidComponent, json.Unmarshal([]byte(`[0]`), &decoded))
+	assert.Equal(t, ErrInvalidComponent, json.Unmarshal([]byte(`[true]`), &decoded))
+}
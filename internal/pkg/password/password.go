@@ -3,9 +3,11 @@ package password
 import (
 	"errors"
 	"github.com/dlclark/regexp2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var ErrBadPassword = errors.New("bad password")
+var ErrWrongPassword = errors.New("wrong password")
 
 const regex = `^(?=.*[0-9])[a-zA-Z0-9!@#$%^&*]{7,30}$`
 
@@ -20,3 +22,21 @@ func ValidatePassword(password string) error {
 	}
 	return ErrBadPassword
 }
+
+// Hash salts and hashes the password with bcrypt, which is slow enough to
+// make guessing expensive.
+func Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Compare checks the password against a hash made by Hash.
+func Compare(hash string, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+	return nil
+}
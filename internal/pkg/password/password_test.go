@@ -32,3 +32,15 @@ func TestPasswordValidator(t *testing.T) {
 		})
 	}
 }
+
+func TestHash(t *testing.T) {
+	hash, err := Hash("secret")
+	assert.Equal(t, nil, err)
+	other, err := Hash("secret")
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, hash, other)
+
+	assert.Equal(t, nil, Compare(hash, "secret"))
+	assert.Equal(t, ErrWrongPassword, Compare(hash, "wrong"))
+	assert.Equal(t, ErrWrongPassword, Compare("not a hash", "secret"))
+}
@@ -0,0 +1,36 @@
+package wikilink
+
+import (
+	"regexp"
+	"strings"
+)
+
+// linkRegexp matches [[target]], where the target is the name or the token
+// of a note.
+var linkRegexp = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+
+// Parse returns the targets of the links in the body in the order they
+// first appear, every target once.
+func Parse(body string) []string {
+	var targets []string
+	seen := map[string]bool{}
+	for _, match := range linkRegexp.FindAllStringSubmatch(body, -1) {
+		target := strings.TrimSpace(match[1])
+		if target == "" || seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// Rename points the links to oldName in the body to newName.
+func Rename(body string, oldName string, newName string) string {
+	return linkRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		if strings.TrimSpace(link[2:len(link)-2]) != oldName {
+			return link
+		}
+		return "[[" + newName + "]]"
+	})
+}
@@ -0,0 +1,42 @@
+package wikilink
+
+import (
+	"github.com/stretchr/testify/require"
+	"log"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		inBody   string
+		expected []string
+	}{
+		"Names and tokens": {
+			inBody:   "See [[Plans]] and [[ 123 ]], then [[Plans]] again.",
+			expected: []string{"Plans", "123"},
+		},
+		"Empty and unclosed": {
+			inBody:   "[[ ]] [[Plans] [Plans]]",
+			expected: nil,
+		},
+		"Nested brackets": {
+			inBody:   "[[[Plans]]]",
+			expected: []string{"Plans"},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Parse(tc.inBody))
+		})
+		log.Println("SUCCESS")
+	}
+}
+
+func TestRename(t *testing.T) {
+	body := "See [[Plans]], [[ Plans ]] and [[Plans 2]]."
+	require.Equal(t, "See [[Roadmap]], [[Roadmap]] and [[Plans 2]].", Rename(body, "Plans", "Roadmap"))
+	require.Equal(t, body, Rename(body, "Other", "Roadmap"))
+	log.Println("SUCCESS")
+}
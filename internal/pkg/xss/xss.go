@@ -2,6 +2,8 @@ package xss
 
 import (
 	"cotion/internal/domain/entity"
+	"cotion/internal/pkg/ot"
+	"encoding/json"
 	"github.com/microcosm-cc/bluemonday"
 	log "github.com/sirupsen/logrus"
 )
@@ -28,6 +30,29 @@ func SanitizeNotes(data *entity.ShortNotes) {
 		(*data).ShortNote[i].Name = sanitizer.Sanitize((*data).ShortNote[i].Name)
 		(*data).ShortNote[i].Body = sanitizer.Sanitize((*data).ShortNote[i].Body)
 		(*data).ShortNote[i].Token = sanitizer.Sanitize((*data).ShortNote[i].Token)
+		sanitizeIcon((*data).ShortNote[i].Icon)
+		sanitizeTags((*data).ShortNote[i].Tags)
+	}
+}
+
+func SanitizeTags(data *entity.Tags) {
+	if sanitizer == nil {
+		return
+	}
+	sanitizeTags((*data).Tags)
+}
+
+func SanitizeTag(data *entity.Tag) {
+	if sanitizer == nil {
+		return
+	}
+	(*data).Name = sanitizer.Sanitize((*data).Name)
+	(*data).Color = sanitizer.Sanitize((*data).Color)
+}
+
+func sanitizeTags(tags []entity.Tag) {
+	for i := range tags {
+		SanitizeTag(&tags[i])
 	}
 }
 
@@ -37,4 +62,242 @@ func SanitizeNote(data *entity.Note) {
 	}
 	(*data).Name = sanitizer.Sanitize((*data).Name)
 	(*data).Body = sanitizer.Sanitize((*data).Body)
+	sanitizeIcon((*data).Icon)
+	for i := 0; i < len((*data).Blocks); i++ {
+		(*data).Blocks[i].Content = sanitizeJSON((*data).Blocks[i].Content)
+	}
+}
+
+func SanitizeRevisions(data *entity.Revisions) {
+	if sanitizer == nil {
+		return
+	}
+	for i := range (*data).Revisions {
+		SanitizeRevision(&(*data).Revisions[i])
+	}
+}
+
+func SanitizeRevision(data *entity.Revision) {
+	if sanitizer == nil {
+		return
+	}
+	(*data).Name = sanitizer.Sanitize((*data).Name)
+	(*data).Body = sanitizer.Sanitize((*data).Body)
+	for i := 0; i < len((*data).Blocks); i++ {
+		(*data).Blocks[i].Content = sanitizeJSON((*data).Blocks[i].Content)
+	}
+}
+
+func SanitizeRevisionDiff(data *entity.RevisionDiff) {
+	if sanitizer == nil {
+		return
+	}
+	(*data).FromName = sanitizer.Sanitize((*data).FromName)
+	(*data).ToName = sanitizer.Sanitize((*data).ToName)
+	(*data).Diff = sanitizer.Sanitize((*data).Diff)
+}
+
+func sanitizeIcon(icon *entity.NoteIcon) {
+	if icon != nil {
+		icon.Value = sanitizer.Sanitize(icon.Value)
+	}
+}
+
+// sanitizeJSON sanitizes every string value of a JSON document.
+func sanitizeJSON(raw json.RawMessage) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw
+	}
+
+	sanitized, err := json.Marshal(sanitizeValue(value))
+	if err != nil {
+		return raw
+	}
+	return sanitized
+}
+
+func sanitizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return sanitizer.Sanitize(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = sanitizeValue(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = sanitizeValue(v[key])
+		}
+	}
+	return value
+}
+
+func SanitizeTree(data *entity.NotesTree) {
+	if sanitizer == nil {
+		return
+	}
+	sanitizeTreeItems((*data).Notes)
+}
+
+func sanitizeTreeItems(items []entity.NoteTreeItem) {
+	for i := 0; i < len(items); i++ {
+		items[i].Name = sanitizer.Sanitize(items[i].Name)
+		items[i].Token = sanitizer.Sanitize(items[i].Token)
+		sanitizeTreeItems(items[i].Children)
+	}
+}
+
+func SanitizeFoundNotes(data *entity.FoundNotes) {
+	if sanitizer == nil {
+		return
+	}
+	for i := 0; i < len((*data).Notes); i++ {
+		(*data).Notes[i].Name = sanitizer.Sanitize((*data).Notes[i].Name)
+		(*data).Notes[i].Snippet = sanitizer.Sanitize((*data).Notes[i].Snippet)
+		(*data).Notes[i].Token = sanitizer.Sanitize((*data).Notes[i].Token)
+	}
+}
+
+func SanitizeTrash(data *entity.TrashedNotes) {
+	if sanitizer == nil {
+		return
+	}
+	for i := 0; i < len((*data).Notes); i++ {
+		(*data).Notes[i].Name = sanitizer.Sanitize((*data).Notes[i].Name)
+		(*data).Notes[i].Token = sanitizer.Sanitize((*data).Notes[i].Token)
+		(*data).Notes[i].Parent = sanitizer.Sanitize((*data).Notes[i].Parent)
+	}
+}
+
+func SanitizePresence(data *entity.Presence) {
+	if sanitizer == nil {
+		return
+	}
+	for i := 0; i < len((*data).Users); i++ {
+		(*data).Users[i].Username = sanitizer.Sanitize((*data).Users[i].Username)
+	}
+}
+
+func SanitizeNoteEvent(data *entity.NoteEvent) {
+	if sanitizer == nil {
+		return
+	}
+	(*data).Name = sanitizer.Sanitize((*data).Name)
+	(*data).NoteToken = sanitizer.Sanitize((*data).NoteToken)
+	(*data).Parent = sanitizer.Sanitize((*data).Parent)
+}
+
+func SanitizeComments(data *entity.Comments) {
+	if sanitizer == nil {
+		return
+	}
+	for i := 0; i < len((*data).Comments); i++ {
+		SanitizeComment(&(*data).Comments[i])
+	}
+}
+
+func SanitizeComment(data *entity.Comment) {
+	if sanitizer == nil {
+		return
+	}
+	(*data).Body = sanitizer.Sanitize((*data).Body)
+	if (*data).Anchor != nil {
+		(*data).Anchor.BlockID = sanitizer.Sanitize((*data).Anchor.BlockID)
+	}
+	for i := 0; i < len((*data).Replies); i++ {
+		SanitizeComment(&(*data).Replies[i])
+	}
+}
+
+func SanitizeBacklinks(data *entity.Backlinks) {
+	if sanitizer == nil {
+		return
+	}
+	for i := range data.Backlinks {
+		data.Backlinks[i].Name = sanitizer.Sanitize(data.Backlinks[i].Name)
+	}
+}
+
+func SanitizeNotesGraph(data *entity.NotesGraph) {
+	if sanitizer == nil {
+		return
+	}
+	for i := range data.Nodes {
+		data.Nodes[i].Name = sanitizer.Sanitize(data.Nodes[i].Name)
+	}
+}
+
+func SanitizeImportedNotes(data *entity.ImportedNotes) {
+	if sanitizer == nil {
+		return
+	}
+	for i := range data.Names {
+		data.Names[i] = sanitizer.Sanitize(data.Names[i])
+	}
+}
+
+func SanitizeTemplates(data *entity.Templates) {
+	if sanitizer == nil {
+		return
+	}
+	for i := range data.Templates {
+		SanitizeTemplate(&data.Templates[i])
+	}
+}
+
+func SanitizeTemplate(data *entity.Template) {
+	if sanitizer == nil {
+		return
+	}
+	data.Name = sanitizer.Sanitize(data.Name)
+	data.Body = sanitizer.Sanitize(data.Body)
+}
+
+func SanitizeAttachments(data *entity.Attachments) {
+	if sanitizer == nil {
+		return
+	}
+	for i := range data.Attachments {
+		SanitizeAttachment(&data.Attachments[i])
+	}
+}
+
+func SanitizeAttachment(data *entity.Attachment) {
+	if sanitizer == nil {
+		return
+	}
+	data.FileName = sanitizer.Sanitize(data.FileName)
+}
+
+func SanitizeUpload(data *entity.Upload) {
+	if sanitizer == nil {
+		return
+	}
+	data.FileName = sanitizer.Sanitize(data.FileName)
+	if data.Attachment != nil {
+		SanitizeAttachment(data.Attachment)
+	}
+}
+
+// SanitizeCollabMessage sanitizes the name, the body and the inserted text
+// of a collaborative editing message. The operation is copied, as it is
+// shared with the history of the document.
+func SanitizeCollabMessage(data *entity.CollabMessage) {
+	if sanitizer == nil {
+		return
+	}
+	(*data).Name = sanitizer.Sanitize((*data).Name)
+	(*data).Body = sanitizer.Sanitize((*data).Body)
+	if (*data).Operation == nil {
+		return
+	}
+	operation := ot.Operation{}
+	for _, component := range (*data).Operation {
+		operation = operation.
+			Retain(component.Retain).
+			Insert(sanitizer.Sanitize(component.Insert)).
+			Delete(component.Delete)
+	}
+	(*data).Operation = operation
 }
@@ -11,12 +11,183 @@ CREATE TABLE Note
 (
   NoteID    varchar(100)     PRIMARY KEY,
   Name      varchar(100)     NOT NULL,
-  Body      text             NOT NULL
+  Body      text             NOT NULL,
+  Parent    varchar(100)     REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  IconType  varchar(10)      NOT NULL DEFAULT '',
+  Icon      varchar(100)     NOT NULL DEFAULT '',
+  Cover     varchar(100)     NOT NULL DEFAULT '',
+  CreatedAt timestamptz      NOT NULL DEFAULT now(),
+  UpdatedAt timestamptz      NOT NULL DEFAULT now(),
+  Version   integer          NOT NULL DEFAULT 1,
+  CreatedBy varchar(64)      REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE SET NULL,
+  LastEditedBy varchar(64)   REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE SET NULL,
+  DeletedAt timestamptz,
+  Search    tsvector         GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', Name), 'A') || setweight(to_tsvector('english', Name), 'A') ||
+    setweight(to_tsvector('russian', Body), 'B') || setweight(to_tsvector('english', Body), 'B')
+  ) STORED
 );
 
+CREATE INDEX NoteParent ON Note (Parent);
+CREATE INDEX NoteSearch ON Note USING GIN (Search);
+CREATE INDEX NoteDeletedAt ON Note (DeletedAt) WHERE DeletedAt IS NOT NULL;
+
 CREATE TABLE UsersNotes
 (
   UserID      varchar(64)        REFERENCES CotionUser 	(UserID) ON UPDATE CASCADE ON DELETE CASCADE,
   NoteID      varchar(100)       REFERENCES Note 		(NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  Role        varchar(20)        NOT NULL DEFAULT 'owner' CHECK (Role IN ('owner', 'editor', 'commenter', 'viewer')),
+  Pinned      boolean            NOT NULL DEFAULT false,
+  Favorite    boolean            NOT NULL DEFAULT false,
+  Position    integer            NOT NULL DEFAULT 0,
   CONSTRAINT  UserNoteID PRIMARY KEY (UserID, NoteID)
 );
+
+CREATE TABLE Tag
+(
+  TagID       serial             PRIMARY KEY,
+  UserID      varchar(64)        NOT NULL REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE CASCADE,
+  Name        varchar(30)        NOT NULL,
+  Color       varchar(7)         NOT NULL,
+  CONSTRAINT  UserTagName UNIQUE (UserID, Name)
+);
+
+CREATE TABLE NoteTag
+(
+  TagID       integer            REFERENCES Tag (TagID) ON DELETE CASCADE,
+  NoteID      varchar(100)       REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT  NoteTagID PRIMARY KEY (TagID, NoteID)
+);
+
+CREATE INDEX NoteTagNote ON NoteTag (NoteID);
+
+CREATE TABLE Block
+(
+  BlockID     varchar(32)        PRIMARY KEY,
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  Position    integer            NOT NULL,
+  Type        varchar(20)        NOT NULL,
+  Content     jsonb              NOT NULL,
+  CONSTRAINT  BlockPosition UNIQUE (NoteID, Position) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE TABLE Revision
+(
+  RevisionID  serial             PRIMARY KEY,
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  UserID      varchar(64)        REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE SET NULL,
+  CreatedAt   timestamptz        NOT NULL DEFAULT now(),
+  Name        varchar(100)       NOT NULL,
+  Body        text               NOT NULL,
+  Blocks      jsonb
+);
+
+CREATE INDEX RevisionNote ON Revision (NoteID);
+
+CREATE TABLE ShareLink
+(
+  ShareToken  varchar(64)        PRIMARY KEY,
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  CreatedBy   varchar(64)        REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE SET NULL,
+  ExpiresAt   timestamptz,
+  Password    varchar(256)       NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ShareLinkNote ON ShareLink (NoteID);
+
+CREATE TABLE Comment
+(
+  CommentID   serial             PRIMARY KEY,
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  ParentID    integer            REFERENCES Comment (CommentID) ON DELETE CASCADE,
+  UserID      varchar(64)        REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE SET NULL,
+  Body        text               NOT NULL,
+  BlockID     varchar(32),
+  AnchorFrom  integer,
+  AnchorTo    integer,
+  Resolved    boolean            NOT NULL DEFAULT false,
+  CreatedAt   timestamptz        NOT NULL DEFAULT now(),
+  UpdatedAt   timestamptz        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX CommentNote ON Comment (NoteID, CreatedAt);
+
+CREATE TABLE NoteLink
+(
+  FromNoteID  varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  ToNoteID    varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  PRIMARY KEY (FromNoteID, ToNoteID)
+);
+
+CREATE INDEX NoteLinkTo ON NoteLink (ToNoteID);
+
+-- CommentID is 0 for mentions in the body of the note.
+CREATE TABLE Mention
+(
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  CommentID   integer            NOT NULL DEFAULT 0,
+  UserID      varchar(64)        NOT NULL REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE CASCADE,
+  PRIMARY KEY (NoteID, CommentID, UserID)
+);
+
+CREATE TABLE Notification
+(
+  NotificationID serial          PRIMARY KEY,
+  UserID      varchar(64)        NOT NULL REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE CASCADE,
+  Type        varchar(20)        NOT NULL,
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  CommentID   integer            NOT NULL DEFAULT 0,
+  AuthorID    varchar(64)        REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE SET NULL,
+  IsRead      boolean            NOT NULL DEFAULT false,
+  CreatedAt   timestamptz        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX NotificationUser ON Notification (UserID, CreatedAt);
+
+-- Templates without a user are built in and shown to everyone.
+CREATE TABLE Template
+(
+  TemplateID  serial             PRIMARY KEY,
+  UserID      varchar(64)        REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE CASCADE,
+  Name        varchar(100)       NOT NULL,
+  Body        text               NOT NULL,
+  Tags        integer[]          NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX TemplateUser ON Template (UserID);
+
+INSERT INTO Template (Name, Body) VALUES
+  ('Meeting {{date}}', E'Date: {{date}}\nNotes by: {{username}}\n\nAttendees:\n- \n\nAgenda:\n- \n\nNotes:\n\nAction items:\n- [ ] '),
+  ('Retro {{date}}', E'What went well:\n- \n\nWhat could be better:\n- \n\nAction items:\n- [ ] ');
+
+CREATE TABLE Attachment
+(
+  AttachmentID serial            PRIMARY KEY,
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  ObjectName  varchar(64)        NOT NULL,
+  FileName    varchar(255)       NOT NULL,
+  ContentType varchar(255)       NOT NULL,
+  Size        bigint             NOT NULL,
+  UploadedBy  varchar(64)        REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE SET NULL,
+  CreatedAt   timestamptz        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX AttachmentNote ON Attachment (NoteID);
+
+CREATE TABLE Upload
+(
+  UploadID    varchar(32)        PRIMARY KEY,
+  NoteID      varchar(100)       NOT NULL REFERENCES Note (NoteID) ON UPDATE CASCADE ON DELETE CASCADE,
+  UserID      varchar(64)        NOT NULL REFERENCES CotionUser (UserID) ON UPDATE CASCADE ON DELETE CASCADE,
+  FileName    varchar(255)       NOT NULL,
+  Size        bigint             NOT NULL,
+  UploadOffset bigint             NOT NULL DEFAULT 0,
+  ContentType varchar(255)       NOT NULL DEFAULT '',
+  ObjectName  varchar(64)        NOT NULL DEFAULT '',
+  StorageID   varchar(255)       NOT NULL DEFAULT '',
+  Parts       jsonb              NOT NULL DEFAULT '[]',
+  ExpiresAt   timestamptz        NOT NULL
+);
+
+CREATE INDEX UploadExpiresAt ON Upload (ExpiresAt);
+CREATE INDEX UploadNoteID ON Upload (NoteID);